@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -9,6 +10,9 @@ import (
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
 	"github.com/somaz94/go-git-commit-action/internal/git"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/git/lock"
+	"github.com/somaz94/go-git-commit-action/internal/git/signing"
 	"github.com/somaz94/go-git-commit-action/internal/output"
 )
 
@@ -29,17 +33,82 @@ func main() {
 		log.Fatalf("Failed to initialize configuration: %v", err)
 	}
 
+	// Import the signing key for the duration of the run. Cleanup must run on
+	// failure too, so the steps below report errors instead of exiting.
+	signer := signing.NewSigner(cfg)
+	err = signer.Setup()
+	if err == nil {
+		err = runLocked(ctx, cfg)
+	}
+	signer.Cleanup()
+	if err != nil {
+		log.Fatalf("Error %v", err)
+	}
+}
+
+// runLocked executes run while holding the remote lock of the branch, with
+// remote_lock. The lock is taken once git is configured and released when run
+// returns, including after a failure or a SIGINT/SIGTERM cancellation.
+func runLocked(ctx context.Context, cfg *config.GitConfig) error {
+	if !cfg.RemoteLock {
+		return run(ctx, cfg)
+	}
+
+	locker := lock.NewLocker(cfg)
+	defer locker.Release()
+	return run(lock.NewContext(ctx, locker), cfg)
+}
+
+// run executes the steps of the action. With rollback_on_failure the remote
+// changes they make are journaled and, when a step fails, undone in reverse
+// order; the journal is printed either way.
+func run(ctx context.Context, cfg *config.GitConfig) error {
+	if !cfg.RollbackOnFailure {
+		return runSteps(ctx, cfg)
+	}
+
+	j := journal.New()
+	err := runSteps(journal.NewContext(ctx, j), cfg)
+	if err != nil {
+		fmt.Println("\nRolling Back Remote Changes:")
+		// The rollback must run even when the failure was a cancellation.
+		if rbErr := j.Rollback(context.WithoutCancel(ctx)); rbErr != nil {
+			err = fmt.Errorf("%w; rollback incomplete: %v", err, rbErr)
+		}
+	}
+	j.Print()
+	return err
+}
+
+// runSteps executes the commit, tag and release steps and writes the action
+// outputs.
+func runSteps(ctx context.Context, cfg *config.GitConfig) error {
 	// Create result to collect action outputs
 	result := output.NewResult()
 
-	if err := git.RunGitCommit(ctx, cfg, result); err != nil {
-		log.Fatalf("Error executing git commands: %v", err)
+	// The tag manager is shared with the commit flow so that files belonging
+	// to the tagged commit (the changelog) are prepared before committing.
+	var tagManager *git.TagManager
+	if cfg.HasTagOperation() {
+		tagManager = git.NewTagManager(cfg)
 	}
 
-	if cfg.TagName != "" {
-		tagManager := git.NewTagManager(cfg)
+	// With atomic_push the commit and tag flows only prepare their refs;
+	// both are pushed together once the tag exists.
+	if cfg.AtomicPush {
+		tagManager.DeferPush()
+	}
+
+	if err := git.RunGitCommit(ctx, cfg, result, tagManager); err != nil {
+		return fmt.Errorf("executing git commands: %w", err)
+	}
+
+	if tagManager != nil {
 		if err := tagManager.HandleGitTag(ctx, result); err != nil {
-			log.Fatalf("Error handling git tag: %v", err)
+			return fmt.Errorf("handling git tag: %w", err)
+		}
+		if err := tagManager.PushPending(ctx, result); err != nil {
+			return fmt.Errorf("pushing commit and tag: %w", err)
 		}
 	}
 
@@ -47,4 +116,5 @@ func main() {
 	if err := result.WriteToGitHubOutput(); err != nil {
 		log.Printf("[WARN] Failed to write action outputs: %v", err)
 	}
+	return nil
 }
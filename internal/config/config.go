@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -16,17 +18,65 @@ const (
 	EnvUserName  = "INPUT_USER_NAME"
 
 	// Commit settings
-	EnvCommitMessage = "INPUT_COMMIT_MESSAGE"
-	EnvBranch        = "INPUT_BRANCH"
-	EnvRepoPath      = "INPUT_REPOSITORY_PATH"
-	EnvFilePattern   = "INPUT_FILE_PATTERN"
-	EnvSkipIfEmpty   = "INPUT_SKIP_IF_EMPTY"
+	EnvCommitMessage   = "INPUT_COMMIT_MESSAGE"
+	EnvCommitMsgFile   = "INPUT_COMMIT_MESSAGE_FILE"
+	EnvBranch          = "INPUT_BRANCH"
+	EnvRepoPath        = "INPUT_REPOSITORY_PATH"
+	EnvFilePattern     = "INPUT_FILE_PATTERN"
+	EnvFileExclude     = "INPUT_FILE_EXCLUDE"
+	EnvSkipIfEmpty     = "INPUT_SKIP_IF_EMPTY"
+	EnvCommitViaAPI    = "INPUT_COMMIT_VIA_API"
+	EnvCommitSignoff   = "INPUT_COMMIT_SIGNOFF"
+	EnvCoauthors       = "INPUT_COMMIT_COAUTHORS"
+	EnvTrailers        = "INPUT_COMMIT_TRAILERS"
+	EnvRunHooks        = "INPUT_RUN_HOOKS"
+	EnvPreCommitCmd    = "INPUT_PRE_COMMIT_COMMAND"
+	EnvCommitGroups    = "INPUT_COMMIT_GROUPS"
+	EnvGroupsUnmatched = "INPUT_COMMIT_GROUPS_UNMATCHED"
+
+	// Push settings
+	EnvPushConflict = "INPUT_PUSH_CONFLICT_STRATEGY"
+	EnvPushRetries  = "INPUT_PUSH_RETRIES"
+	EnvPushForce    = "INPUT_PUSH_FORCE"
+	EnvAtomicPush   = "INPUT_ATOMIC_PUSH"
+
+	// Guardrail settings
+	EnvMaxFileSize  = "INPUT_MAX_FILE_SIZE"
+	EnvMaxTotalSize = "INPUT_MAX_TOTAL_SIZE"
+	EnvForbidBinary = "INPUT_FORBID_BINARY"
+	EnvScanSecrets  = "INPUT_SCAN_SECRETS"
+	EnvSecretsAllow = "INPUT_SECRETS_ALLOWLIST"
 
 	// Tag settings
 	EnvTagName      = "INPUT_TAG_NAME"
 	EnvTagMessage   = "INPUT_TAG_MESSAGE"
 	EnvDeleteTag    = "INPUT_DELETE_TAG"
 	EnvTagReference = "INPUT_TAG_REFERENCE"
+	EnvTagBump      = "INPUT_TAG_BUMP"
+	EnvTagPrefix    = "INPUT_TAG_PREFIX"
+	EnvTagPreID     = "INPUT_TAG_PRERELEASE_ID"
+	EnvAliasTags    = "INPUT_UPDATE_ALIAS_TAGS"
+
+	// Changelog settings
+	EnvChangelog     = "INPUT_CHANGELOG"
+	EnvChangelogFile = "INPUT_CHANGELOG_FILE"
+
+	// Release settings
+	EnvCreateRelease        = "INPUT_CREATE_RELEASE"
+	EnvReleaseName          = "INPUT_RELEASE_NAME"
+	EnvReleaseBody          = "INPUT_RELEASE_BODY"
+	EnvReleaseBodyFile      = "INPUT_RELEASE_BODY_FILE"
+	EnvReleaseDraft         = "INPUT_RELEASE_DRAFT"
+	EnvReleasePrerelease    = "INPUT_RELEASE_PRERELEASE"
+	EnvReleaseGenerateNotes = "INPUT_RELEASE_GENERATE_NOTES"
+	EnvReleaseDryRun        = "INPUT_RELEASE_DRY_RUN"
+	EnvReleaseAssets        = "INPUT_RELEASE_ASSETS"
+
+	// Signing settings
+	EnvSigningKey    = "INPUT_SIGNING_KEY"
+	EnvSigningFormat = "INPUT_SIGNING_FORMAT"
+	EnvSignCommits   = "INPUT_SIGN_COMMITS"
+	EnvSignTags      = "INPUT_SIGN_TAGS"
 
 	// Pull request settings
 	EnvCreatePR           = "INPUT_CREATE_PR"
@@ -38,38 +88,142 @@ const (
 	EnvGitHubToken        = "INPUT_GITHUB_TOKEN"
 	EnvPRLabels           = "INPUT_PR_LABELS"
 	EnvPRBody             = "INPUT_PR_BODY"
+	EnvPRBodyFile         = "INPUT_PR_BODY_FILE"
+	EnvPRTemplate         = "INPUT_PR_TEMPLATE"
 	EnvPRClosed           = "INPUT_PR_CLOSED"
 	EnvPRDraft            = "INPUT_PR_DRAFT"
 	EnvPRReviewers        = "INPUT_PR_REVIEWERS"
+	EnvPRTeamReviewers    = "INPUT_PR_TEAM_REVIEWERS"
+	EnvPRCodeowners       = "INPUT_PR_REVIEWERS_FROM_CODEOWNERS"
 	EnvPRAssignees        = "INPUT_PR_ASSIGNEES"
 	EnvPRDryRun           = "INPUT_PR_DRY_RUN"
+	EnvPRSplitBy          = "INPUT_PR_SPLIT_BY"
+	EnvPRUpdateExisting   = "INPUT_PR_UPDATE_EXISTING"
+	EnvPRAutoMerge        = "INPUT_PR_AUTO_MERGE"
+	EnvPRMergeNow         = "INPUT_PR_MERGE_NOW"
+	EnvPRWaitForChecks    = "INPUT_PR_WAIT_FOR_CHECKS"
 
 	// Operational settings
 	EnvDebug      = "INPUT_DEBUG"
 	EnvTimeout    = "INPUT_TIMEOUT"
 	EnvRetryCount = "INPUT_RETRY_COUNT"
+	EnvRollback   = "INPUT_ROLLBACK_ON_FAILURE"
+	EnvRemoteLock = "INPUT_REMOTE_LOCK"
+	EnvLockTTL    = "INPUT_REMOTE_LOCK_TTL"
 )
 
 // Default values for configuration parameters
 const (
-	DefaultCommitMessage = "Auto commit by Go Git Commit Action"
-	DefaultBranch        = "main"
-	DefaultRepoPath      = "."
-	DefaultFilePattern   = "."
-	DefaultSkipIfEmpty   = false
-	DefaultDeleteTag     = false
-	DefaultCreatePR      = false
-	DefaultAutoBranch    = false
-	DefaultPRTitle       = ""
-	DefaultPRBase        = "main"
-	DefaultPRBranch      = ""
-	DefaultDeleteSource  = false
-	DefaultPRClosed      = false
-	DefaultPRDraft       = false
-	DefaultPRDryRun      = false
-	DefaultDebug         = false
-	DefaultTimeout       = 30
-	DefaultRetryCount    = 3
+	DefaultCommitMessage   = "Auto commit by Go Git Commit Action"
+	DefaultBranch          = "main"
+	DefaultRepoPath        = "."
+	DefaultFilePattern     = "."
+	DefaultSkipIfEmpty     = false
+	DefaultCommitViaAPI    = false
+	DefaultCommitSignoff   = false
+	DefaultRunHooks        = true
+	DefaultGroupsUnmatched = GroupsUnmatchedCommit
+	DefaultPushConflict    = PushConflictRebase
+	DefaultPushRetries     = 3
+	DefaultPushForce       = PushForceNone
+	DefaultAtomicPush      = false
+	DefaultForbidBinary    = false
+	DefaultScanSecrets     = false
+	DefaultDeleteTag       = false
+	DefaultTagPrefix       = "v"
+	DefaultTagPreID        = "rc"
+	DefaultAliasTags       = false
+	DefaultChangelog       = false
+	DefaultChangelogFile   = "CHANGELOG.md"
+	DefaultCreateRelease   = false
+	DefaultReleaseDraft    = false
+	DefaultReleasePre      = false
+	DefaultReleaseNotes    = false
+	DefaultReleaseDryRun   = false
+	DefaultSigningFormat   = SigningFormatGPG
+	DefaultSignCommits     = false
+	DefaultSignTags        = false
+	DefaultCreatePR        = false
+	DefaultAutoBranch      = false
+	DefaultPRTitle         = ""
+	DefaultPRBase          = "main"
+	DefaultPRBranch        = ""
+	DefaultDeleteSource    = false
+	DefaultPRClosed        = false
+	DefaultPRDraft         = false
+	DefaultPRDryRun        = false
+	DefaultPRUpdate        = false
+	DefaultPRWaitForChecks = false
+	DefaultPRCodeowners    = false
+	DefaultPRTemplate      = ".github/pull_request_template.md" // used when present
+	DefaultDebug           = false
+	DefaultTimeout         = 30
+	DefaultRetryCount      = 3
+	DefaultRollback        = false
+	DefaultRemoteLock      = false
+	DefaultLockTTL         = 300
+)
+
+// Tag bump modes accepted by tag_bump.
+const (
+	TagBumpMajor      = "major"
+	TagBumpMinor      = "minor"
+	TagBumpPatch      = "patch"
+	TagBumpPrerelease = "prerelease"
+	TagBumpAuto       = "auto"
+)
+
+// TrailerCoauthoredBy is the trailer key commit_coauthors entries are added
+// under.
+const TrailerCoauthoredBy = "Co-authored-by"
+
+var (
+	// coauthorPattern matches the "Name <email>" form of a commit_coauthors
+	// entry.
+	coauthorPattern = regexp.MustCompile(`^[^<>]+ <[^<>\s]+@[^<>\s]+>$`)
+	// trailerKeyPattern matches a git trailer key.
+	trailerKeyPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+)
+
+// Push conflict strategies accepted by push_conflict_strategy.
+const (
+	PushConflictRebase = "rebase"
+	PushConflictMerge  = "merge"
+	PushConflictFail   = "fail"
+)
+
+// Handling of the changes no commit_groups entry matches, accepted by
+// commit_groups_unmatched.
+const (
+	GroupsUnmatchedCommit = "commit" // a final commit with commit_message
+	GroupsUnmatchedLeave  = "leave"  // left unstaged
+)
+
+// Modes accepted by pr_split_by, which opens one pull request per part of
+// the changes.
+const (
+	PRSplitDirectory = "directory" // per top-level directory
+	PRSplitGroup     = "group"     // per commit_groups entry
+)
+
+// Merge methods accepted by pr_auto_merge and pr_merge_now.
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// Force push modes accepted by push_force.
+const (
+	PushForceNone  = "none"
+	PushForceLease = "lease"
+	PushForceForce = "force"
+)
+
+// Signing key formats accepted by signing_format.
+const (
+	SigningFormatGPG = "gpg"
+	SigningFormatSSH = "ssh"
 )
 
 // GitConfig holds all configuration parameters for the Git commit action.
@@ -81,17 +235,65 @@ type GitConfig struct {
 	UserName  string
 
 	// Commit settings
-	CommitMessage string
-	Branch        string
-	RepoPath      string
-	FilePattern   string
-	SkipIfEmpty   bool
+	CommitMessage     string
+	CommitMessageFile string
+	Branch            string
+	RepoPath          string
+	FilePattern       string // see FilePatterns
+	FileExclude       string // see FileExcludes
+	SkipIfEmpty       bool
+	CommitViaAPI      bool
+	CommitSignoff     bool
+	Coauthors         []string // "Name <email>"
+	Trailers          []string // "key=value"
+	RunHooks          bool
+	PreCommitCommand  string
+	CommitGroupSpec   string // see CommitGroups
+	GroupsUnmatched   string
+
+	// Push settings
+	PushConflictStrategy string
+	PushRetries          int
+	PushForce            string
+	AtomicPush           bool
+
+	// Guardrail settings
+	MaxFileSize      string // a size such as "10MB"; see FileSizeLimit
+	MaxTotalSize     string // see TotalSizeLimit
+	ForbidBinary     bool
+	ScanSecrets      bool
+	SecretsAllowlist string
 
 	// Tag settings
 	TagName      string
 	TagMessage   string
 	DeleteTag    bool
 	TagReference string
+	TagBump      string
+	TagPrefix    string
+	TagPreID     string
+	AliasTags    bool
+
+	// Changelog settings
+	Changelog     bool
+	ChangelogFile string
+
+	// Release settings
+	CreateRelease        bool
+	ReleaseName          string
+	ReleaseBody          string
+	ReleaseBodyFile      string
+	ReleaseDraft         bool
+	ReleasePrerelease    bool
+	ReleaseGenerateNotes bool
+	ReleaseDryRun        bool
+	ReleaseAssets        string
+
+	// Signing settings
+	SigningKey    string
+	SigningFormat string
+	SignCommits   bool
+	SignTags      bool
 
 	// Pull request settings
 	CreatePR           bool
@@ -103,16 +305,30 @@ type GitConfig struct {
 	GitHubToken        string
 	PRLabels           []string
 	PRBody             string
+	PRBodyFile         string
+	PRTemplate         string
+	PRBodyTemplate     string // content of PRBodyFile or PRTemplate; see loadPRBodyTemplate
+	PRBodyVerbatim     bool   // PRBodyTemplate is used as it is, not rendered
 	PRClosed           bool
 	PRDraft            bool
 	PRReviewers        []string
+	PRTeamReviewers    []string // team slugs, or "@org/team" names as in CODEOWNERS
+	PRCodeowners       bool     // pr_reviewers_from_codeowners
 	PRAssignees        []string
 	PRDryRun           bool
+	PRSplitBy          string
+	PRUpdateExisting   bool
+	PRAutoMerge        string // merge method, "" to leave auto-merge off
+	PRMergeNow         string // merge method, "" not to merge
+	PRWaitForChecks    bool
 
 	// Operational settings
-	Debug      bool
-	Timeout    int
-	RetryCount int
+	Debug             bool
+	Timeout           int
+	RetryCount        int
+	RollbackOnFailure bool
+	RemoteLock        bool
+	RemoteLockTTL     int
 }
 
 // Validate checks that the configuration is valid for the requested operations.
@@ -131,6 +347,181 @@ func (c *GitConfig) Validate() error {
 		}
 	}
 
+	// Validate templates now rather than when the commit or PR is made
+	templates := []struct{ field, text string }{
+		{"commit_message", c.CommitMessage},
+		{"pr_title", c.PRTitle},
+		{"pr_body", c.PRBody},
+	}
+	if c.CommitMessageFile != "" {
+		templates[0].field = "commit_message_file"
+	}
+	for _, t := range templates {
+		if err := validateTemplate(t.field, t.text); err != nil {
+			return errors.NewConfigError(t.field, fmt.Sprintf("invalid template: %v", err))
+		}
+	}
+
+	// Validate the pull request body template
+	if c.PRBodyFile != "" && c.PRTemplate != "" {
+		return errors.NewConfigError("pr_template", "cannot be used with pr_body_file")
+	}
+	if field := c.PRBodyTemplateInput(); field != "" {
+		if c.PRBody != "" {
+			return errors.NewConfigError(field, "cannot be used with pr_body")
+		}
+		if !c.PRBodyVerbatim {
+			if err := validatePRTemplate(field, c.PRBodyTemplate); err != nil {
+				return errors.NewConfigError(field, fmt.Sprintf("invalid template: %v", err))
+			}
+		}
+	}
+
+	// Validate API commit configuration
+	if c.CommitViaAPI {
+		if c.GitHubToken == "" {
+			return errors.NewConfigError("github_token", "must be specified when commit_via_api is true")
+		}
+		if c.CreatePR && c.AutoBranch {
+			return errors.NewConfigError("commit_via_api", "cannot be used with auto_branch")
+		}
+		if c.SignCommits {
+			return errors.NewConfigError("commit_via_api", "cannot be used with sign_commits; GitHub signs API commits itself")
+		}
+	}
+
+	// Validate commit trailers
+	for _, coauthor := range c.Coauthors {
+		if !coauthorPattern.MatchString(coauthor) {
+			return errors.NewConfigError("commit_coauthors", fmt.Sprintf("invalid entry %q (expected \"Name <email>\")", coauthor))
+		}
+	}
+	for _, trailer := range c.Trailers {
+		key, value, ok := strings.Cut(trailer, "=")
+		if !ok || !trailerKeyPattern.MatchString(strings.TrimSpace(key)) || strings.TrimSpace(value) == "" {
+			return errors.NewConfigError("commit_trailers", fmt.Sprintf("invalid entry %q (expected key=value)", trailer))
+		}
+	}
+
+	// Validate commit groups
+	groups, err := parseCommitGroups(c.CommitGroupSpec)
+	if err != nil {
+		return errors.NewConfigError("commit_groups", err.Error())
+	}
+	if len(groups) > 0 {
+		if c.CommitViaAPI {
+			return errors.NewConfigError("commit_groups", "cannot be used with commit_via_api")
+		}
+		if c.CreatePR && c.AutoBranch && c.PRSplitBy != PRSplitGroup {
+			return errors.NewConfigError("commit_groups", "cannot be used with auto_branch unless pr_split_by is group")
+		}
+	}
+	for i, group := range groups {
+		if err := validateTemplate("commit_groups", group.Message); err != nil {
+			return errors.NewConfigError("commit_groups", fmt.Sprintf("group %d: invalid template: %v", i+1, err))
+		}
+	}
+	switch c.GroupsUnmatched {
+	case "", GroupsUnmatchedCommit, GroupsUnmatchedLeave:
+	default:
+		return errors.NewConfigError("commit_groups_unmatched", fmt.Sprintf("unsupported value %q (expected commit or leave)", c.GroupsUnmatched))
+	}
+
+	// Validate pull request merging
+	for _, m := range []struct{ field, method string }{
+		{"pr_auto_merge", c.PRAutoMerge},
+		{"pr_merge_now", c.PRMergeNow},
+	} {
+		switch m.method {
+		case "":
+			continue
+		case MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+		default:
+			return errors.NewConfigError(m.field, fmt.Sprintf("unsupported value %q (expected merge, squash or rebase)", m.method))
+		}
+		if !c.CreatePR {
+			return errors.NewConfigError(m.field, "requires create_pr to be true")
+		}
+		if c.PRClosed || c.PRDraft {
+			return errors.NewConfigError(m.field, "cannot be used with pr_closed or pr_draft")
+		}
+		if c.DeleteSourceBranch {
+			return errors.NewConfigError(m.field, "cannot be used with delete_source_branch; let the repository delete merged branches")
+		}
+	}
+	if c.PRAutoMerge != "" && c.PRMergeNow != "" {
+		return errors.NewConfigError("pr_merge_now", "cannot be used with pr_auto_merge")
+	}
+
+	// Validate waiting for checks
+	if c.PRWaitForChecks {
+		if !c.CreatePR {
+			return errors.NewConfigError("pr_wait_for_checks", "requires create_pr to be true")
+		}
+		if c.PRSplitBy != "" {
+			return errors.NewConfigError("pr_wait_for_checks", "cannot be used with pr_split_by")
+		}
+	}
+
+	// Validate pull request splitting
+	switch c.PRSplitBy {
+	case "":
+	case PRSplitDirectory, PRSplitGroup:
+		if !c.CreatePR || !c.AutoBranch {
+			return errors.NewConfigError("pr_split_by", "requires create_pr and auto_branch to be true")
+		}
+		if c.PRSplitBy == PRSplitGroup && len(groups) == 0 {
+			return errors.NewConfigError("pr_split_by", "group requires commit_groups")
+		}
+	default:
+		return errors.NewConfigError("pr_split_by", fmt.Sprintf("unsupported value %q (expected directory or group)", c.PRSplitBy))
+	}
+
+	// Validate push recovery
+	switch c.PushConflictStrategy {
+	case "", PushConflictRebase, PushConflictMerge, PushConflictFail:
+	default:
+		return errors.NewConfigError("push_conflict_strategy", fmt.Sprintf("unsupported value %q (expected rebase, merge or fail)", c.PushConflictStrategy))
+	}
+	if c.PushRetries < 0 {
+		return errors.NewConfigError("push_retries", "must not be negative")
+	}
+	switch c.PushForce {
+	case "", PushForceNone, PushForceLease, PushForceForce:
+	default:
+		return errors.NewConfigError("push_force", fmt.Sprintf("unsupported value %q (expected lease, force or none)", c.PushForce))
+	}
+	if c.IsForcePush() && c.CommitViaAPI {
+		return errors.NewConfigError("push_force", "cannot be used with commit_via_api")
+	}
+	if c.AtomicPush {
+		if !c.HasTagOperation() || c.DeleteTag {
+			return errors.NewConfigError("atomic_push", "requires a tag to create (tag_name or tag_bump)")
+		}
+		if c.CommitViaAPI {
+			return errors.NewConfigError("atomic_push", "cannot be used with commit_via_api")
+		}
+		if c.CreatePR {
+			return errors.NewConfigError("atomic_push", "cannot be used with create_pr")
+		}
+	}
+
+	// Validate guardrails
+	if _, err := parseSize(c.MaxFileSize); err != nil {
+		return errors.NewConfigError("max_file_size", err.Error())
+	}
+	if _, err := parseSize(c.MaxTotalSize); err != nil {
+		return errors.NewConfigError("max_total_size", err.Error())
+	}
+	if c.SecretsAllowlist != "" && !c.ScanSecrets {
+		return errors.NewConfigError("secrets_allowlist", "requires scan_secrets to be true")
+	}
+
+	// Validate remote lock
+	if c.RemoteLock && c.RemoteLockTTL <= 0 {
+		return errors.NewConfigError("remote_lock_ttl", "must be greater than 0 when remote_lock is true")
+	}
+
 	// Validate tag configuration
 	if c.TagName != "" && c.DeleteTag {
 		if c.TagReference != "" {
@@ -138,9 +529,150 @@ func (c *GitConfig) Validate() error {
 		}
 	}
 
+	if c.TagBump != "" {
+		if !isValidTagBump(c.TagBump) {
+			return errors.NewConfigError("tag_bump", fmt.Sprintf("unsupported value %q (expected major, minor, patch, prerelease or auto)", c.TagBump))
+		}
+		if c.TagName != "" {
+			return errors.NewConfigError("tag_bump", "cannot be combined with tag_name; the tag name is computed from existing tags")
+		}
+		if c.DeleteTag {
+			return errors.NewConfigError("tag_bump", "cannot be used with delete_tag")
+		}
+	}
+
+	if c.AliasTags && (!c.HasTagOperation() || c.DeleteTag) {
+		return errors.NewConfigError("update_alias_tags", "requires tag_name or tag_bump to create a tag")
+	}
+
+	// Validate changelog configuration
+	if c.Changelog {
+		if !c.HasTagOperation() || c.DeleteTag {
+			return errors.NewConfigError("changelog", "requires tag_name or tag_bump to create a tag")
+		}
+		if c.ChangelogFile == "" {
+			return errors.NewConfigError("changelog_file", "must be specified when changelog is true")
+		}
+	}
+
+	// Validate release configuration
+	if c.CreateRelease {
+		if !c.HasTagOperation() || c.DeleteTag {
+			return errors.NewConfigError("create_release", "requires tag_name or tag_bump to create a tag")
+		}
+		if c.GitHubToken == "" {
+			return errors.NewConfigError("github_token", "must be specified when create_release is true")
+		}
+		if c.ReleaseBody != "" && c.ReleaseBodyFile != "" {
+			return errors.NewConfigError("release_body_file", "cannot be used with release_body")
+		}
+	}
+
+	if c.ReleaseAssets != "" {
+		if !c.HasTagOperation() || c.DeleteTag {
+			return errors.NewConfigError("release_assets", "requires tag_name or tag_bump to create a tag")
+		}
+		if c.GitHubToken == "" {
+			return errors.NewConfigError("github_token", "must be specified when release_assets is set")
+		}
+	}
+
+	// Validate signing configuration
+	if c.SignCommits || c.SignTags {
+		if c.SigningKey == "" {
+			return errors.NewConfigError("signing_key", "must be specified when sign_commits or sign_tags is true")
+		}
+		if c.SigningFormat != SigningFormatGPG && c.SigningFormat != SigningFormatSSH {
+			return errors.NewConfigError("signing_format", fmt.Sprintf("unsupported value %q (expected gpg or ssh)", c.SigningFormat))
+		}
+	}
+
 	return nil
 }
 
+// SigningEnabled reports whether commits or tags are to be signed.
+func (c *GitConfig) SigningEnabled() bool {
+	return c.SignCommits || c.SignTags
+}
+
+// IsForcePush reports whether pushes to the branch may overwrite remote
+// commits, with push_force set to lease or force.
+func (c *GitConfig) IsForcePush() bool {
+	return c.PushForce == PushForceLease || c.PushForce == PushForceForce
+}
+
+// Identity returns the configured git identity as "Name <email>", the form
+// git uses in Signed-off-by trailers.
+func (c *GitConfig) Identity() string {
+	return fmt.Sprintf("%s <%s>", c.UserName, c.UserEmail)
+}
+
+// CommitTrailers returns the "Key: value" trailer lines requested by
+// commit_coauthors and commit_trailers, in that order. The sign-off is not
+// included: git adds it itself with --signoff.
+func (c *GitConfig) CommitTrailers() []string {
+	trailers := make([]string, 0, len(c.Coauthors)+len(c.Trailers))
+	for _, coauthor := range c.Coauthors {
+		trailers = append(trailers, TrailerCoauthoredBy+": "+coauthor)
+	}
+	for _, trailer := range c.Trailers {
+		key, value, _ := strings.Cut(trailer, "=")
+		trailers = append(trailers, strings.TrimSpace(key)+": "+strings.TrimSpace(value))
+	}
+	return trailers
+}
+
+// FilePatterns returns the pathspecs of file_pattern, the files to stage.
+func (c *GitConfig) FilePatterns() []string {
+	return parsePatterns(c.FilePattern)
+}
+
+// CommitGroups returns the entries of commit_groups, or nil when the changes
+// are committed together.
+func (c *GitConfig) CommitGroups() []CommitGroup {
+	groups, _ := parseCommitGroups(c.CommitGroupSpec)
+	return groups
+}
+
+// FileExcludes returns the pathspecs of file_exclude, the files never to
+// stage even when file_pattern matches them.
+func (c *GitConfig) FileExcludes() []string {
+	return parsePatterns(c.FileExclude)
+}
+
+// isValidTagBump reports whether mode is one of the supported tag_bump values.
+func isValidTagBump(mode string) bool {
+	switch mode {
+	case TagBumpMajor, TagBumpMinor, TagBumpPatch, TagBumpPrerelease, TagBumpAuto:
+		return true
+	}
+	return false
+}
+
+// HasGuardrails reports whether the staged changes are to be checked before
+// committing (max_file_size, max_total_size, forbid_binary, scan_secrets).
+func (c *GitConfig) HasGuardrails() bool {
+	return c.FileSizeLimit() > 0 || c.TotalSizeLimit() > 0 || c.ForbidBinary || c.ScanSecrets
+}
+
+// FileSizeLimit returns max_file_size in bytes, or 0 for no limit.
+func (c *GitConfig) FileSizeLimit() int64 {
+	size, _ := parseSize(c.MaxFileSize)
+	return size
+}
+
+// TotalSizeLimit returns max_total_size in bytes, or 0 for no limit.
+func (c *GitConfig) TotalSizeLimit() int64 {
+	size, _ := parseSize(c.MaxTotalSize)
+	return size
+}
+
+// HasTagOperation reports whether the configuration asks for any tag work,
+// either an explicit tag_name or a computed tag_bump.
+func (c *GitConfig) HasTagOperation() bool {
+	return c.TagName != "" || c.TagBump != ""
+}
+
 // NewGitConfig creates a new GitConfig instance by reading environment variables.
 // It applies default values where applicable and validates the configuration.
 func NewGitConfig() (*GitConfig, error) {
@@ -150,17 +682,65 @@ func NewGitConfig() (*GitConfig, error) {
 		UserName:  os.Getenv(EnvUserName),
 
 		// Commit settings
-		CommitMessage: getEnvWithDefault(EnvCommitMessage, DefaultCommitMessage),
-		Branch:        getEnvWithDefault(EnvBranch, DefaultBranch),
-		RepoPath:      getEnvWithDefault(EnvRepoPath, DefaultRepoPath),
-		FilePattern:   getEnvWithDefault(EnvFilePattern, DefaultFilePattern),
-		SkipIfEmpty:   getBoolEnv(EnvSkipIfEmpty, DefaultSkipIfEmpty),
+		CommitMessage:     getEnvWithDefault(EnvCommitMessage, DefaultCommitMessage),
+		CommitMessageFile: os.Getenv(EnvCommitMsgFile),
+		Branch:            getEnvWithDefault(EnvBranch, DefaultBranch),
+		RepoPath:          getEnvWithDefault(EnvRepoPath, DefaultRepoPath),
+		FilePattern:       getEnvWithDefault(EnvFilePattern, DefaultFilePattern),
+		FileExclude:       os.Getenv(EnvFileExclude),
+		SkipIfEmpty:       getBoolEnv(EnvSkipIfEmpty, DefaultSkipIfEmpty),
+		CommitViaAPI:      getBoolEnv(EnvCommitViaAPI, DefaultCommitViaAPI),
+		CommitSignoff:     getBoolEnv(EnvCommitSignoff, DefaultCommitSignoff),
+		Coauthors:         parseCommaSeparated(os.Getenv(EnvCoauthors)),
+		Trailers:          parseLines(os.Getenv(EnvTrailers)),
+		RunHooks:          getBoolEnv(EnvRunHooks, DefaultRunHooks),
+		PreCommitCommand:  strings.TrimSpace(os.Getenv(EnvPreCommitCmd)),
+		CommitGroupSpec:   os.Getenv(EnvCommitGroups),
+		GroupsUnmatched:   strings.ToLower(strings.TrimSpace(getEnvWithDefault(EnvGroupsUnmatched, DefaultGroupsUnmatched))),
+
+		// Push settings
+		PushConflictStrategy: strings.ToLower(strings.TrimSpace(getEnvWithDefault(EnvPushConflict, DefaultPushConflict))),
+		PushRetries:          getIntEnv(EnvPushRetries, DefaultPushRetries),
+		PushForce:            strings.ToLower(strings.TrimSpace(getEnvWithDefault(EnvPushForce, DefaultPushForce))),
+		AtomicPush:           getBoolEnv(EnvAtomicPush, DefaultAtomicPush),
+
+		// Guardrail settings
+		MaxFileSize:      strings.TrimSpace(os.Getenv(EnvMaxFileSize)),
+		MaxTotalSize:     strings.TrimSpace(os.Getenv(EnvMaxTotalSize)),
+		ForbidBinary:     getBoolEnv(EnvForbidBinary, DefaultForbidBinary),
+		ScanSecrets:      getBoolEnv(EnvScanSecrets, DefaultScanSecrets),
+		SecretsAllowlist: os.Getenv(EnvSecretsAllow),
 
 		// Tag settings
 		TagName:      os.Getenv(EnvTagName),
 		TagMessage:   os.Getenv(EnvTagMessage),
 		DeleteTag:    getBoolEnv(EnvDeleteTag, DefaultDeleteTag),
 		TagReference: os.Getenv(EnvTagReference),
+		TagBump:      strings.ToLower(strings.TrimSpace(os.Getenv(EnvTagBump))),
+		TagPrefix:    getEnvWithDefault(EnvTagPrefix, DefaultTagPrefix),
+		TagPreID:     getEnvWithDefault(EnvTagPreID, DefaultTagPreID),
+		AliasTags:    getBoolEnv(EnvAliasTags, DefaultAliasTags),
+
+		// Changelog settings
+		Changelog:     getBoolEnv(EnvChangelog, DefaultChangelog),
+		ChangelogFile: getEnvWithDefault(EnvChangelogFile, DefaultChangelogFile),
+
+		// Release settings
+		CreateRelease:        getBoolEnv(EnvCreateRelease, DefaultCreateRelease),
+		ReleaseName:          os.Getenv(EnvReleaseName),
+		ReleaseBody:          os.Getenv(EnvReleaseBody),
+		ReleaseBodyFile:      os.Getenv(EnvReleaseBodyFile),
+		ReleaseDraft:         getBoolEnv(EnvReleaseDraft, DefaultReleaseDraft),
+		ReleasePrerelease:    getBoolEnv(EnvReleasePrerelease, DefaultReleasePre),
+		ReleaseGenerateNotes: getBoolEnv(EnvReleaseGenerateNotes, DefaultReleaseNotes),
+		ReleaseDryRun:        getBoolEnv(EnvReleaseDryRun, DefaultReleaseDryRun),
+		ReleaseAssets:        os.Getenv(EnvReleaseAssets),
+
+		// Signing settings
+		SigningKey:    os.Getenv(EnvSigningKey),
+		SigningFormat: strings.ToLower(strings.TrimSpace(getEnvWithDefault(EnvSigningFormat, DefaultSigningFormat))),
+		SignCommits:   getBoolEnv(EnvSignCommits, DefaultSignCommits),
+		SignTags:      getBoolEnv(EnvSignTags, DefaultSignTags),
 
 		// Pull request settings
 		CreatePR:           getBoolEnv(EnvCreatePR, DefaultCreatePR),
@@ -172,16 +752,35 @@ func NewGitConfig() (*GitConfig, error) {
 		GitHubToken:        getGitHubToken(),
 		PRLabels:           parseCommaSeparated(os.Getenv(EnvPRLabels)),
 		PRBody:             os.Getenv(EnvPRBody),
+		PRBodyFile:         os.Getenv(EnvPRBodyFile),
+		PRTemplate:         os.Getenv(EnvPRTemplate),
 		PRClosed:           getBoolEnv(EnvPRClosed, DefaultPRClosed),
 		PRDraft:            getBoolEnv(EnvPRDraft, DefaultPRDraft),
 		PRReviewers:        parseCommaSeparated(os.Getenv(EnvPRReviewers)),
+		PRTeamReviewers:    parseCommaSeparated(os.Getenv(EnvPRTeamReviewers)),
+		PRCodeowners:       getBoolEnv(EnvPRCodeowners, DefaultPRCodeowners),
 		PRAssignees:        parseCommaSeparated(os.Getenv(EnvPRAssignees)),
 		PRDryRun:           getBoolEnv(EnvPRDryRun, DefaultPRDryRun),
+		PRSplitBy:          strings.ToLower(strings.TrimSpace(os.Getenv(EnvPRSplitBy))),
+		PRUpdateExisting:   getBoolEnv(EnvPRUpdateExisting, DefaultPRUpdate),
+		PRAutoMerge:        strings.ToLower(strings.TrimSpace(os.Getenv(EnvPRAutoMerge))),
+		PRMergeNow:         strings.ToLower(strings.TrimSpace(os.Getenv(EnvPRMergeNow))),
+		PRWaitForChecks:    getBoolEnv(EnvPRWaitForChecks, DefaultPRWaitForChecks),
 
 		// Operational settings
-		Debug:      getBoolEnv(EnvDebug, DefaultDebug),
-		Timeout:    getIntEnv(EnvTimeout, DefaultTimeout),
-		RetryCount: getIntEnv(EnvRetryCount, DefaultRetryCount),
+		Debug:             getBoolEnv(EnvDebug, DefaultDebug),
+		Timeout:           getIntEnv(EnvTimeout, DefaultTimeout),
+		RetryCount:        getIntEnv(EnvRetryCount, DefaultRetryCount),
+		RollbackOnFailure: getBoolEnv(EnvRollback, DefaultRollback),
+		RemoteLock:        getBoolEnv(EnvRemoteLock, DefaultRemoteLock),
+		RemoteLockTTL:     getIntEnv(EnvLockTTL, DefaultLockTTL),
+	}
+
+	if err := cfg.loadCommitMessageFile(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.loadPRBodyTemplate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	// Validate the configuration after setting all values
@@ -192,6 +791,92 @@ func NewGitConfig() (*GitConfig, error) {
 	return cfg, nil
 }
 
+// loadCommitMessageFile replaces CommitMessage with the content of
+// commit_message_file, when set. The path is relative to the workspace.
+func (c *GitConfig) loadCommitMessageFile() error {
+	if c.CommitMessageFile == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(c.CommitMessageFile)
+	if err != nil {
+		return errors.NewWithPath("read commit message file", c.CommitMessageFile, err)
+	}
+	message := strings.TrimRight(string(content), "\r\n")
+	if strings.TrimSpace(message) == "" {
+		return errors.NewConfigError("commit_message_file", "file is empty")
+	}
+	c.CommitMessage = message
+	return nil
+}
+
+// loadPRBodyTemplate sets PRBodyTemplate to the content of pr_body_file or
+// pr_template. Without either, a pull request without pr_body takes its body
+// from DefaultPRTemplate, the repository's pull request template, when it
+// exists; pr_template is then set to it. The paths are relative to
+// repository_path, which the action only changes into after loading.
+func (c *GitConfig) loadPRBodyTemplate() error {
+	field := c.PRBodyTemplateInput()
+	path := c.PRBodyFile
+	if field == "pr_template" {
+		path = c.PRTemplate
+	}
+	if field == "" {
+		if !c.CreatePR || c.PRBody != "" {
+			return nil
+		}
+		path = DefaultPRTemplate
+		if _, err := os.Stat(c.repoFile(path)); err != nil {
+			return nil
+		}
+	}
+	path = c.repoFile(path)
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.NewWithPath("read PR body template", path, err)
+	}
+	text := strings.TrimRight(string(content), "\r\n")
+	if field != "" {
+		if strings.TrimSpace(text) == "" {
+			return errors.NewConfigError(field, "file is empty")
+		}
+		c.PRBodyTemplate = text
+		return nil
+	}
+
+	// The repository's template was not written for this action: should it
+	// not be a valid template, e.g. for mentioning ${{ github.sha }}, it is
+	// used as it is.
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	c.PRTemplate, c.PRBodyTemplate = DefaultPRTemplate, text
+	c.PRBodyVerbatim = validatePRTemplate(DefaultPRTemplate, text) != nil
+	return nil
+}
+
+// repoFile returns path resolved against repository_path, unless it is
+// absolute.
+func (c *GitConfig) repoFile(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.RepoPath, path)
+}
+
+// PRBodyTemplateInput returns the input the pull request body template comes
+// from, pr_body_file or pr_template, or "" without one.
+func (c *GitConfig) PRBodyTemplateInput() string {
+	switch {
+	case c.PRBodyFile != "":
+		return "pr_body_file"
+	case c.PRTemplate != "":
+		return "pr_template"
+	}
+	return ""
+}
+
 // getEnvWithDefault retrieves an environment variable value or returns
 // the specified default value if the variable is not set or empty.
 func getEnvWithDefault(key, defaultValue string) string {
@@ -257,6 +942,65 @@ func parseCommaSeparated(labelsStr string) []string {
 	return result
 }
 
+// parseLines splits a list given one item per line or comma-separated into a
+// slice of strings, trimming whitespace and dropping empty items. Used for
+// inputs that are naturally written as a YAML block scalar.
+func parseLines(s string) []string {
+	return parseCommaSeparated(strings.ReplaceAll(s, "\n", ","))
+}
+
+// sizeUnits maps the size suffixes accepted by parseSize to their factor.
+// Decimal and binary spellings are both powers of 1024, as is usual for
+// file sizes.
+var sizeUnits = map[string]int64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1 << 10, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30, "gib": 1 << 30,
+}
+
+// sizePattern splits a size into its number and unit.
+var sizePattern = regexp.MustCompile(`^(\d+)\s*([a-zA-Z]*)$`)
+
+// parseSize parses a size such as "500K", "10MB" or "1GiB" into bytes. An
+// empty size is 0, meaning no limit.
+func parseSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	m := sizePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q (expected a number of bytes with an optional K, M or G unit)", s)
+	}
+	factor, ok := sizeUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q (expected B, K, KB, KiB, M, MB, MiB, G, GB or GiB)", m[2])
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil || n > (1<<62)/factor {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return n * factor, nil
+}
+
+// parsePatterns splits a pattern list. A list spanning several lines has one
+// pattern per line, so patterns may contain spaces; a single line is split on
+// whitespace, as file_pattern always was. Empty items are dropped.
+func parsePatterns(s string) []string {
+	if !strings.Contains(s, "\n") {
+		return strings.Fields(s)
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
+}
+
 // getGitHubToken retrieves the GitHub token from various sources.
 // Priority order:
 // 1. INPUT_GITHUB_TOKEN (user-provided token via action input)
@@ -0,0 +1,286 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CommitGroup is an entry of commit_groups: the changes matching Paths are
+// committed together, with Message or, when empty, commit_message.
+type CommitGroup struct {
+	Paths   []string
+	Message string
+}
+
+// parseCommitGroups parses commit_groups, a YAML list of {paths, message}
+// mappings. The action has no YAML dependency, so the subset workflows use is
+// parsed here: a block list whose paths are a scalar, a flow list ([a, b]) or
+// a block list, and whose message is a scalar or a literal block (|), with
+// full-line and trailing comments. A JSON array, which is also YAML, is accepted as well.
+// A scalar paths value is split like file_pattern.
+func parseCommitGroups(spec string) ([]CommitGroup, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var groups []CommitGroup
+	var err error
+	if strings.HasPrefix(strings.TrimSpace(spec), "[") {
+		groups, err = parseCommitGroupsJSON(spec)
+	} else {
+		groups, err = parseCommitGroupsYAML(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, group := range groups {
+		if len(group.Paths) == 0 {
+			return nil, fmt.Errorf("group %d has no paths", i+1)
+		}
+	}
+	return groups, nil
+}
+
+// parseCommitGroupsJSON parses commit_groups given as a JSON array, where
+// paths is a string or an array of strings.
+func parseCommitGroupsJSON(spec string) ([]CommitGroup, error) {
+	var entries []struct {
+		Paths   json.RawMessage `json:"paths"`
+		Message string          `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(spec), &entries); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	groups := make([]CommitGroup, len(entries))
+	for i, entry := range entries {
+		groups[i].Message = entry.Message
+		var paths []string
+		var path string
+		switch {
+		case len(entry.Paths) == 0:
+		case json.Unmarshal(entry.Paths, &paths) == nil:
+			groups[i].Paths = paths
+		case json.Unmarshal(entry.Paths, &path) == nil:
+			groups[i].Paths = parsePatterns(path)
+		default:
+			return nil, fmt.Errorf("group %d: paths must be a string or a list of strings", i+1)
+		}
+	}
+	return groups, nil
+}
+
+// parseCommitGroupsYAML parses commit_groups given as a YAML block list.
+func parseCommitGroupsYAML(spec string) ([]CommitGroup, error) {
+	var groups []CommitGroup
+	itemIndent := -1
+
+	// pathList is set while the block list of paths is read; block collects
+	// a literal block message indented deeper than blockIndent.
+	pathList := false
+	var block []string
+	blockIndent := -1
+	endBlock := func() {
+		if block != nil {
+			groups[len(groups)-1].Message = dedent(block)
+			block = nil
+		}
+	}
+
+	for n, raw := range strings.Split(spec, "\n") {
+		line := strings.TrimRight(raw, " \t\r")
+		text := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if block != nil && (text == "" || indent > blockIndent) {
+			block = append(block, line)
+			continue
+		}
+		endBlock()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", n+1)
+		}
+
+		if itemIndent < 0 {
+			itemIndent = indent
+		}
+		switch {
+		case indent == itemIndent && (text == "-" || strings.HasPrefix(text, "- ")):
+			// "- key: value" starts a group, with its first key on the line.
+			groups = append(groups, CommitGroup{})
+			pathList = false
+			text = strings.TrimSpace(text[1:])
+			if text == "" {
+				continue
+			}
+			indent += len(line[indent:]) - len(text)
+		case indent <= itemIndent || len(groups) == 0:
+			return nil, fmt.Errorf("line %d: expected a list item (\"- paths: ...\")", n+1)
+		case pathList && strings.HasPrefix(text, "- "):
+			path, err := parseScalar(stripComment(text[2:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			group := &groups[len(groups)-1]
+			group.Paths = append(group.Paths, path)
+			continue
+		}
+
+		group := &groups[len(groups)-1]
+		key, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", n+1)
+		}
+		value = stripComment(strings.TrimSpace(value))
+		pathList = false
+		switch strings.TrimSpace(key) {
+		case "paths":
+			if value == "" {
+				pathList = true
+				continue
+			}
+			paths, err := parsePathsValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			group.Paths = paths
+		case "message":
+			if value == "|" || value == "|-" {
+				block, blockIndent = []string{}, indent
+				continue
+			}
+			message, err := parseScalar(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			group.Message = message
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q (expected paths or message)", n+1, strings.TrimSpace(key))
+		}
+	}
+	endBlock()
+	return groups, nil
+}
+
+// stripComment removes a trailing comment from value: a # at its start or
+// after whitespace, outside a quoted scalar. As in YAML, a quote only opens a
+// quoted scalar at the start of the value or of a flow list item, so the
+// apostrophe in a plain scalar such as it's is literal.
+func stripComment(value string) string {
+	quote, prev := byte(0), byte(0)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if quote == '"' && c == '\\' {
+				i++
+			} else if c == quote {
+				quote, prev = 0, c
+			}
+		case c == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return strings.TrimSpace(value[:i])
+		case (c == '"' || c == '\'') && (prev == 0 || prev == '[' || prev == ','):
+			quote = c
+		case c != ' ' && c != '\t':
+			prev = c
+		}
+	}
+	return value
+}
+
+// parsePathsValue parses a paths value given on the key's line: a flow list
+// of scalars, a quoted scalar naming one path, or a plain scalar split like
+// file_pattern.
+func parsePathsValue(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") {
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+			path, err := parseScalar(value)
+			return []string{path}, err
+		}
+		return parsePatterns(value), nil
+	}
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("unterminated list %s", value)
+	}
+
+	// Split on the commas outside quotes.
+	var items []string
+	start, quote := 1, rune(0)
+	for i, c := range value {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',' || i == len(value)-1:
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quoted string in %s", value)
+	}
+
+	var paths []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		path, err := parseScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// parseScalar returns the value of a plain, single-quoted or double-quoted
+// YAML scalar.
+func parseScalar(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case strings.HasPrefix(value, `"`):
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted string %s", value)
+		}
+		return s, nil
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", fmt.Errorf("invalid quoted string %s", value)
+		}
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+// dedent joins the lines of a literal block, removing the indentation of its
+// first line and the trailing blank lines.
+func dedent(lines []string) string {
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			indent = len(line) - len(strings.TrimLeft(line, " "))
+			break
+		}
+	}
+	var out []string
+	for _, line := range lines {
+		if len(line) >= indent && indent >= 0 {
+			line = line[indent:]
+		} else {
+			line = strings.TrimSpace(line)
+		}
+		out = append(out, line)
+	}
+	return strings.TrimRight(strings.Join(out, "\n"), "\n")
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateDateFormat is the layout of TemplateData.Date.
+const TemplateDateFormat = "2006-01-02"
+
+// TemplateData holds the variables available to the commit_message, pr_title
+// and pr_body templates.
+type TemplateData struct {
+	Branch       string   // branch the changes are committed to
+	ChangedFiles []string // paths reported by git status
+	RunID        string   // GITHUB_RUN_ID
+	SHA          string   // HEAD before the commit
+	Date         string   // current UTC date, YYYY-MM-DD
+	Repo         string   // GITHUB_REPOSITORY
+	Actor        string   // GITHUB_ACTOR
+}
+
+// PRTemplateData holds the variables available to the pr_body_file and
+// pr_template templates, which are rendered when the pull request is created:
+// those of TemplateData, where ChangedFiles lists the files the pull request
+// changes and SHA is its head commit, and the changes between the base and
+// the head branch.
+type PRTemplateData struct {
+	TemplateData
+	Base      string       // pr_base
+	Head      string       // pr_branch
+	Changes   []FileChange // status of each changed file
+	Stats     []FileStat   // lines added and deleted in each changed file
+	DiffStat  string       // Stats as a Markdown table
+	Commits   []CommitInfo // commits between Base and Head, newest first
+	RunURL    string       // link to the workflow run
+	Truncated bool         // the lists were cut to fit GitHub's body size limit
+}
+
+// FileChange is a file changed by a pull request.
+type FileChange struct {
+	Status string // A, M, D, R (renamed), C (copied) or T (type changed)
+	Path   string
+}
+
+// FileStat counts the lines a pull request adds to and deletes from a file.
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool // no line counts
+}
+
+// CommitInfo is a commit of a pull request.
+type CommitInfo struct {
+	SHA     string
+	Author  string
+	Subject string
+}
+
+// templateFuncs are the functions available to templates in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// NewTemplateData returns TemplateData for the current run, reading the run
+// metadata from the GitHub Actions environment.
+func NewTemplateData(branch, sha string, changedFiles []string) TemplateData {
+	return TemplateData{
+		Branch:       branch,
+		ChangedFiles: changedFiles,
+		RunID:        os.Getenv("GITHUB_RUN_ID"),
+		SHA:          sha,
+		Date:         time.Now().UTC().Format(TemplateDateFormat),
+		Repo:         os.Getenv("GITHUB_REPOSITORY"),
+		Actor:        os.Getenv("GITHUB_ACTOR"),
+	}
+}
+
+// RenderTemplate renders text as a Go text/template with data, a TemplateData
+// or PRTemplateData. Text without template actions is returned unchanged.
+// Referencing a variable that does not exist is an error.
+func RenderTemplate(name, text string, data any) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// validateTemplate reports template errors in text before anything runs:
+// syntax errors as well as unknown variables, which only surface on
+// execution, so the template is rendered once against sample data.
+func validateTemplate(name, text string) error {
+	sample := TemplateData{ChangedFiles: []string{"file"}}
+	_, err := RenderTemplate(name, text, sample)
+	return err
+}
+
+// validatePRTemplate is validateTemplate for the pull request body templates.
+func validatePRTemplate(name, text string) error {
+	sample := PRTemplateData{
+		TemplateData: TemplateData{ChangedFiles: []string{"file"}},
+		Changes:      []FileChange{{Status: "M", Path: "file"}},
+		Stats:        []FileStat{{Path: "file", Added: 1}},
+		Commits:      []CommitInfo{{SHA: "sha", Subject: "subject"}},
+	}
+	_, err := RenderTemplate(name, text, sample)
+	return err
+}
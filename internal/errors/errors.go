@@ -1,6 +1,10 @@
 package errors
 
-import "fmt"
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+)
 
 // GitError represents an error that occurred during a Git operation.
 // It provides structured error information including the operation,
@@ -89,6 +93,33 @@ func NewWithContext(message string, attempts int, lastErr error) *RetryError {
 	}
 }
 
+// PermanentError marks an error that retrying the operation cannot fix, such
+// as a merge conflict. Retry loops return it immediately.
+type PermanentError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error for error chain support.
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps err so that IsPermanent reports true for it.
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err, or any error it wraps, is a PermanentError.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return stderrors.As(err, &permanent)
+}
+
 // APIError represents an error from the GitHub API.
 type APIError struct {
 	Operation  string                 // API operation (e.g., "create PR", "add labels")
@@ -140,3 +171,90 @@ func NewAPIErrorWithDetails(operation, message string, statusCode int, details m
 		Details:    details,
 	}
 }
+
+// Violation is one breach of a rule by a staged path.
+type Violation struct {
+	Path   string // Offending path; empty for a rule on the staged changes as a whole
+	Rule   string // Rule breached (e.g., "max_file_size", "aws-access-key")
+	Detail string // What was found, such as the size or line (optional)
+}
+
+// String renders the violation as "path: rule (detail)".
+func (v Violation) String() string {
+	s := v.Rule
+	if v.Path != "" {
+		s = v.Path + ": " + s
+	}
+	if v.Detail != "" {
+		s += " (" + v.Detail + ")"
+	}
+	return s
+}
+
+// ViolationError reports every rule breached by the staged changes, so that
+// all of them can be fixed at once.
+type ViolationError struct {
+	Violations []Violation
+}
+
+// Error implements the error interface, listing one violation per line.
+func (e *ViolationError) Error() string {
+	lines := make([]string, 0, len(e.Violations)+1)
+	lines = append(lines, fmt.Sprintf("staged changes break %d guardrail(s):", len(e.Violations)))
+	for _, v := range e.Violations {
+		lines = append(lines, "  - "+v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NewViolationError creates a new ViolationError.
+func NewViolationError(violations []Violation) *ViolationError {
+	return &ViolationError{Violations: violations}
+}
+
+// maxCommandOutputLines bounds the output a CommandError reports; the end of
+// the output is kept, where failures are usually explained.
+const maxCommandOutputLines = 50
+
+// CommandError reports a command run for the user, such as a git hook or
+// pre_commit_command, that failed, with what it printed.
+type CommandError struct {
+	Command  string // Command that failed (e.g., "git commit", "make fmt")
+	ExitCode int    // Exit status; -1 when the command did not run to completion
+	Output   string // Combined stdout and stderr of the command
+	Err      error  // Underlying error
+}
+
+// Error implements the error interface, followed by the indented output.
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Command, e.Err)
+	if e.ExitCode >= 0 {
+		msg = fmt.Sprintf("%s exited with status %d", e.Command, e.ExitCode)
+	}
+
+	output := strings.TrimRight(e.Output, "\n")
+	if output == "" {
+		return msg
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > maxCommandOutputLines {
+		omitted := len(lines) - maxCommandOutputLines
+		lines = append([]string{fmt.Sprintf("... %d line(s) omitted", omitted)}, lines[omitted:]...)
+	}
+	return msg + ":\n    " + strings.Join(lines, "\n    ")
+}
+
+// Unwrap returns the underlying error for error chain support.
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// NewCommandError creates a new CommandError.
+func NewCommandError(command string, exitCode int, output []byte, err error) *CommandError {
+	return &CommandError{
+		Command:  command,
+		ExitCode: exitCode,
+		Output:   string(output),
+		Err:      err,
+	}
+}
@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -283,6 +284,81 @@ func TestErrorChaining(t *testing.T) {
 	}
 }
 
+func TestPermanent(t *testing.T) {
+	cause := New("rebase", errors.New("conflict"))
+	err := fmt.Errorf("push: %w", Permanent(cause))
+
+	if !IsPermanent(err) {
+		t.Error("IsPermanent() = false for a wrapped PermanentError, want true")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is() should find the cause through a PermanentError")
+	}
+	if err.Error() != "push: rebase: conflict" {
+		t.Errorf("Error() = %q, want the cause's message unchanged", err.Error())
+	}
+	if IsPermanent(cause) || IsPermanent(nil) {
+		t.Error("IsPermanent() = true for an unmarked error, want false")
+	}
+}
+
+func TestViolationError(t *testing.T) {
+	err := NewViolationError([]Violation{
+		{Path: "dist/app.bin", Rule: "max_file_size", Detail: "12.0 MiB > 10.0 MiB"},
+		{Path: ".env", Rule: "env-file"},
+		{Rule: "max_total_size", Detail: "60.0 MiB > 50.0 MiB"},
+	})
+
+	want := "staged changes break 3 guardrail(s):\n" +
+		"  - dist/app.bin: max_file_size (12.0 MiB > 10.0 MiB)\n" +
+		"  - .env: env-file\n" +
+		"  - max_total_size (60.0 MiB > 50.0 MiB)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	var target *ViolationError
+	if !errors.As(fmt.Errorf("commit: %w", err), &target) || len(target.Violations) != 3 {
+		t.Error("errors.As() should find the ViolationError with its violations")
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	cause := fmt.Errorf("exit status 1")
+	err := NewCommandError("git commit", 1, []byte("golangci-lint....Failed\nmain.go:3: unused\n"), cause)
+
+	want := "git commit exited with status 1:\n" +
+		"    golangci-lint....Failed\n" +
+		"    main.go:3: unused"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("errors.Is() should find the underlying error")
+	}
+
+	// A command that did not run reports its error instead of a status.
+	notRun := NewCommandError("make fmt", -1, nil, fmt.Errorf("executable file not found"))
+	if got, want := notRun.Error(), "make fmt: executable file not found"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestCommandError_TruncatesOutput(t *testing.T) {
+	var output strings.Builder
+	for i := 1; i <= maxCommandOutputLines+10; i++ {
+		fmt.Fprintf(&output, "line %d\n", i)
+	}
+	msg := NewCommandError("make lint", 2, []byte(output.String()), nil).Error()
+
+	if !strings.Contains(msg, "... 10 line(s) omitted\n    line 11\n") {
+		t.Errorf("Error() = %q, want the first 10 lines omitted", msg)
+	}
+	if !strings.HasSuffix(msg, fmt.Sprintf("line %d", maxCommandOutputLines+10)) {
+		t.Errorf("Error() = %q, want the last line kept", msg)
+	}
+}
+
 func TestErrorMessages(t *testing.T) {
 	// Test that error messages contain expected information
 	tests := []struct {
@@ -0,0 +1,232 @@
+package apicommit
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/github"
+)
+
+// Committer creates commits through the GitHub Git Data API instead of git
+// commit and git push. GitHub signs such commits for the token's identity, so
+// they show as verified without any key management.
+type Committer struct {
+	config *config.GitConfig
+	client *github.Client
+	runner gitcmd.Runner
+}
+
+// NewCommitter creates a new Committer instance.
+func NewCommitter(cfg *config.GitConfig) *Committer {
+	return NewCommitterWithRunner(cfg, gitcmd.NewExecRunner())
+}
+
+// NewCommitterWithRunner creates a Committer with an explicit command Runner.
+func NewCommitterWithRunner(cfg *config.GitConfig, r gitcmd.Runner) *Committer {
+	return NewCommitterWithClient(cfg, r, github.NewClient(cfg.GitHubToken))
+}
+
+// NewCommitterWithClient creates a Committer with both seams supplied
+// explicitly: the command Runner for git and the API client for GitHub. Tests
+// use it to drive the API paths against an httptest server.
+func NewCommitterWithClient(cfg *config.GitConfig, r gitcmd.Runner, client *github.Client) *Committer {
+	return &Committer{config: cfg, client: client, runner: r}
+}
+
+// Commit turns the staged index into a commit on branch: it uploads a blob
+// per added or modified file, creates a tree on top of HEAD's tree, creates
+// the commit with HEAD as parent and fast-forwards the branch ref to it. The
+// local branch is then moved to the new commit, keeping the working tree, so
+// later steps (such as tagging) see it as HEAD.
+//
+// It returns the new commit SHA, or "" when nothing is staged.
+func (c *Committer) Commit(ctx context.Context, branch, message string) (string, error) {
+	changes, err := shared.StagedChanges(c.runner)
+	if err != nil {
+		return "", err
+	}
+	if len(changes) == 0 {
+		fmt.Println("  - [WARN] Nothing to commit, skipping commit...")
+		return "", nil
+	}
+
+	parent, err := c.revParse("HEAD")
+	if err != nil {
+		return "", err
+	}
+	baseTree, err := c.revParse("HEAD^{tree}")
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := c.treeEntries(ctx, changes)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("  - Creating tree (%d changes)... ", len(entries))
+	tree, err := c.post(ctx, "create tree", "/git/trees", map[string]interface{}{
+		"base_tree": baseTree,
+		"tree":      entries,
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("Done")
+
+	// Author and committer are left to GitHub: a commit it authors itself is
+	// the one it signs.
+	fmt.Printf("  - Creating commit... ")
+	commit, err := c.post(ctx, "create commit", "/git/commits", map[string]interface{}{
+		"message": shared.AppendTrailers(message, c.trailers()),
+		"tree":    tree,
+		"parents": []string{parent},
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("Done")
+
+	fmt.Printf("  - Updating branch %s... ", branch)
+	resp, err := c.client.Patch(ctx, "/git/refs/heads/"+escapeRef(branch), map[string]interface{}{
+		"sha":   commit,
+		"force": false,
+	})
+	if err != nil {
+		fmt.Println("FAILED")
+		return "", errors.NewAPIErrorFrom("update branch ref", err)
+	}
+	if msg, ok := resp["message"].(string); ok && msg != "" {
+		fmt.Println("FAILED")
+		return "", errors.NewAPIError("update branch ref", msg)
+	}
+	fmt.Println("Done")
+
+	if err := c.syncLocalBranch(branch); err != nil {
+		return "", err
+	}
+
+	fmt.Printf("Commit created via API: %s\n", commit)
+	return commit, nil
+}
+
+// trailers returns the trailers the git path adds with --signoff and
+// --trailer. GitHub creates this commit, so they are written into the message.
+func (c *Committer) trailers() []string {
+	var trailers []string
+	if c.config.CommitSignoff {
+		trailers = append(trailers, shared.FormatTrailer(shared.TrailerSignedOffBy, c.config.Identity()))
+	}
+	return append(trailers, c.config.CommitTrailers()...)
+}
+
+// treeEntries builds the tree API entries for changes, uploading the content
+// of every added or modified file as a base64 blob so binary files survive.
+func (c *Committer) treeEntries(ctx context.Context, changes []shared.StagedChange) ([]map[string]interface{}, error) {
+	entries := make([]map[string]interface{}, 0, len(changes))
+	for _, ch := range changes {
+		switch ch.Mode {
+		case shared.ModeDeleted:
+			// A null sha removes the path from the base tree.
+			entries = append(entries, map[string]interface{}{
+				"path": ch.Path, "mode": "100644", "type": "blob", "sha": nil,
+			})
+		case shared.ModeGitlink:
+			entries = append(entries, map[string]interface{}{
+				"path": ch.Path, "mode": ch.Mode, "type": "commit", "sha": ch.SHA,
+			})
+		default:
+			sha, err := c.uploadBlob(ctx, ch)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, map[string]interface{}{
+				"path": ch.Path, "mode": ch.Mode, "type": "blob", "sha": sha,
+			})
+		}
+	}
+	return entries, nil
+}
+
+// uploadBlob creates a blob with the staged content of ch.
+func (c *Committer) uploadBlob(ctx context.Context, ch shared.StagedChange) (string, error) {
+	content, err := c.runner.Output(gitcmd.CmdGit, gitcmd.CatFileBlobArgs(ch.SHA)...)
+	if err != nil {
+		return "", errors.NewWithPath("read staged content", ch.Path, err)
+	}
+
+	fmt.Printf("  - Uploading %s... ", ch.Path)
+	sha, err := c.post(ctx, "create blob", "/git/blobs", map[string]interface{}{
+		"content":  base64.StdEncoding.EncodeToString(content),
+		"encoding": "base64",
+	})
+	if err != nil {
+		return "", err
+	}
+	fmt.Println("Done")
+	return sha, nil
+}
+
+// post sends a Git Data API create request and returns the "sha" of the
+// created object. The caller has printed the step description; post prints
+// FAILED on error.
+func (c *Committer) post(ctx context.Context, op, endpoint string, payload map[string]interface{}) (string, error) {
+	resp, err := c.client.Post(ctx, endpoint, payload)
+	if err != nil {
+		fmt.Println("FAILED")
+		return "", errors.NewAPIErrorFrom(op, err)
+	}
+	if msg, ok := resp["message"].(string); ok && msg != "" {
+		fmt.Println("FAILED")
+		return "", errors.NewAPIError(op, msg)
+	}
+	sha, ok := resp["sha"].(string)
+	if !ok || sha == "" {
+		fmt.Println("FAILED")
+		return "", errors.NewAPIError(op, "response did not include a sha")
+	}
+	return sha, nil
+}
+
+// revParse resolves rev to a full object name.
+func (c *Committer) revParse(rev string) (string, error) {
+	out, err := c.runner.Output(gitcmd.CmdGit, gitcmd.RevParseArgs(rev)...)
+	if err != nil {
+		return "", errors.NewWithPath("resolve revision", rev, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// syncLocalBranch moves the local branch to the commit just created on the
+// remote. A soft reset keeps the working tree and leaves the index matching
+// the new commit.
+func (c *Committer) syncLocalBranch(branch string) error {
+	fmt.Printf("  - Syncing local branch... ")
+	if err := c.runner.Run(gitcmd.CmdGit, gitcmd.FetchArgs(gitcmd.RefOrigin, branch)...); err != nil {
+		fmt.Println("FAILED")
+		return errors.New("fetch committed branch", err)
+	}
+	if err := c.runner.Run(gitcmd.CmdGit, gitcmd.ResetSoftArgs(gitcmd.RefOrigin+"/"+branch)...); err != nil {
+		fmt.Println("FAILED")
+		return errors.New("reset to committed branch", err)
+	}
+	fmt.Println("Done")
+	return nil
+}
+
+// escapeRef escapes each segment of a branch name for use in a URL path,
+// keeping the slashes of names such as "release/v1".
+func escapeRef(ref string) string {
+	parts := strings.Split(ref, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,291 @@
+package apicommit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/github"
+)
+
+const (
+	headSHA = "1111111111111111111111111111111111111111"
+	treeSHA = "2222222222222222222222222222222222222222"
+	zeroSHA = "0000000000000000000000000000000000000000"
+)
+
+// apiCall is one request the fake GitHub API received.
+type apiCall struct {
+	Method string
+	Path   string
+	Body   map[string]any
+}
+
+// fakeAPI is an httptest-backed stand-in for the Git Data API. routes maps
+// "<METHOD> <path>" to a canned response; anything unrouted returns 404.
+type fakeAPI struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	calls  []apiCall
+	routes map[string]func(w http.ResponseWriter, n int)
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	f := &fakeAPI{routes: make(map[string]func(http.ResponseWriter, int))}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAPI) handle(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	var body map[string]any
+	_ = json.Unmarshal(raw, &body)
+
+	// The client prefixes every endpoint with /repos/<owner>/<repo>.
+	path := strings.TrimPrefix(r.URL.RequestURI(), "/repos/owner/repo")
+
+	f.mu.Lock()
+	f.calls = append(f.calls, apiCall{Method: r.Method, Path: path, Body: body})
+	n := 0
+	for _, c := range f.calls {
+		if c.Method == r.Method && c.Path == path {
+			n++
+		}
+	}
+	handler, ok := f.routes[r.Method+" "+path]
+	f.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		return
+	}
+	handler(w, n)
+}
+
+// route registers a JSON response for one endpoint. A body containing %d is
+// formatted with the 1-based call count, giving each blob a distinct sha.
+func (f *fakeAPI) route(methodAndPath string, status int, body string) *fakeAPI {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[methodAndPath] = func(w http.ResponseWriter, n int) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(strings.ReplaceAll(body, "%d", strconv.Itoa(n))))
+	}
+	return f
+}
+
+// callsTo returns the calls made to one endpoint, in order.
+func (f *fakeAPI) callsTo(methodAndPath string) []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []apiCall
+	for _, c := range f.calls {
+		if c.Method+" "+c.Path == methodAndPath {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// happyAPI routes every endpoint of a successful commit.
+func happyAPI(t *testing.T) *fakeAPI {
+	return newFakeAPI(t).
+		route("POST /git/blobs", http.StatusCreated, `{"sha":"blob%d"}`).
+		route("POST /git/trees", http.StatusCreated, `{"sha":"newtree"}`).
+		route("POST /git/commits", http.StatusCreated, `{"sha":"newcommit"}`).
+		route("PATCH /git/refs/heads/main", http.StatusOK, `{"ref":"refs/heads/main"}`)
+}
+
+// rawRecord renders one NUL-terminated git diff --raw record.
+func rawRecord(srcMode, dstMode, dstSHA, status, path string) string {
+	return ":" + srcMode + " " + dstMode + " " + zeroSHA + " " + dstSHA + " " + status + "\x00" + path + "\x00"
+}
+
+func gitKey(args []string) string {
+	return gitcmd.Call{Name: gitcmd.CmdGit, Args: args}.Key()
+}
+
+// stagedRunner returns a FakeRunner reporting diff as the staged changes and
+// blobs (sha -> content) as the object store.
+func stagedRunner(diff string, blobs map[string]string) *gitcmd.FakeRunner {
+	f := gitcmd.NewFakeRunner().
+		Stub(gitKey(gitcmd.DiffCachedRawArgs()), gitcmd.FakeResult{Stdout: diff}).
+		Stub(gitKey(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: headSHA + "\n"}).
+		Stub(gitKey(gitcmd.RevParseArgs("HEAD^{tree}")), gitcmd.FakeResult{Stdout: treeSHA + "\n"})
+	for sha, content := range blobs {
+		f.Stub(gitKey(gitcmd.CatFileBlobArgs(sha)), gitcmd.FakeResult{Stdout: content})
+	}
+	return f
+}
+
+func newAPICommitter(t *testing.T, r gitcmd.Runner, api *fakeAPI) *Committer {
+	t.Helper()
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	cfg := &config.GitConfig{GitHubToken: "token", Branch: "main", CommitViaAPI: true}
+	return NewCommitterWithClient(cfg, r, github.NewClientWithBaseURL(cfg.GitHubToken, api.server.URL))
+}
+
+// treeEntry finds the tree entry for path in the create-tree payload.
+func treeEntry(t *testing.T, call apiCall, path string) map[string]any {
+	t.Helper()
+	entries, _ := call.Body["tree"].([]any)
+	for _, e := range entries {
+		entry, _ := e.(map[string]any)
+		if entry["path"] == path {
+			return entry
+		}
+	}
+	t.Fatalf("tree has no entry for %s: %v", path, call.Body["tree"])
+	return nil
+}
+
+func TestCommit_BuildsCommitFromIndex(t *testing.T) {
+	binary := "\x89PNG\r\n\x1a\n\x00\xff"
+	diff := rawRecord("000000", "100644", "aaa", "A", "new.txt") +
+		rawRecord("100644", "100644", "bbb", "M", "docs/readme.md") +
+		rawRecord("100644", "000000", zeroSHA, "D", "old.txt") +
+		rawRecord("100644", "100755", "ccc", "M", "scripts/run.sh") +
+		rawRecord("000000", "100644", "ddd", "A", "logo.png")
+	r := stagedRunner(diff, map[string]string{
+		"aaa": "hello\n", "bbb": "docs\n", "ccc": "#!/bin/sh\n", "ddd": binary,
+	})
+	api := happyAPI(t)
+
+	sha, err := newAPICommitter(t, r, api).Commit(context.Background(), "main", "chore: update")
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if sha != "newcommit" {
+		t.Errorf("sha = %q, want newcommit", sha)
+	}
+
+	blobs := api.callsTo("POST /git/blobs")
+	if len(blobs) != 4 {
+		t.Fatalf("uploaded %d blobs, want 4 (deletions upload nothing)", len(blobs))
+	}
+	for _, b := range blobs {
+		if b.Body["encoding"] != "base64" {
+			t.Errorf("blob encoding = %v, want base64", b.Body["encoding"])
+		}
+	}
+	if got := blobs[3].Body["content"]; got != base64.StdEncoding.EncodeToString([]byte(binary)) {
+		t.Errorf("binary blob content = %v, want bytes preserved", got)
+	}
+
+	trees := api.callsTo("POST /git/trees")
+	if len(trees) != 1 {
+		t.Fatalf("created %d trees, want 1", len(trees))
+	}
+	tree := trees[0]
+	if tree.Body["base_tree"] != treeSHA {
+		t.Errorf("base_tree = %v, want %s", tree.Body["base_tree"], treeSHA)
+	}
+	if e := treeEntry(t, tree, "new.txt"); e["sha"] != "blob1" || e["mode"] != "100644" {
+		t.Errorf("new.txt entry = %v", e)
+	}
+	if e := treeEntry(t, tree, "old.txt"); e["sha"] != nil {
+		t.Errorf("deleted entry sha = %v, want null", e["sha"])
+	}
+	if e := treeEntry(t, tree, "scripts/run.sh"); e["mode"] != "100755" {
+		t.Errorf("executable mode = %v, want 100755", e["mode"])
+	}
+
+	commits := api.callsTo("POST /git/commits")
+	if len(commits) != 1 {
+		t.Fatalf("created %d commits, want 1", len(commits))
+	}
+	commit := commits[0].Body
+	if commit["message"] != "chore: update" || commit["tree"] != "newtree" {
+		t.Errorf("commit payload = %v", commit)
+	}
+	if parents, _ := commit["parents"].([]any); len(parents) != 1 || parents[0] != headSHA {
+		t.Errorf("parents = %v, want [%s]", commit["parents"], headSHA)
+	}
+	if _, ok := commit["author"]; ok {
+		t.Error("author must be left to GitHub so it signs the commit")
+	}
+
+	refs := api.callsTo("PATCH /git/refs/heads/main")
+	if len(refs) != 1 || refs[0].Body["sha"] != "newcommit" || refs[0].Body["force"] != false {
+		t.Errorf("ref update = %v, want a non-forced move to newcommit", refs)
+	}
+
+	for _, want := range [][]string{
+		gitcmd.FetchArgs(gitcmd.RefOrigin, "main"),
+		gitcmd.ResetSoftArgs("origin/main"),
+	} {
+		if !r.Ran(gitKey(want)) {
+			t.Errorf("expected %q to run; ran %v", gitKey(want), r.Keys())
+		}
+	}
+}
+
+func TestCommit_AddsTrailersToMessage(t *testing.T) {
+	r := stagedRunner(rawRecord("000000", "100644", "aaa", "A", "a.txt"), map[string]string{"aaa": "a"})
+	api := happyAPI(t)
+	c := newAPICommitter(t, r, api)
+	c.config.UserName = "Bot"
+	c.config.UserEmail = "bot@example.com"
+	c.config.CommitSignoff = true
+	c.config.Coauthors = []string{"Jane <jane@example.com>"}
+
+	if _, err := c.Commit(context.Background(), "main", "chore: update"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	want := "chore: update\n\nSigned-off-by: Bot <bot@example.com>\nCo-authored-by: Jane <jane@example.com>\n"
+	commits := api.callsTo("POST /git/commits")
+	if len(commits) != 1 || commits[0].Body["message"] != want {
+		t.Errorf("commit message = %v, want %q", commits, want)
+	}
+}
+
+func TestCommit_NothingStaged(t *testing.T) {
+	r := stagedRunner("", nil)
+	api := happyAPI(t)
+
+	sha, err := newAPICommitter(t, r, api).Commit(context.Background(), "main", "msg")
+	if err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if sha != "" {
+		t.Errorf("sha = %q, want empty", sha)
+	}
+	if calls := api.callsTo("POST /git/commits"); len(calls) != 0 {
+		t.Errorf("no commit should be created, got %v", calls)
+	}
+}
+
+func TestCommit_RefUpdateRejected(t *testing.T) {
+	r := stagedRunner(rawRecord("000000", "100644", "aaa", "A", "a.txt"), map[string]string{"aaa": "a"})
+	api := happyAPI(t).
+		route("PATCH /git/refs/heads/main", http.StatusUnprocessableEntity, `{"message":"Update is not a fast forward"}`)
+
+	_, err := newAPICommitter(t, r, api).Commit(context.Background(), "main", "msg")
+	if err == nil || !strings.Contains(err.Error(), "Update is not a fast forward") {
+		t.Fatalf("err = %v, want the API rejection", err)
+	}
+	if r.Ran(gitKey(gitcmd.ResetSoftArgs("origin/main"))) {
+		t.Error("local branch must not move when the ref update fails")
+	}
+}
+
+func TestEscapeRef(t *testing.T) {
+	if got := escapeRef("release/v1 beta"); got != "release/v1%20beta" {
+		t.Errorf("escapeRef = %q", got)
+	}
+}
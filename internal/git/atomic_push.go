@@ -0,0 +1,57 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// DeferPush makes the commit and tag flows record their branch and tag
+// instead of pushing them, for PushPending to publish in one atomic push
+// (atomic_push). A commit without its tag, or a tag without its commit, then
+// never reaches the remote.
+func (tm *TagManager) DeferPush() {
+	tm.pending = shared.NewAtomicPush()
+}
+
+// PendingPush returns the push collecting the refs of the run, or nil when
+// pushes are not deferred.
+func (tm *TagManager) PendingPush() *shared.AtomicPush {
+	if tm == nil {
+		return nil
+	}
+	return tm.pending
+}
+
+// PushPending pushes the refs recorded since DeferPush in a single atomic
+// push and then publishes the release for the tag, which could not be done
+// before the tag reached the remote.
+func (tm *TagManager) PushPending(ctx context.Context, result *output.Result) error {
+	if tm.pending == nil {
+		return nil
+	}
+
+	fmt.Println("\nPushing Commit and Tag:")
+	err := withRetry(ctx, tm.config.RetryCount, func() error {
+		return tm.pending.Push(tm.runner)
+	})
+	if err != nil {
+		return err
+	}
+
+	if branch, before := tm.pending.Branch(); branch != "" {
+		if after, err := shared.CurrentCommitSHA(tm.runner); err == nil {
+			recordBranchPush(ctx, tm.runner, branch, before, after)
+		}
+	}
+	tm.recordTagPushes(ctx)
+
+	if tm.nothingToRelease || tm.config.TagName == "" {
+		return nil
+	}
+	return withRetry(ctx, tm.config.RetryCount, func() error {
+		return tm.publishRelease(ctx, result)
+	})
+}
@@ -0,0 +1,108 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// With atomic_push neither the commit nor the tag is pushed on its own: both
+// go out in the final atomic push.
+func TestPushPending_PushesCommitAndTagTogether(t *testing.T) {
+	cfg := tagConfig("v1.2.3")
+	cfg.AtomicPush = true
+	f := gitcmd.NewFakeRunner()
+	tm := NewTagManagerWithRunner(cfg, f)
+	tm.DeferPush()
+	result := output.NewResult()
+
+	if err := commitChanges(context.Background(), f, cfg, result, "", tm.PendingPush()); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, "main"))) || f.Ran(key(gitcmd.PushTagArgs("v1.2.3", true))) {
+		t.Fatalf("Keys() = %v, want no push before PushPending", f.Keys())
+	}
+
+	if err := tm.PushPending(context.Background(), result); err != nil {
+		t.Fatalf("PushPending() error = %v, want nil", err)
+	}
+	assertSequence(t, f.Keys(), []string{
+		commitKey(cfg.CommitMessage),
+		key(gitcmd.TagCreateArgs("v1.2.3", true)),
+		key(gitcmd.PushAtomicArgs(gitcmd.RefOrigin, []string{"main", "+refs/tags/v1.2.3"})),
+	})
+}
+
+// A retried tag step must not list the tag twice in the push.
+func TestPushPending_RetriedTagIsPushedOnce(t *testing.T) {
+	cfg := tagConfig("v1.2.3")
+	cfg.RetryCount = 2
+	f := gitcmd.NewFakeRunner()
+	tm := NewTagManagerWithRunner(cfg, f)
+	tm.DeferPush()
+
+	for i := 0; i < 2; i++ {
+		if err := tm.HandleGitTag(context.Background(), output.NewResult()); err != nil {
+			t.Fatalf("HandleGitTag() error = %v, want nil", err)
+		}
+	}
+	if err := tm.PushPending(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("PushPending() error = %v, want nil", err)
+	}
+
+	want := key(gitcmd.PushAtomicArgs(gitcmd.RefOrigin, []string{"+refs/tags/v1.2.3"}))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want %q", f.Keys(), want)
+	}
+}
+
+// An atomic push rejected because the branch moved on is not retried: it
+// would be rejected again.
+func TestPushPending_RejectedPushIsPermanent(t *testing.T) {
+	cfg := tagConfig("v1.2.3")
+	cfg.RetryCount = 3
+	atomicKey := key(gitcmd.PushAtomicArgs(gitcmd.RefOrigin, []string{"main", "+refs/tags/v1.2.3"}))
+	f := gitcmd.NewFakeRunner().
+		Stub(atomicKey, gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
+		Stub(key(gitcmd.MergeBaseIsAncestorArgs("origin/main", "HEAD")), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
+	tm := NewTagManagerWithRunner(cfg, f)
+	tm.DeferPush()
+	tm.PendingPush().SetBranch("main", "9f1c0de", nil)
+	tm.PendingPush().AddTags("v1.2.3")
+
+	err := tm.PushPending(context.Background(), output.NewResult())
+	if !errors.IsPermanent(err) {
+		t.Fatalf("PushPending() error = %v, want a permanent error", err)
+	}
+
+	pushes := 0
+	for _, k := range f.Keys() {
+		if k == atomicKey {
+			pushes++
+		}
+	}
+	if pushes != 1 {
+		t.Errorf("pushed %d times, want 1", pushes)
+	}
+	if !f.Ran(key(gitcmd.FetchArgs(gitcmd.RefOrigin, "main"))) {
+		t.Errorf("Keys() = %v, want the branch fetched to confirm the rejection", f.Keys())
+	}
+}
+
+func TestPushPending_NoopWithoutDeferPush(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+	tm := NewTagManagerWithRunner(tagConfig("v1.2.3"), f)
+
+	if err := tm.PushPending(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("PushPending() error = %v, want nil", err)
+	}
+	if len(f.Calls()) != 0 {
+		t.Errorf("Keys() = %v, want no commands", f.Keys())
+	}
+}
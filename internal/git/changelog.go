@@ -0,0 +1,225 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+const (
+	changelogTitle      = "# Changelog"
+	changelogDateFormat = "2006-01-02"
+	changelogOtherGroup = "Other Changes"
+	changelogBreaking   = "Breaking Changes"
+)
+
+// changelogGroups lists the Conventional Commit types that get their own
+// section, in render order. Any other type falls into changelogOtherGroup.
+var changelogGroups = []struct {
+	Type  string
+	Title string
+}{
+	{"feat", "Features"},
+	{"fix", "Bug Fixes"},
+	{"perf", "Performance Improvements"},
+	{"refactor", "Code Refactoring"},
+	{"revert", "Reverts"},
+	{"docs", "Documentation"},
+	{"test", "Tests"},
+	{"build", "Build System"},
+	{"ci", "Continuous Integration"},
+	{"chore", "Chores"},
+}
+
+// PrepareChangelog renders the changelog section for the tag about to be
+// created, prepends it to changelog_file and stages that file so it lands in
+// the commit made by the commit flow, ahead of createTag. The rendered section
+// is also published as the multi-line changelog output.
+//
+// It is safe to call again on a retry: a file that already carries the section
+// heading for this tag is left untouched.
+func (tm *TagManager) PrepareChangelog(result *output.Result) error {
+	if !tm.config.Changelog {
+		return nil
+	}
+
+	fmt.Println("\nPreparing Changelog:")
+
+	if err := tm.fetchTags(); err != nil {
+		return err
+	}
+	if err := tm.resolveBumpedTag(); err != nil {
+		return err
+	}
+	if tm.nothingToRelease {
+		fmt.Println("  - [WARN] Nothing to release, skipping changelog")
+		return nil
+	}
+
+	previousTag := tm.changelogBaseTag()
+
+	commits := tm.commits
+	if tm.config.TagBump != config.TagBumpAuto {
+		var err error
+		if commits, err = tm.commitsSince(previousTag); err != nil {
+			return err
+		}
+	}
+
+	section := renderChangelog(tm.config.TagName, time.Now().UTC(), commits)
+
+	fmt.Printf("  - Updating %s... ", tm.config.ChangelogFile)
+	written, err := prependChangelog(tm.config.ChangelogFile, tm.config.TagName, section)
+	if err != nil {
+		fmt.Println("FAILED")
+		return err
+	}
+	if written {
+		fmt.Println("Done")
+	} else {
+		fmt.Println("already up to date")
+	}
+
+	if err := shared.RunStep(tm.runner, "Staging changelog", gitcmd.CmdGit, gitcmd.AddArgs(tm.config.ChangelogFile)...); err != nil {
+		return errors.NewWithPath("stage changelog", tm.config.ChangelogFile, err)
+	}
+
+	tm.changelog = section
+	result.Set(output.KeyChangelog, section)
+	return nil
+}
+
+// changelogBaseTag returns the tag the changelog range starts from: the
+// version tag_bump computed from, or else the most recent tag reachable from
+// the tag target. It returns "" when there is no earlier tag.
+func (tm *TagManager) changelogBaseTag() string {
+	if tm.config.TagBump != "" {
+		return tm.previousTag
+	}
+
+	target := tm.config.TagReference
+	if target == "" {
+		target = "HEAD"
+	}
+	out, err := tm.runner.Output(gitcmd.CmdGit, gitcmd.DescribeLatestTagArgs(target, tm.config.TagName)...)
+	if err != nil {
+		// describe fails when no tag is reachable; the whole history is then
+		// the first release.
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// changelogHeading returns the heading line prefix that identifies the section
+// for tag, used both to render it and to detect an existing one.
+func changelogHeading(tag string) string {
+	return "## " + tag + " ("
+}
+
+// renderChangelog renders one changelog section for tag, grouping commits by
+// Conventional Commit type. Breaking changes are listed first regardless of
+// their type.
+func renderChangelog(tag string, date time.Time, commits []conventionalCommit) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s%s)\n", changelogHeading(tag), date.Format(changelogDateFormat))
+
+	grouped := make(map[string][]conventionalCommit)
+	for _, c := range commits {
+		grouped[changelogGroupTitle(c)] = append(grouped[changelogGroupTitle(c)], c)
+	}
+
+	titles := []string{changelogBreaking}
+	for _, g := range changelogGroups {
+		titles = append(titles, g.Title)
+	}
+	titles = append(titles, changelogOtherGroup)
+
+	for _, title := range titles {
+		entries := grouped[title]
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n", title)
+		for _, c := range entries {
+			b.WriteString(renderChangelogEntry(c))
+		}
+	}
+
+	if len(commits) == 0 {
+		b.WriteString("\nNo changes.\n")
+	}
+
+	return b.String()
+}
+
+// changelogGroupTitle returns the section a commit is listed under.
+func changelogGroupTitle(c conventionalCommit) string {
+	if c.Breaking {
+		return changelogBreaking
+	}
+	for _, g := range changelogGroups {
+		if g.Type == c.Type {
+			return g.Title
+		}
+	}
+	return changelogOtherGroup
+}
+
+// renderChangelogEntry renders a single bullet: scope, subject, short SHA and
+// author.
+func renderChangelogEntry(c conventionalCommit) string {
+	subject := c.Subject
+	if c.Scope != "" {
+		subject = fmt.Sprintf("**%s:** %s", c.Scope, subject)
+	}
+	return fmt.Sprintf("- %s (%s) by %s\n", subject, shortenCommitSHA(c.SHA), c.Author)
+}
+
+// prependChangelog inserts section at the top of the changelog at path, below
+// its "# Changelog" title when present, creating the file if needed. It
+// reports false without writing when the file already has a section for tag.
+func prependChangelog(path, tag, section string) (bool, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.NewWithPath("read changelog", path, err)
+	}
+
+	content := string(existing)
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, changelogHeading(tag)) {
+			return false, nil
+		}
+	}
+
+	var updated string
+	switch {
+	case content == "":
+		updated = changelogTitle + "\n\n" + section
+	case strings.HasPrefix(content, "# "):
+		title, rest, _ := strings.Cut(content, "\n")
+		updated = title + "\n\n" + section
+		if rest = strings.TrimLeft(rest, "\n"); rest != "" {
+			updated += "\n" + rest
+		}
+	default:
+		updated = section + "\n" + content
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, permDir); err != nil {
+			return false, errors.NewWithPath("create directory", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(updated), permFile); err != nil {
+		return false, errors.NewWithPath("write changelog", path, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,188 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+func TestRenderChangelog_GroupsByType(t *testing.T) {
+	commits := []conventionalCommit{
+		{SHA: "1111111111aa", Author: "Ann", Type: "fix", Subject: "handle nil"},
+		{SHA: "2222222222bb", Author: "Bob", Type: "feat", Scope: "cli", Subject: "add flag"},
+		{SHA: "3333333333cc", Author: "Cy", Type: "feat", Subject: "drop v1", Breaking: true},
+		{SHA: "4444444444dd", Author: "Di", Subject: "Update README"},
+	}
+
+	got := renderChangelog("v2.0.0", time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), commits)
+
+	wantOrder := []string{
+		"## v2.0.0 (2026-01-02)",
+		"### Breaking Changes",
+		"- drop v1 (33333333) by Cy",
+		"### Features",
+		"- **cli:** add flag (22222222) by Bob",
+		"### Bug Fixes",
+		"- handle nil (11111111) by Ann",
+		"### Other Changes",
+		"- Update README (44444444) by Di",
+	}
+	pos := 0
+	for _, want := range wantOrder {
+		i := strings.Index(got[pos:], want)
+		if i < 0 {
+			t.Fatalf("renderChangelog() =\n%s\nwant %q after offset %d", got, want, pos)
+		}
+		pos += i + len(want)
+	}
+}
+
+func TestRenderChangelog_NoCommits(t *testing.T) {
+	got := renderChangelog("v1.0.1", time.Now(), nil)
+	if !strings.Contains(got, "No changes.") {
+		t.Errorf("renderChangelog(nil) = %q, want a no-changes note", got)
+	}
+}
+
+func TestPrependChangelog(t *testing.T) {
+	const section = "## v1.1.0 (2026-01-02)\n\n### Features\n\n- new\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{
+			name: "new file",
+			want: "# Changelog\n\n" + section,
+		},
+		{
+			name:     "below title",
+			existing: "# Changelog\n\n## v1.0.0 (2025-12-01)\n\n- old\n",
+			want:     "# Changelog\n\n" + section + "\n## v1.0.0 (2025-12-01)\n\n- old\n",
+		},
+		{
+			name:     "no title",
+			existing: "## v1.0.0 (2025-12-01)\n",
+			want:     section + "\n## v1.0.0 (2025-12-01)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+			if tt.existing != "" {
+				os.WriteFile(path, []byte(tt.existing), 0644)
+			}
+
+			written, err := prependChangelog(path, "v1.1.0", section)
+			if err != nil || !written {
+				t.Fatalf("prependChangelog() = (%v, %v), want (true, nil)", written, err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("changelog =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrependChangelog_Idempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	section := "## v1.1.0 (2026-01-02)\n\n- new\n"
+
+	if _, err := prependChangelog(path, "v1.1.0", section); err != nil {
+		t.Fatalf("prependChangelog() error = %v", err)
+	}
+	written, err := prependChangelog(path, "v1.1.0", section)
+	if err != nil || written {
+		t.Errorf("second prependChangelog() = (%v, %v), want (false, nil)", written, err)
+	}
+	got, _ := os.ReadFile(path)
+	if strings.Count(string(got), "## v1.1.0") != 1 {
+		t.Errorf("changelog = %q, want the section exactly once", got)
+	}
+}
+
+func TestPrepareChangelog_ExplicitTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	cfg := tagConfig("v1.3.0")
+	cfg.Changelog = true
+	cfg.ChangelogFile = path
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DescribeLatestTagArgs("HEAD", "v1.3.0")), gitcmd.FakeResult{Stdout: "v1.2.0\n"}).
+		Stub(key(gitcmd.LogArgs("v1.2.0..HEAD")), gitcmd.FakeResult{Stdout: logOutput(
+			[4]string{"abcdef0123", "Ann", "fix: off by one", ""},
+		)})
+	tm := NewTagManagerWithRunner(cfg, f)
+	result := output.NewResult()
+
+	if err := tm.PrepareChangelog(result); err != nil {
+		t.Fatalf("PrepareChangelog() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.FetchTagsArgs()),
+		key(gitcmd.LogArgs("v1.2.0..HEAD")),
+		key(gitcmd.AddArgs(path)),
+	})
+	got, _ := os.ReadFile(path)
+	if !strings.Contains(string(got), "- off by one (abcdef01) by Ann") {
+		t.Errorf("changelog = %q, want the fix entry", got)
+	}
+	if out := result.Get(output.KeyChangelog); !strings.HasPrefix(out, "## v1.3.0 (") {
+		t.Errorf("changelog output = %q, want the rendered section", out)
+	}
+}
+
+func TestPrepareChangelog_DisabledDoesNothing(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+	tm := NewTagManagerWithRunner(tagConfig("v1.0.0"), f)
+
+	if err := tm.PrepareChangelog(output.NewResult()); err != nil {
+		t.Fatalf("PrepareChangelog() error = %v, want nil", err)
+	}
+	if len(f.Keys()) != 0 {
+		t.Errorf("Keys() = %v, want no commands when changelog is off", f.Keys())
+	}
+}
+
+// The changelog must be staged before the commit flow commits, and the tag
+// computed for it must be the one HandleGitTag creates afterwards.
+func TestRunGitCommit_ChangelogCommittedBeforeTag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	cfg := bumpConfig(config.TagBumpAuto)
+	cfg.Changelog = true
+	cfg.ChangelogFile = path
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v0.9.0\n"}).
+		Stub(key(gitcmd.LogArgs("v0.9.0..HEAD")), gitcmd.FakeResult{Stdout: logOutput(
+			[4]string{"abcdef0123", "Ann", "feat: shiny", ""},
+		)})
+	tm := NewTagManagerWithRunner(cfg, f)
+	result := output.NewResult()
+
+	if err := RunGitCommitWithRunner(context.Background(), f, cfg, result, tm); err != nil {
+		t.Fatalf("RunGitCommitWithRunner() error = %v, want nil", err)
+	}
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.AddArgs(path)),
+		commitKey(cfg.CommitMessage),
+		key(gitcmd.TagCreateArgs("v0.10.0", true)),
+		key(gitcmd.PushTagArgs("v0.10.0", true)),
+	})
+	if strings.Count(strings.Join(f.Keys(), "\n"), key(gitcmd.TagListArgs())) != 1 {
+		t.Errorf("Keys() = %v, want the version computed exactly once", f.Keys())
+	}
+}
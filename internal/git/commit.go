@@ -2,14 +2,19 @@ package git
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
 	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/apicommit"
+	"github.com/somaz94/go-git-commit-action/internal/git/guard"
+	"github.com/somaz94/go-git-commit-action/internal/git/lock"
 	"github.com/somaz94/go-git-commit-action/internal/git/shared"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 	"github.com/somaz94/go-git-commit-action/internal/output"
@@ -33,6 +38,7 @@ type FileBackup struct {
 // withRetry provides retry logic for operations that might fail transiently.
 // It executes the given operation repeatedly until it succeeds or the maximum
 // number of retries is reached. The delay between retries increases linearly.
+// An error marked permanent (errors.Permanent) is returned without retrying.
 func withRetry(ctx context.Context, maxRetries int, operation func() error) error {
 	var lastErr error
 	for i := 0; i < maxRetries; i++ {
@@ -41,6 +47,9 @@ func withRetry(ctx context.Context, maxRetries int, operation func() error) erro
 			return ctx.Err()
 		default:
 			if err := operation(); err != nil {
+				if errors.IsPermanent(err) {
+					return err
+				}
 				lastErr = err
 				// Honor context cancellation during backoff instead of
 				// blocking for the full linear delay.
@@ -59,14 +68,17 @@ func withRetry(ctx context.Context, maxRetries int, operation func() error) erro
 
 // RunGitCommit executes the Git commit operation with the provided configuration.
 // It wraps the entire process in a retry mechanism to handle transient failures.
-func RunGitCommit(ctx context.Context, config *config.GitConfig, result *output.Result) error {
-	return RunGitCommitWithRunner(ctx, gitcmd.NewExecRunner(), config, result)
+// tagManager is the TagManager that will create the tag after the commit, or
+// nil when no tag operation is configured; the commit flow uses it to prepare
+// files that belong in the tagged commit, such as the changelog.
+func RunGitCommit(ctx context.Context, config *config.GitConfig, result *output.Result, tagManager *TagManager) error {
+	return RunGitCommitWithRunner(ctx, gitcmd.NewExecRunner(), config, result, tagManager)
 }
 
 // RunGitCommitWithRunner is RunGitCommit with an explicit command Runner.
 // Tests use it to drive the full workflow against a fake instead of a real
 // repository; production callers should use RunGitCommit.
-func RunGitCommitWithRunner(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result) error {
+func RunGitCommitWithRunner(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result, tagManager *TagManager) error {
 	// Save the original working directory to restore before each retry
 	originalDir, err := os.Getwd()
 	if err != nil {
@@ -85,12 +97,12 @@ func RunGitCommitWithRunner(ctx context.Context, r gitcmd.Runner, config *config
 		if err := os.Chdir(originalDir); err != nil {
 			return errors.NewWithPath("restore working directory", originalDir, err)
 		}
-		return executeGitCommitWorkflow(ctx, r, config, result)
+		return executeGitCommitWorkflow(ctx, r, config, result, tagManager)
 	})
 }
 
 // executeGitCommitWorkflow runs all steps of the Git commit process
-func executeGitCommitWorkflow(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result) error {
+func executeGitCommitWorkflow(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result, tagManager *TagManager) error {
 	// Validate the configuration
 	if err := config.Validate(); err != nil {
 		return err
@@ -111,8 +123,28 @@ func executeGitCommitWorkflow(ctx context.Context, r gitcmd.Runner, config *conf
 		return err
 	}
 
-	// Handle the branch
-	if err := handleBranch(r, config); err != nil {
+	// With remote_lock, wait until no other run works on the branch
+	if err := lock.FromContext(ctx).Acquire(ctx); err != nil {
+		return err
+	}
+
+	// Handle the branch, remembering where the remote branch stood for
+	// push_force: lease
+	remoteSHA, err := handleBranch(ctx, r, config)
+	if err != nil {
+		return err
+	}
+
+	// Write and stage the changelog so it is part of the commit being tagged
+	if tagManager != nil {
+		if err := tagManager.PrepareChangelog(result); err != nil {
+			return err
+		}
+	}
+
+	// Run pre_commit_command before the changes are detected, so that what it
+	// modifies is detected and committed as well
+	if err := runPreCommitCommand(r, config); err != nil {
 		return err
 	}
 
@@ -132,15 +164,64 @@ func executeGitCommitWorkflow(ctx context.Context, r gitcmd.Runner, config *conf
 	result.Set(output.KeySkipped, "false")
 
 	// Count changed files
-	changedFiles := countChangedFiles(r)
-	result.Set(output.KeyChangedFiles, fmt.Sprintf("%d", changedFiles))
+	files := changedFiles(r)
+	result.Set(output.KeyChangedFiles, fmt.Sprintf("%d", len(files)))
+
+	// Render the commit message and PR templates now that the changed files
+	// are known. The rendered copy leaves config untouched for a retry. Split
+	// pull requests render them with the files of each instead.
+	if config.PRSplitBy == "" {
+		config, err = renderTemplates(r, config, files)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Create a PR or commit directly based on configuration
 	if config.CreatePR {
-		return handlePullRequestFlow(ctx, r, config, result)
+		return handlePullRequestFlow(ctx, r, config, result, remoteSHA)
 	}
 
-	return commitChanges(r, config, result)
+	// With atomic_push the branch waits for the tag; tagManager pushes both
+	return commitChanges(ctx, r, config, result, remoteSHA, tagManager.PendingPush())
+}
+
+// runPreCommitCommand runs pre_commit_command, such as a formatter, with the
+// shell in the repository. Files staged before it ran, such as the changelog,
+// are staged again so that its modifications to them are committed; the other
+// files it modifies are staged with file_pattern. A failure reports what the
+// command printed.
+func runPreCommitCommand(r gitcmd.Runner, config *config.GitConfig) error {
+	if config.PreCommitCommand == "" {
+		return nil
+	}
+	staged, err := shared.StagedChanges(r)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\nRunning Pre-commit Command:")
+	fmt.Printf("  - %s... ", config.PreCommitCommand)
+	out, err := r.CombinedOutput(gitcmd.CmdShell, gitcmd.ShellCommandArgs(config.PreCommitCommand)...)
+	if err != nil {
+		fmt.Println("FAILED")
+		return shared.CommandFailure(config.PreCommitCommand, out, err)
+	}
+	fmt.Println("Done")
+	if output := strings.TrimRight(string(out), "\n"); output != "" {
+		fmt.Println("    " + strings.ReplaceAll(output, "\n", "\n    "))
+	}
+
+	var restage []string
+	for _, change := range staged {
+		if change.Mode != shared.ModeDeleted {
+			restage = append(restage, gitcmd.PathspecLiteral+change.Path)
+		}
+	}
+	if len(restage) == 0 {
+		return nil
+	}
+	return shared.RunStep(r, "Re-staging staged files", gitcmd.CmdGit, gitcmd.AddPathspecsArgs(restage)...)
 }
 
 // printDebugInfo outputs debug information about the current environment.
@@ -260,8 +341,9 @@ func setupGitCredentials(r gitcmd.Runner, config *config.GitConfig) error {
 }
 
 // handleBranch manages branch-related operations, checking for local and remote
-// branch existence and taking appropriate action.
-func handleBranch(r gitcmd.Runner, config *config.GitConfig) error {
+// branch existence and taking appropriate action. It returns the commit the
+// remote branch points at afterwards, or "" if the remote has no such branch.
+func handleBranch(ctx context.Context, r gitcmd.Runner, config *config.GitConfig) (string, error) {
 	// These are existence probes, so Output is used rather than Run: only the
 	// exit status matters and the command's own output must stay off the log.
 	_, localErr := r.Output(gitcmd.CmdGit, gitcmd.RevParseArgs(config.Branch)...)
@@ -271,19 +353,34 @@ func handleBranch(r gitcmd.Runner, config *config.GitConfig) error {
 	// the exit status alone would report every branch as existing whenever the
 	// remote is merely reachable. The listing itself is the answer.
 	remoteRefs, remoteErr := r.Output(gitcmd.CmdGit, gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, config.Branch)...)
-	remoteBranchExists := remoteErr == nil && len(strings.TrimSpace(string(remoteRefs))) > 0
+	var remoteSHA string
+	if remoteErr == nil {
+		if fields := strings.Fields(string(remoteRefs)); len(fields) > 0 {
+			remoteSHA = fields[0]
+		}
+	}
+	remoteBranchExists := remoteSHA != ""
 
 	// Determine the appropriate action based on branch existence
 	if !localBranchExists && !remoteBranchExists {
-		// Neither local nor remote branch exists, create a new one
-		return createNewBranch(r, config)
+		// Neither local nor remote branch exists, create a new one. Pushing
+		// it leaves the remote branch at HEAD.
+		if err := createNewBranch(r, config); err != nil {
+			return "", err
+		}
+		headSHA, err := shared.CurrentCommitSHA(r)
+		if err != nil {
+			return "", err
+		}
+		recordBranchPush(ctx, r, config.Branch, "", headSHA)
+		return headSHA, nil
 	} else if !localBranchExists && remoteBranchExists {
 		// Only remote branch exists, check it out
-		return checkoutRemoteBranch(r, config)
+		return remoteSHA, checkoutRemoteBranch(r, config)
 	}
 
 	// Local branch already exists and is checked out, nothing to do
-	return nil
+	return remoteSHA, nil
 }
 
 // createNewBranch creates a new branch and pushes it to the remote repository.
@@ -473,25 +570,54 @@ func printChangeDetectionInfo(statusOutput, diffOutput []byte, hasLocalChanges,
 	}
 }
 
-// countChangedFiles counts the number of changed files in the working directory.
-func countChangedFiles(r gitcmd.Runner) int {
+// changedFiles lists the paths of the changed files in the working
+// directory. A rename is reported by its new path.
+func changedFiles(r gitcmd.Runner) []string {
 	statusOutput, err := r.Output(gitcmd.CmdGit, gitcmd.StatusPorcelainArgs()...)
 	if err != nil {
-		return 0
+		return nil
 	}
-	count := 0
+	var files []string
 	for _, line := range strings.Split(string(statusOutput), "\n") {
-		if strings.TrimSpace(line) != "" {
-			count++
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// "XY path" or "XY old -> new"
+		path := strings.TrimSpace(line)
+		if len(line) > 3 {
+			path = line[3:]
+		}
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+len(" -> "):]
 		}
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		files = append(files, path)
 	}
-	return count
+	return files
+}
+
+// setStagedFiles sets the staged_files output to the JSON array of the paths
+// staged for the commit. A nil list, when the paths could not be listed,
+// leaves the output unset.
+func setStagedFiles(result *output.Result, files []string) {
+	if files == nil {
+		return
+	}
+	data, _ := json.Marshal(files) // a []string always encodes
+	result.Set(output.KeyStagedFiles, string(data))
 }
 
 // handlePullRequestFlow manages the creation of pull requests
 // based on the auto_branch configuration.
-func handlePullRequestFlow(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result) error {
-	if config.AutoBranch {
+func handlePullRequestFlow(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result, remoteSHA string) error {
+	if config.PRSplitBy != "" {
+		// One auto branch and PR per directory or commit group
+		if err := createSplitPullRequests(ctx, r, config, result); err != nil {
+			return errors.New("create split pull requests", err)
+		}
+	} else if config.AutoBranch {
 		// Auto branch creation and PR creation in one step
 		if err := CreatePullRequest(ctx, r, config, result); err != nil {
 			return errors.New("create pull request with auto branch", err)
@@ -500,7 +626,7 @@ func handlePullRequestFlow(ctx context.Context, r gitcmd.Runner, config *config.
 		// In dry run mode, skip actual commit/push since we only simulate PR creation
 		if !config.PRDryRun {
 			// First commit changes to the specified branch
-			if err := commitChanges(r, config, result); err != nil {
+			if err := commitChanges(ctx, r, config, result, remoteSHA, nil); err != nil {
 				return err
 			}
 		}
@@ -513,18 +639,45 @@ func handlePullRequestFlow(ctx context.Context, r gitcmd.Runner, config *config.
 	return nil
 }
 
-// commitChanges stages, commits, and pushes the specified files.
-func commitChanges(r gitcmd.Runner, config *config.GitConfig, result *output.Result) error {
+// commitChanges stages, commits, and pushes the specified files. With
+// commit_via_api the commit is created through the GitHub API instead, and
+// with commit_groups one commit is made per group.
+// remoteSHA is where the remote branch was seen, the lease of a
+// push_force: lease push. A non-nil deferred receives the branch instead of
+// it being pushed.
+func commitChanges(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, result *output.Result, remoteSHA string, deferred *shared.AtomicPush) error {
+	if len(config.CommitGroups()) > 0 {
+		return commitGroups(ctx, r, config, result, remoteSHA, deferred)
+	}
+
 	// Stage files first
-	if err := StageFiles(r, config.FilePattern); err != nil {
+	if err := StageFiles(r, config.FilePatterns(), config.FileExcludes()); err != nil {
+		return err
+	}
+	if files, err := shared.StagedFiles(r); err == nil {
+		setStagedFiles(result, files)
+	}
+	if err := guard.NewCheckerWithRunner(config, r).Check(); err != nil {
 		return err
 	}
 
-	// Perform commit and push (existing tracked branch — no upstream flag).
-	// TolerateNothingToCommit preserves the prior batch behavior where an empty
-	// commit is a skipped no-op rather than a failure.
-	if err := shared.CommitAndPush(r, config.CommitMessage, config.Branch,
-		shared.CommitPushOptions{TolerateNothingToCommit: true}); err != nil {
+	// base is the remote commit the push built on, restored on rollback.
+	base := remoteSHA
+	var err error
+	if config.CommitViaAPI {
+		if _, err := apicommit.NewCommitterWithRunner(config, r).Commit(ctx, config.Branch, config.CommitMessage); err != nil {
+			return err
+		}
+	} else if base, err = shared.CommitAndPush(r, config.CommitMessage, config.Branch,
+		// Perform commit and push (existing tracked branch — no upstream flag).
+		// TolerateNothingToCommit preserves the prior batch behavior where an
+		// empty commit is a skipped no-op rather than a failure.
+		shared.CommitOptionsFor(config, shared.CommitPushOptions{
+			TolerateNothingToCommit: true,
+			Force:                   config.PushForce,
+			ExpectedSHA:             remoteSHA,
+			Deferred:                deferred,
+		})); err != nil {
 		return err
 	}
 
@@ -532,6 +685,10 @@ func commitChanges(r gitcmd.Runner, config *config.GitConfig, result *output.Res
 	commitSHA, err := shared.CurrentCommitSHA(r)
 	if err == nil {
 		result.Set(output.KeyCommitSHA, commitSHA)
+		// A deferred push is recorded by the atomic push itself
+		if deferred == nil {
+			recordBranchPush(ctx, r, config.Branch, base, commitSHA)
+		}
 	}
 
 	return nil
@@ -0,0 +1,194 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/guard"
+	"github.com/somaz94/go-git-commit-action/internal/git/pr"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// commitGroups commits the changes in one commit per commit_groups entry, in
+// order, then the changes no entry matched as commit_groups_unmatched asks,
+// and pushes the branch once. Entries only take changes file_pattern and
+// file_exclude select, a file matched by several entries going with the
+// first; entries matching no change are skipped.
+//
+// Files staged before, such as the changelog, are unstaged first and
+// committed last, so that they land in the commit a tag points at.
+func commitGroups(ctx context.Context, r gitcmd.Runner, cfg *config.GitConfig, result *output.Result, remoteSHA string, deferred *shared.AtomicPush) error {
+	prestaged, err := unstageAll(r)
+	if err != nil {
+		return err
+	}
+	groups := cfg.CommitGroups()
+	matched, err := matchGroups(r, cfg, groups, prestaged)
+	if err != nil {
+		return err
+	}
+
+	opts := shared.CommitOptionsFor(cfg, shared.CommitPushOptions{
+		TolerateNothingToCommit: true,
+		Force:                   cfg.PushForce,
+		ExpectedSHA:             remoteSHA,
+		Deferred:                deferred,
+	})
+	var shas []string
+	staged := []string{}
+	// commit commits the staged files with message, rendered when it is a
+	// group's own message.
+	commit := func(message string, render bool) error {
+		files, err := shared.StagedFiles(r)
+		if err != nil {
+			return errors.New("list staged files", err)
+		}
+		if len(files) == 0 {
+			fmt.Println("  - No changes matched, skipping")
+			return nil
+		}
+		if render {
+			if message, err = renderGroupMessage(r, cfg, message, files); err != nil {
+				return err
+			}
+		}
+		if err := guard.NewCheckerWithRunner(cfg, r).Check(); err != nil {
+			return err
+		}
+		if committed, err := shared.Commit(r, message, opts); err != nil || !committed {
+			return err
+		}
+		sha, err := shared.CurrentCommitSHA(r)
+		if err != nil {
+			return errors.New("resolve commit", err)
+		}
+		shas = append(shas, sha)
+		staged = append(staged, files...)
+		return nil
+	}
+
+	for i, group := range groups {
+		fmt.Printf("\nCommit Group %d/%d (%s):\n", i+1, len(groups), strings.Join(group.Paths, " "))
+		if len(matched[i]) > 0 {
+			if err := shared.RunStep(r, "Adding files", gitcmd.CmdGit, gitcmd.AddPathspecsArgs(matched[i])...); err != nil {
+				return err
+			}
+		}
+		message, render := group.Message, true
+		if message == "" {
+			message, render = cfg.CommitMessage, false
+		}
+		if err := commit(message, render); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("\nCommitting Remaining Changes:")
+	if cfg.GroupsUnmatched != config.GroupsUnmatchedLeave {
+		if err := shared.StageFiles(r, cfg.FilePatterns(), cfg.FileExcludes()); err != nil {
+			return err
+		}
+	}
+	if len(prestaged) > 0 {
+		if err := shared.RunStep(r, "Restoring files staged earlier", gitcmd.CmdGit, gitcmd.AddPathspecsArgs(prestaged)...); err != nil {
+			return err
+		}
+	}
+	if err := commit(cfg.CommitMessage, false); err != nil {
+		return err
+	}
+
+	setStagedFiles(result, staged)
+	if len(shas) == 0 {
+		fmt.Println("  - [WARN] Nothing to commit, skipping push...")
+		return nil
+	}
+	data, _ := json.Marshal(shas) // a []string always encodes
+	result.Set(output.KeyCommitSHAs, string(data))
+	commitSHA := shas[len(shas)-1]
+	result.Set(output.KeyCommitSHA, commitSHA)
+
+	base, err := shared.Push(r, cfg.Branch, opts)
+	if err != nil {
+		return err
+	}
+	// A deferred push is recorded by the atomic push itself
+	if deferred == nil {
+		recordBranchPush(ctx, r, cfg.Branch, base, commitSHA)
+	}
+	return nil
+}
+
+// matchGroups returns, for each of groups, the literal pathspecs of the
+// changes it commits: those selected by file_pattern and file_exclude that
+// its paths match and no earlier group took. Paths in skip, literal
+// pathspecs, are left to no group. Git matches the pathspecs against the
+// staged changes, so a path that no longer exists matches nothing rather
+// than failing git add. The index is left empty.
+func matchGroups(r gitcmd.Runner, cfg *config.GitConfig, groups []config.CommitGroup, skip []string) ([][]string, error) {
+	if err := shared.StageFiles(r, cfg.FilePatterns(), cfg.FileExcludes()); err != nil {
+		return nil, err
+	}
+
+	taken := map[string]bool{}
+	for _, pathspec := range skip {
+		taken[strings.TrimPrefix(pathspec, gitcmd.PathspecLiteral)] = true
+	}
+	diff := pr.NewDiffCheckerWithRunner(cfg, r)
+	matched := make([][]string, len(groups))
+	for i, group := range groups {
+		paths, err := diff.StagedPaths(group.Paths...)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			if !taken[path] {
+				taken[path] = true
+				matched[i] = append(matched[i], gitcmd.PathspecLiteral+path)
+			}
+		}
+	}
+
+	if err := shared.RunStep(r, "Unstaging files", gitcmd.CmdGit, gitcmd.ResetIndexArgs()...); err != nil {
+		return nil, errors.New("unstage files", err)
+	}
+	return matched, nil
+}
+
+// unstageAll unstages every staged change and returns the literal pathspecs
+// of the staged paths, to stage them again later.
+func unstageAll(r gitcmd.Runner) ([]string, error) {
+	changes, err := shared.StagedChanges(r)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+
+	pathspecs := make([]string, len(changes))
+	for i, change := range changes {
+		pathspecs[i] = gitcmd.PathspecLiteral + change.Path
+	}
+	if err := shared.RunStep(r, "Unstaging files staged earlier", gitcmd.CmdGit, gitcmd.ResetIndexArgs()...); err != nil {
+		return nil, errors.New("unstage files", err)
+	}
+	return pathspecs, nil
+}
+
+// renderGroupMessage renders the message of a commit_groups entry as a
+// template whose ChangedFiles are the files committed with it.
+func renderGroupMessage(r gitcmd.Runner, cfg *config.GitConfig, message string, files []string) (string, error) {
+	if !hasTemplate(message) {
+		return message, nil
+	}
+	sha, _ := shared.CurrentCommitSHA(r)
+	rendered, err := config.RenderTemplate("commit_groups", message, config.NewTemplateData(cfg.Branch, sha, files))
+	if err != nil {
+		return "", errors.NewConfigError("commit_groups", fmt.Sprintf("render template: %v", err))
+	}
+	return rendered, nil
+}
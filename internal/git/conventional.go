@@ -0,0 +1,86 @@
+package git
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// conventionalHeaderPattern matches a Conventional Commits header:
+// "<type>[(<scope>)][!]: <subject>".
+var conventionalHeaderPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.+)$`)
+
+// breakingFooterPattern matches a BREAKING CHANGE footer anywhere in the body.
+var breakingFooterPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// conventionalCommit is one commit of the release range, with its header
+// parsed as a Conventional Commit. Type is empty for non-conventional headers.
+type conventionalCommit struct {
+	SHA      string `json:"sha"`
+	Author   string `json:"author"`
+	Type     string `json:"type"`
+	Scope    string `json:"scope,omitempty"`
+	Subject  string `json:"subject"`
+	Breaking bool   `json:"breaking"`
+}
+
+// parseConventionalCommit builds a conventionalCommit from the raw log fields.
+// A header that does not follow the convention keeps its full text as Subject.
+func parseConventionalCommit(sha, author, header, body string) conventionalCommit {
+	c := conventionalCommit{SHA: sha, Author: author, Subject: header}
+
+	if m := conventionalHeaderPattern.FindStringSubmatch(header); m != nil {
+		c.Type = strings.ToLower(m[1])
+		c.Scope = m[2]
+		c.Breaking = m[3] == "!"
+		c.Subject = m[4]
+	}
+	if breakingFooterPattern.MatchString(body) {
+		c.Breaking = true
+	}
+
+	return c
+}
+
+// parseCommitLog splits the output of gitcmd.LogArgs into commits.
+func parseCommitLog(out string) []conventionalCommit {
+	var commits []conventionalCommit
+	for _, record := range strings.Split(out, gitcmd.LogRecordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, gitcmd.LogFieldSep, 4)
+		if len(fields) < 3 {
+			continue
+		}
+		body := ""
+		if len(fields) == 4 {
+			body = fields[3]
+		}
+		commits = append(commits, parseConventionalCommit(fields[0], fields[1], fields[2], body))
+	}
+	return commits
+}
+
+// inferBump picks the bump implied by commits: major for any breaking change,
+// minor for any feat, and patch otherwise. It returns "" when there are no
+// commits, meaning there is nothing to release.
+func inferBump(commits []conventionalCommit) string {
+	if len(commits) == 0 {
+		return ""
+	}
+
+	bump := config.TagBumpPatch
+	for _, c := range commits {
+		if c.Breaking {
+			return config.TagBumpMajor
+		}
+		if c.Type == "feat" {
+			bump = config.TagBumpMinor
+		}
+	}
+	return bump
+}
@@ -5,8 +5,9 @@ import (
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 )
 
-// StageFiles adds the specified files to the Git staging area.
+// StageFiles adds the files matched by patterns, minus those matched by
+// excludes, to the Git staging area.
 // It delegates to the shared package to avoid duplication with pr/branch.go.
-func StageFiles(r gitcmd.Runner, filePattern string) error {
-	return shared.StageFiles(r, filePattern)
+func StageFiles(r gitcmd.Runner, patterns, excludes []string) error {
+	return shared.StageFiles(r, patterns, excludes)
 }
@@ -0,0 +1,185 @@
+// Package guard checks the staged changes before they are committed, so that
+// a misconfigured file_pattern cannot publish large binaries or credentials:
+// max_file_size and max_total_size limit the size of the staged files,
+// forbid_binary rejects binary files and scan_secrets looks for credentials.
+//
+// Every breach is collected before failing, so that the error lists them all.
+package guard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// Rules checked on the staged files, named after their inputs.
+const (
+	RuleFileSize  = "max_file_size"
+	RuleTotalSize = "max_total_size"
+	RuleBinary    = "forbid_binary"
+)
+
+// maxScanSize bounds the files scan_secrets reads. Larger files are reported
+// as not scanned rather than read into memory.
+const maxScanSize = 5 << 20
+
+// Checker enforces the guardrails configured in GitConfig on the staged
+// changes.
+type Checker struct {
+	config *config.GitConfig
+	runner gitcmd.Runner
+
+	// unscanned lists the files too large for scan_secrets to read.
+	unscanned []string
+}
+
+// NewChecker creates a new Checker instance.
+func NewChecker(cfg *config.GitConfig) *Checker {
+	return NewCheckerWithRunner(cfg, gitcmd.NewExecRunner())
+}
+
+// NewCheckerWithRunner creates a Checker with an explicit command Runner,
+// allowing tests to supply the staged changes without a repository.
+func NewCheckerWithRunner(cfg *config.GitConfig, r gitcmd.Runner) *Checker {
+	return &Checker{config: cfg, runner: r}
+}
+
+// Check inspects the files added or modified in the index and returns an
+// *errors.ViolationError, marked permanent, listing every breach. It does
+// nothing when no guardrail is configured.
+func (c *Checker) Check() error {
+	if !c.config.HasGuardrails() {
+		return nil
+	}
+
+	fmt.Printf("  - Checking staged changes... ")
+	violations, err := c.violations()
+	switch {
+	case err != nil:
+		fmt.Println("FAILED")
+	case len(violations) > 0:
+		fmt.Printf("%d violation(s)\n", len(violations))
+	default:
+		fmt.Println("Done")
+	}
+	for _, path := range c.unscanned {
+		fmt.Printf("  - [WARN] %s is larger than %s, not scanned for secrets\n", path, formatSize(maxScanSize))
+	}
+
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return errors.Permanent(errors.NewViolationError(violations))
+	}
+	return nil
+}
+
+// violations collects the breaches of the staged changes.
+func (c *Checker) violations() ([]errors.Violation, error) {
+	changes, err := shared.StagedChanges(c.runner)
+	if err != nil {
+		return nil, err
+	}
+	binary, err := c.binaryPaths()
+	if err != nil {
+		return nil, err
+	}
+	var scanner *secretScanner
+	if c.config.ScanSecrets {
+		if scanner, err = newSecretScanner(c.config.SecretsAllowlist); err != nil {
+			return nil, err
+		}
+	}
+
+	var violations []errors.Violation
+	var total int64
+	fileLimit, totalLimit := c.config.FileSizeLimit(), c.config.TotalSizeLimit()
+	for _, ch := range changes {
+		if ch.Mode == shared.ModeDeleted || ch.Mode == shared.ModeGitlink {
+			continue
+		}
+
+		size, err := c.objectSize(ch)
+		if err != nil {
+			return nil, err
+		}
+		total += size
+		if fileLimit > 0 && size > fileLimit {
+			violations = append(violations, errors.Violation{Path: ch.Path, Rule: RuleFileSize,
+				Detail: formatSize(size) + " > " + formatSize(fileLimit)})
+		}
+		if c.config.ForbidBinary && binary[ch.Path] {
+			violations = append(violations, errors.Violation{Path: ch.Path, Rule: RuleBinary})
+		}
+
+		if scanner == nil || binary[ch.Path] {
+			continue
+		}
+		if size > maxScanSize {
+			c.unscanned = append(c.unscanned, ch.Path)
+			continue
+		}
+		content, err := c.runner.Output(gitcmd.CmdGit, gitcmd.CatFileBlobArgs(ch.SHA)...)
+		if err != nil {
+			return nil, errors.NewWithPath("read staged content", ch.Path, err)
+		}
+		violations = append(violations, scanner.scan(ch.Path, string(content))...)
+	}
+
+	if totalLimit > 0 && total > totalLimit {
+		violations = append(violations, errors.Violation{Rule: RuleTotalSize,
+			Detail: formatSize(total) + " > " + formatSize(totalLimit)})
+	}
+	return violations, nil
+}
+
+// binaryPaths returns the staged paths git considers binary: those numstat
+// reports with "-" line counts.
+func (c *Checker) binaryPaths() (map[string]bool, error) {
+	out, err := c.runner.Output(gitcmd.CmdGit, gitcmd.DiffCachedNumstatArgs()...)
+	if err != nil {
+		return nil, errors.New("count staged changes", err)
+	}
+
+	binary := make(map[string]bool)
+	for _, record := range strings.Split(string(out), "\x00") {
+		// "<added>\t<deleted>\t<path>"
+		fields := strings.SplitN(record, "\t", 3)
+		if len(fields) == 3 && fields[0] == "-" && fields[1] == "-" {
+			binary[fields[2]] = true
+		}
+	}
+	return binary, nil
+}
+
+// objectSize returns the size in bytes of the staged content of ch.
+func (c *Checker) objectSize(ch shared.StagedChange) (int64, error) {
+	out, err := c.runner.Output(gitcmd.CmdGit, gitcmd.CatFileSizeArgs(ch.SHA)...)
+	if err != nil {
+		return 0, errors.NewWithPath("read staged size", ch.Path, err)
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return 0, errors.NewWithPath("read staged size", ch.Path, err)
+	}
+	return size, nil
+}
+
+// formatSize renders n bytes for a violation, in the largest fitting unit.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,138 @@
+package guard
+
+import (
+	stderrors "errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+func key(args []string) string {
+	return gitcmd.Call{Name: gitcmd.CmdGit, Args: args}.Key()
+}
+
+// stagedFile describes a staged change served by the fake index.
+type stagedFile struct {
+	path    string
+	mode    string
+	size    int
+	binary  bool
+	content string
+}
+
+// fakeIndex answers the commands Check runs for files.
+func fakeIndex(files ...stagedFile) *gitcmd.FakeRunner {
+	var raw, numstat strings.Builder
+	f := gitcmd.NewFakeRunner()
+	for i, file := range files {
+		sha := strings.Repeat(string(rune('a'+i)), 40)
+		mode := file.mode
+		if mode == "" {
+			mode = "100644"
+		}
+		raw.WriteString(":100644 " + mode + " 0000000000000000000000000000000000000000 " + sha + " M\x00" + file.path + "\x00")
+		if file.binary {
+			numstat.WriteString("-\t-\t" + file.path + "\x00")
+		} else {
+			numstat.WriteString("1\t0\t" + file.path + "\x00")
+		}
+		f.Stub(key(gitcmd.CatFileSizeArgs(sha)), gitcmd.FakeResult{Stdout: strconv.Itoa(file.size) + "\n"})
+		f.Stub(key(gitcmd.CatFileBlobArgs(sha)), gitcmd.FakeResult{Stdout: file.content})
+	}
+	f.Stub(key(gitcmd.DiffCachedRawArgs()), gitcmd.FakeResult{Stdout: raw.String()})
+	f.Stub(key(gitcmd.DiffCachedNumstatArgs()), gitcmd.FakeResult{Stdout: numstat.String()})
+	return f
+}
+
+func violationsOf(t *testing.T, err error) []errors.Violation {
+	t.Helper()
+	var ve *errors.ViolationError
+	if !stderrors.As(err, &ve) {
+		t.Fatalf("Check() error = %v, want a *ViolationError", err)
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("Check() error = %v, want a permanent error", err)
+	}
+	return ve.Violations
+}
+
+func TestChecker_NoGuardrails(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+	if err := NewCheckerWithRunner(&config.GitConfig{}, f).Check(); err != nil {
+		t.Fatalf("Check() error = %v, want nil", err)
+	}
+	if len(f.Keys()) != 0 {
+		t.Errorf("Check() ran %v, want nothing", f.Keys())
+	}
+}
+
+func TestChecker_SizeAndBinary(t *testing.T) {
+	cfg := &config.GitConfig{MaxFileSize: "1K", MaxTotalSize: "2K", ForbidBinary: true}
+	f := fakeIndex(
+		stagedFile{path: "small.txt", size: 100},
+		stagedFile{path: "big.txt", size: 1500},
+		stagedFile{path: "logo.png", size: 900, binary: true},
+		stagedFile{path: "removed.bin", mode: "000000", binary: true},
+		stagedFile{path: "vendor/lib", mode: "160000"},
+	)
+
+	got := violationsOf(t, NewCheckerWithRunner(cfg, f).Check())
+	want := []errors.Violation{
+		{Path: "big.txt", Rule: RuleFileSize, Detail: "1.5 KiB > 1.0 KiB"},
+		{Path: "logo.png", Rule: RuleBinary},
+		{Rule: RuleTotalSize, Detail: "2.4 KiB > 2.0 KiB"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("violations[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestChecker_Passes(t *testing.T) {
+	cfg := &config.GitConfig{MaxFileSize: "1M", ForbidBinary: true, ScanSecrets: true}
+	f := fakeIndex(stagedFile{path: "README.md", size: 12, content: "hello world\n"})
+
+	if err := NewCheckerWithRunner(cfg, f).Check(); err != nil {
+		t.Fatalf("Check() error = %v, want nil", err)
+	}
+}
+
+// Binary files are not scanned for secrets, nor are files above maxScanSize.
+func TestChecker_ScanSecretsSkipsUnreadable(t *testing.T) {
+	cfg := &config.GitConfig{ScanSecrets: true}
+	f := fakeIndex(
+		stagedFile{path: "blob.bin", size: 10, binary: true},
+		stagedFile{path: "huge.txt", size: maxScanSize + 1},
+	)
+
+	if err := NewCheckerWithRunner(cfg, f).Check(); err != nil {
+		t.Fatalf("Check() error = %v, want nil", err)
+	}
+	for _, sha := range []string{strings.Repeat("a", 40), strings.Repeat("b", 40)} {
+		if f.Ran(key(gitcmd.CatFileBlobArgs(sha))) {
+			t.Errorf("Keys() = %v, want %s not read", f.Keys(), sha)
+		}
+	}
+}
+
+func TestChecker_ListFailure(t *testing.T) {
+	cfg := &config.GitConfig{ForbidBinary: true}
+	f := gitcmd.NewFakeRunner().Stub(key(gitcmd.DiffCachedRawArgs()), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
+
+	err := NewCheckerWithRunner(cfg, f).Check()
+	if err == nil {
+		t.Fatal("Check() error = nil, want the git failure")
+	}
+	var ve *errors.ViolationError
+	if stderrors.As(err, &ve) {
+		t.Errorf("Check() error = %v, want no violations", err)
+	}
+}
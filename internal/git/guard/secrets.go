@@ -0,0 +1,178 @@
+package guard
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	pathpkg "path"
+	"regexp"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+)
+
+// Rules of the secret scanner, as reported in violations and named in the
+// secrets_allowlist file.
+const (
+	RuleAWSKey        = "aws-access-key"
+	RuleGitHubToken   = "github-token"
+	RulePrivateKey    = "private-key"
+	RuleGenericSecret = "generic-secret"
+	RuleEnvFile       = "env-file"
+)
+
+// A value assigned to a secret-like key is taken for a credential, rather than
+// a placeholder such as "changeme-changeme", when it is at least
+// minSecretLength long and its Shannon entropy, in bits per character,
+// reaches minSecretEntropy.
+const (
+	minSecretLength  = 16
+	minSecretEntropy = 3.5
+)
+
+// secretPatterns match credentials with a recognizable format.
+var secretPatterns = []struct {
+	rule string
+	re   *regexp.Regexp
+}{
+	{RuleAWSKey, regexp.MustCompile(`\b(AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+	{RuleGitHubToken, regexp.MustCompile(`\b(ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}\b`)},
+	{RuleGitHubToken, regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{82}\b`)},
+	{RulePrivateKey, regexp.MustCompile(`-----BEGIN ((RSA|DSA|EC|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`)},
+}
+
+// secretAssignment matches a value assigned to a secret-like key, such as
+// `api_key = "..."` or `password: ...`; the value is the last group.
+var secretAssignment = regexp.MustCompile(
+	`(?i)(secret|token|password|passwd|api[_-]?key|access[_-]?key|private[_-]?key)[A-Za-z0-9_-]*["']?\s*[:=]\s*["']?([^\s"'` + "`" + `,;]+)`)
+
+// secretScanner looks for credentials in staged content. Violations only name
+// the rule and line, never the matched value, since the action log is often
+// public.
+type secretScanner struct {
+	allow []allowEntry
+}
+
+// allowEntry is a line of the secrets_allowlist file: a path glob, and
+// optionally the only rule it silences.
+type allowEntry struct {
+	glob string
+	rule string
+}
+
+// newSecretScanner creates a scanner honouring the allowlist file at path,
+// read relative to the repository. An empty path allows nothing.
+func newSecretScanner(path string) (*secretScanner, error) {
+	s := &secretScanner{}
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewWithPath("read secrets allowlist", path, err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		glob, rule, _ := strings.Cut(line, ":")
+		if _, err := pathpkg.Match(glob, ""); err != nil {
+			return nil, errors.NewWithPath("read secrets allowlist", path, fmt.Errorf("invalid pattern %q: %w", glob, err))
+		}
+		s.allow = append(s.allow, allowEntry{glob: strings.TrimSuffix(glob, "/"), rule: strings.TrimSpace(rule)})
+	}
+	return s, nil
+}
+
+// allowed reports whether the allowlist silences rule for path. A glob
+// matches the path itself or any directory containing it.
+func (s *secretScanner) allowed(path, rule string) bool {
+	for _, a := range s.allow {
+		if a.rule != "" && a.rule != rule {
+			continue
+		}
+		for p := path; p != "." && p != "/"; p = pathpkg.Dir(p) {
+			if ok, _ := pathpkg.Match(a.glob, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scan returns a violation for every credential found in the content of the
+// file at path, at most one per rule and line.
+func (s *secretScanner) scan(path, content string) []errors.Violation {
+	var violations []errors.Violation
+	add := func(rule, detail string) {
+		if !s.allowed(path, rule) {
+			violations = append(violations, errors.Violation{Path: path, Rule: rule, Detail: detail})
+		}
+	}
+
+	if isEnvFile(path) {
+		add(RuleEnvFile, "")
+	}
+	for i, line := range strings.Split(content, "\n") {
+		detail := fmt.Sprintf("line %d", i+1)
+		seen := make(map[string]bool)
+		for _, p := range secretPatterns {
+			if !seen[p.rule] && p.re.MatchString(line) {
+				seen[p.rule] = true
+				add(p.rule, detail)
+			}
+		}
+		if !seen[RuleGitHubToken] && !seen[RuleAWSKey] && hasSecretAssignment(line) {
+			add(RuleGenericSecret, detail)
+		}
+	}
+	return violations
+}
+
+// hasSecretAssignment reports whether line assigns a high-entropy value to a
+// secret-like key.
+func hasSecretAssignment(line string) bool {
+	for _, m := range secretAssignment.FindAllStringSubmatch(line, -1) {
+		value := m[len(m)-1]
+		if len(value) >= minSecretLength && entropy(value) >= minSecretEntropy {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvFile reports whether path names a dotenv file, other than the
+// templates committed on purpose.
+func isEnvFile(path string) bool {
+	base := pathpkg.Base(path)
+	if base != ".env" && !strings.HasPrefix(base, ".env.") {
+		return false
+	}
+	switch pathpkg.Ext(base) {
+	case ".example", ".sample", ".template":
+		return false
+	}
+	return true
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
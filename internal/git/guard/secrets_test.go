@@ -0,0 +1,116 @@
+package guard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+)
+
+// Fixtures are assembled at run time so that this file does not itself trip
+// secret scanners.
+var (
+	fakeAWSKey      = "AKIA" + "Z7QW3E5R7T9Y2U4I"
+	fakeGitHubToken = "ghp" + "_" + strings.Repeat("aB3dE6gH9j", 3) + "K2mN5p"
+	fakeKeyHeader   = "-----BEGIN " + "RSA PRIVATE KEY-----"
+)
+
+func TestSecretScanner_Scan(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []string // "rule detail"
+	}{
+		{"clean", "main.go", "package main\n\nfunc main() {}\n", nil},
+		{"aws key", "deploy.sh", "export AWS_ACCESS_KEY_ID=" + fakeAWSKey + "\n", []string{"aws-access-key line 1"}},
+		{"github token", "ci.yml", "env:\n  TOKEN: " + fakeGitHubToken + "\n", []string{"github-token line 2"}},
+		{"private key", "id_rsa", fakeKeyHeader + "\nMIIE\n", []string{"private-key line 1"}},
+		{"generic secret", "settings.py", `API_KEY = "q8Zr2LpX0vN4tY7bWc1M"` + "\n", []string{"generic-secret line 1"}},
+		{"placeholder value", "settings.py", `password = "changeme-changeme"` + "\n", nil},
+		{"short value", "settings.py", "token: abc123\n", nil},
+		{"env file", "config/.env.production", "DEBUG=1\n", []string{"env-file "}},
+		{"env template", ".env.example", "DEBUG=1\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range (&secretScanner{}).scan(tt.path, tt.content) {
+				if v.Path != tt.path {
+					t.Errorf("violation path = %q, want %q", v.Path, tt.path)
+				}
+				got = append(got, v.Rule+" "+v.Detail)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("scan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretScanner_Allowlist(t *testing.T) {
+	t.Chdir(t.TempDir())
+	allowlist := "# test fixtures\n" +
+		"testdata/\n" +
+		"docs/*.md:aws-access-key  # documented example key\n"
+	if err := os.WriteFile(".secrets-allowlist", []byte(allowlist), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := newSecretScanner(".secrets-allowlist")
+	if err != nil {
+		t.Fatalf("newSecretScanner() error = %v", err)
+	}
+	content := fakeAWSKey + "\n" + fakeGitHubToken + "\n"
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"testdata/keys/fixture.txt", 0},
+		{"docs/setup.md", 1},     // only the AWS key is allowed
+		{"docs/sub/setup.md", 2}, // the glob does not match nested files
+		{"src/testdata.txt", 2},  // nor a file sharing the directory's prefix
+	}
+	for _, tt := range tests {
+		if got := s.scan(tt.path, content); len(got) != tt.want {
+			t.Errorf("scan(%q) = %v, want %d violation(s)", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNewSecretScanner_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := newSecretScanner(filepath.Join(dir, "missing")); err == nil {
+		t.Error("newSecretScanner(missing) error = nil, want an error")
+	}
+
+	bad := filepath.Join(dir, "bad")
+	if err := os.WriteFile(bad, []byte("[unterminated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSecretScanner(bad); err == nil {
+		t.Error("newSecretScanner(bad) error = nil, want an invalid pattern")
+	}
+}
+
+// A secret found in the staged content fails the check without the value
+// appearing in the error.
+func TestChecker_ScanSecrets(t *testing.T) {
+	cfg := &config.GitConfig{ScanSecrets: true}
+	content := "aws_key: " + fakeAWSKey + "\n"
+	f := fakeIndex(stagedFile{path: "deploy.yml", size: len(content), content: content})
+
+	err := NewCheckerWithRunner(cfg, f).Check()
+	got := violationsOf(t, err)
+	want := errors.Violation{Path: "deploy.yml", Rule: RuleAWSKey, Detail: "line 1"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("violations = %v, want [%v]", got, want)
+	}
+	if strings.Contains(err.Error(), fakeAWSKey) {
+		t.Errorf("Check() error = %v, want the key redacted", err)
+	}
+}
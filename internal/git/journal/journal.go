@@ -0,0 +1,132 @@
+// Package journal records the remote mutations made during a run (branches
+// created, commits and tags pushed, pull requests opened, labels added) so
+// that, with rollback_on_failure, they can be undone in reverse order when a
+// later step fails.
+//
+// The journal travels in the context: steps record into the journal of the
+// context they are given, and recording is a no-op when there is none.
+package journal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// UndoFunc reverts one recorded mutation.
+type UndoFunc func(ctx context.Context) error
+
+// Entry status values, as printed by Print.
+const (
+	StatusKept        = "kept"
+	StatusReverted    = "reverted"
+	StatusFailed      = "revert FAILED"
+	StatusIrrevocable = "cannot be reverted"
+)
+
+// Entry is one recorded mutation.
+type Entry struct {
+	Action string // what was done, e.g. "pushed tag v1.2.3"
+	Status string // one of the Status values
+	Err    error  // the undo failure when Status is StatusFailed
+
+	undo UndoFunc
+}
+
+// Journal is the ordered list of mutations of a run. The zero value is not
+// used; a nil *Journal ignores every call, so steps need not check whether
+// rollback is enabled.
+type Journal struct {
+	entries []*Entry
+}
+
+// New returns an empty Journal.
+func New() *Journal {
+	return &Journal{}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying j.
+func NewContext(ctx context.Context, j *Journal) context.Context {
+	return context.WithValue(ctx, contextKey{}, j)
+}
+
+// FromContext returns the Journal carried by ctx, or nil.
+func FromContext(ctx context.Context) *Journal {
+	j, _ := ctx.Value(contextKey{}).(*Journal)
+	return j
+}
+
+// Record adds a mutation that has been applied to the remote. undo reverts
+// it; nil marks a mutation that cannot be reverted.
+func (j *Journal) Record(action string, undo UndoFunc) {
+	if j == nil {
+		return
+	}
+	j.entries = append(j.entries, &Entry{Action: action, Status: StatusKept, undo: undo})
+}
+
+// Entries returns the recorded mutations in the order they were made.
+func (j *Journal) Entries() []*Entry {
+	if j == nil {
+		return nil
+	}
+	return j.entries
+}
+
+// Rollback undoes the recorded mutations, newest first. A failing undo does
+// not stop the others; the error lists every mutation left in place.
+func (j *Journal) Rollback(ctx context.Context) error {
+	if j == nil {
+		return nil
+	}
+
+	var failed []string
+	for i := len(j.entries) - 1; i >= 0; i-- {
+		e := j.entries[i]
+		if e.Status != StatusKept {
+			continue
+		}
+		if e.undo == nil {
+			e.Status = StatusIrrevocable
+			failed = append(failed, e.Action)
+			continue
+		}
+
+		fmt.Printf("  - Reverting: %s... ", e.Action)
+		if err := e.undo(ctx); err != nil {
+			fmt.Println("FAILED")
+			e.Status, e.Err = StatusFailed, err
+			failed = append(failed, e.Action)
+			continue
+		}
+		fmt.Println("Done")
+		e.Status = StatusReverted
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("could not revert: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Print lists the recorded mutations and what became of them.
+func (j *Journal) Print() {
+	if j == nil {
+		return
+	}
+
+	fmt.Println("\nRemote Changes:")
+	if len(j.entries) == 0 {
+		fmt.Println("  - none")
+		return
+	}
+	for _, e := range j.entries {
+		if e.Err != nil {
+			fmt.Printf("  - %s: %s (%v)\n", e.Action, e.Status, e.Err)
+			continue
+		}
+		fmt.Printf("  - %s: %s\n", e.Action, e.Status)
+	}
+}
@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRollback_UndoesInReverseOrder(t *testing.T) {
+	j := New()
+	var undone []string
+	for _, action := range []string{"first", "second", "third"} {
+		j.Record(action, func(context.Context) error {
+			undone = append(undone, action)
+			return nil
+		})
+	}
+
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	if want := []string{"third", "second", "first"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("undone = %v, want %v", undone, want)
+	}
+	for _, e := range j.Entries() {
+		if e.Status != StatusReverted {
+			t.Errorf("%s: Status = %q, want %q", e.Action, e.Status, StatusReverted)
+		}
+	}
+}
+
+// A failing undo is reported but does not stop the older entries from being
+// reverted.
+func TestRollback_ContinuesPastFailures(t *testing.T) {
+	j := New()
+	firstUndone := false
+	j.Record("first", func(context.Context) error {
+		firstUndone = true
+		return nil
+	})
+	j.Record("second", func(context.Context) error { return errors.New("boom") })
+	j.Record("third", nil)
+
+	err := j.Rollback(context.Background())
+	if err == nil {
+		t.Fatal("Rollback() error = nil, want the failed entries reported")
+	}
+	if !strings.Contains(err.Error(), "second") || !strings.Contains(err.Error(), "third") {
+		t.Errorf("error = %q, want it to name second and third", err.Error())
+	}
+	if !firstUndone {
+		t.Error("first was not reverted after a later entry failed")
+	}
+
+	got := []string{j.Entries()[0].Status, j.Entries()[1].Status, j.Entries()[2].Status}
+	if want := []string{StatusReverted, StatusFailed, StatusIrrevocable}; !reflect.DeepEqual(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+}
+
+func TestRollback_RunsOnce(t *testing.T) {
+	j := New()
+	calls := 0
+	j.Record("push", func(context.Context) error {
+		calls++
+		return nil
+	})
+
+	_ = j.Rollback(context.Background())
+	_ = j.Rollback(context.Background())
+	if calls != 1 {
+		t.Errorf("undo ran %d times, want 1", calls)
+	}
+}
+
+func TestNilJournal_IsNoop(t *testing.T) {
+	var j *Journal
+	j.Record("push", nil)
+	j.Print()
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Errorf("Rollback() error = %v, want nil", err)
+	}
+	if FromContext(context.Background()) != nil {
+		t.Error("FromContext() of a bare context is not nil")
+	}
+}
+
+func TestContext_CarriesJournal(t *testing.T) {
+	j := New()
+	ctx := NewContext(context.Background(), j)
+
+	FromContext(ctx).Record("push", nil)
+	if len(j.Entries()) != 1 {
+		t.Errorf("Entries() = %v, want the entry recorded through the context", j.Entries())
+	}
+}
@@ -0,0 +1,230 @@
+// Package lock serializes runs of the action against the same branch with a
+// lock held on the remote (remote_lock), so that matrix jobs do not race each
+// other's commits, tags and pull requests.
+//
+// The lock is the ref refs/locks/<branch>. It is taken by pushing a commit to
+// it with a lease that requires the ref not to exist, which the remote accepts
+// for only one of several concurrent runs, and released by deleting it. The
+// commit message records who holds the lock and when it expires, so that a
+// lock left behind by a killed run is taken over once remote_lock_ttl passes.
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+const (
+	// headerOwner and headerExpires prefix the metadata lines of the lock
+	// commit message.
+	headerOwner   = "Owner: "
+	headerExpires = "Expires: "
+)
+
+var (
+	// pollDelay is the first wait for a held lock; it doubles up to
+	// maxPollDelay. Variables so that tests need not wait.
+	pollDelay    = time.Second
+	maxPollDelay = 10 * time.Second
+)
+
+// Locker acquires and releases the remote lock of the configured branch. A
+// nil *Locker ignores every call, so callers need not check whether
+// remote_lock is enabled.
+type Locker struct {
+	config *config.GitConfig
+	runner gitcmd.Runner
+	ref    string
+
+	// sha is the lock commit pushed by Acquire; empty while not held.
+	sha string
+	// dir is the repository directory Acquire ran in, for Release.
+	dir string
+}
+
+// holder describes the lock commit currently on the remote.
+type holder struct {
+	sha     string
+	owner   string
+	expires time.Time
+}
+
+// NewLocker creates a new Locker instance.
+func NewLocker(cfg *config.GitConfig) *Locker {
+	return NewLockerWithRunner(cfg, gitcmd.NewExecRunner())
+}
+
+// NewLockerWithRunner creates a Locker with an explicit command Runner,
+// allowing tests to assert the emitted commands without a remote.
+func NewLockerWithRunner(cfg *config.GitConfig, r gitcmd.Runner) *Locker {
+	return &Locker{config: cfg, runner: r, ref: gitcmd.RefLocks + cfg.Branch}
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Locker) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the Locker carried by ctx, or nil.
+func FromContext(ctx context.Context) *Locker {
+	l, _ := ctx.Value(contextKey{}).(*Locker)
+	return l
+}
+
+// Acquire takes the lock, waiting with exponential backoff while another run
+// holds it and taking over a lock whose expiry has passed. It must run in the
+// repository with credentials configured, and gives up when ctx is done; the
+// caller bounds ctx with the timeout input. Acquiring a held lock again, as a
+// retried workflow does, is a no-op.
+func (l *Locker) Acquire(ctx context.Context) error {
+	if l == nil || l.sha != "" {
+		return nil
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return errors.New("get working directory", err)
+	}
+	sha, err := l.createLockCommit()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nAcquiring Lock %s:\n", l.ref)
+	delay := pollDelay
+	expected := "" // the lease: the ref must not exist yet
+	for {
+		fmt.Printf("  - Pushing lock... ")
+		err := l.runner.Run(gitcmd.CmdGit, gitcmd.PushRefToArgs(l.ref, sha, gitcmd.ForceWithLeaseOpt(l.ref, expected))...)
+		if err == nil {
+			fmt.Println("Done")
+			l.sha, l.dir = sha, dir
+			return nil
+		}
+		// git push exits 1 for a rejected ref; anything else is a
+		// connection or permission problem the lock cannot wait out.
+		if code, ok := gitcmd.ExitCodeOf(err); !ok || code != 1 {
+			fmt.Println("FAILED")
+			return errors.NewWithPath("push lock", l.ref, err)
+		}
+		fmt.Println("Rejected")
+
+		h, err := l.readHolder()
+		if err != nil {
+			return err
+		}
+		if h == nil {
+			// Released between the push and the read; try again at once.
+			expected = ""
+			continue
+		}
+		if !h.expires.IsZero() && time.Now().After(h.expires) {
+			fmt.Printf("  - [WARN] Lock held by %s expired at %s, taking it over\n", h.owner, h.expires.Format(time.RFC3339))
+			expected = h.sha
+			continue
+		}
+
+		fmt.Printf("  - Lock held by %s, waiting %s\n", h.owner, delay)
+		select {
+		case <-ctx.Done():
+			return errors.Permanent(errors.NewWithPath("acquire lock", l.ref,
+				fmt.Errorf("still held by %s: %w", h.owner, ctx.Err())))
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxPollDelay)
+		expected = ""
+	}
+}
+
+// Release deletes the lock if this run holds it, unless another run has
+// taken it over meanwhile. A failure is only reported: the lock then expires
+// after remote_lock_ttl.
+func (l *Locker) Release() {
+	if l == nil || l.sha == "" {
+		return
+	}
+
+	fmt.Printf("\nReleasing Lock %s:\n", l.ref)
+	// The workflow may have left the repository directory.
+	if err := os.Chdir(l.dir); err != nil {
+		fmt.Printf("  - [WARN] Failed to release lock: %v\n", err)
+		return
+	}
+	err := shared.RunStep(l.runner, "Deleting lock", gitcmd.CmdGit,
+		gitcmd.PushRefToArgs(l.ref, "", gitcmd.ForceWithLeaseOpt(l.ref, l.sha))...)
+	if err != nil {
+		fmt.Printf("  - [WARN] Failed to release lock, it expires after %ds: %v\n", l.config.RemoteLockTTL, err)
+		return
+	}
+	l.sha = ""
+}
+
+// createLockCommit creates, without touching any branch, the commit pushed as
+// the lock. It reuses the tree of HEAD, which the remote already has, so the
+// push carries next to nothing.
+func (l *Locker) createLockCommit() (string, error) {
+	tree, err := l.runner.Output(gitcmd.CmdGit, gitcmd.RevParseArgs("HEAD^{tree}")...)
+	if err != nil {
+		return "", errors.New("resolve HEAD tree", err)
+	}
+
+	expires := time.Now().UTC().Add(time.Duration(l.config.RemoteLockTTL) * time.Second)
+	message := fmt.Sprintf("lock %s\n\n%s%s\n%s%s", l.config.Branch,
+		headerOwner, owner(), headerExpires, expires.Format(time.RFC3339))
+
+	out, err := l.runner.Output(gitcmd.CmdGit, gitcmd.CommitTreeArgs(strings.TrimSpace(string(tree)), message)...)
+	if err != nil {
+		return "", errors.New("create lock commit", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// readHolder fetches the lock commit on the remote and parses its metadata.
+// It returns nil when the lock is not held.
+func (l *Locker) readHolder() (*holder, error) {
+	out, err := l.runner.Output(gitcmd.CmdGit, gitcmd.LsRemoteRefArgs(gitcmd.RefOrigin, l.ref)...)
+	if err != nil {
+		return nil, errors.NewWithPath("read lock", l.ref, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	h := &holder{sha: fields[0], owner: "unknown owner"}
+	if _, err := l.runner.Output(gitcmd.CmdGit, gitcmd.FetchArgs(gitcmd.RefOrigin, l.ref)...); err != nil {
+		return nil, errors.NewWithPath("fetch lock", l.ref, err)
+	}
+	commit, err := l.runner.Output(gitcmd.CmdGit, gitcmd.CatFileCommitArgs(h.sha)...)
+	if err != nil {
+		return nil, errors.NewWithPath("read lock", l.ref, err)
+	}
+
+	for _, line := range strings.Split(string(commit), "\n") {
+		if v, ok := strings.CutPrefix(line, headerOwner); ok {
+			h.owner = v
+		}
+		if v, ok := strings.CutPrefix(line, headerExpires); ok {
+			// A lock without a valid expiry is never taken over.
+			h.expires, _ = time.Parse(time.RFC3339, v)
+		}
+	}
+	return h, nil
+}
+
+// owner identifies this run in the lock metadata.
+func owner() string {
+	return fmt.Sprintf("%s run %s/%s job %s",
+		os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"),
+		os.Getenv("GITHUB_RUN_ATTEMPT"), os.Getenv("GITHUB_JOB"))
+}
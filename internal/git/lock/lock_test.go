@@ -0,0 +1,195 @@
+package lock
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+const (
+	lockRef   = "refs/locks/main"
+	lockSHA   = "10c4"
+	holderSHA = "0ther"
+)
+
+func key(args []string) string {
+	return gitcmd.Call{Name: gitcmd.CmdGit, Args: args}.Key()
+}
+
+func lockConfig() *config.GitConfig {
+	return &config.GitConfig{Branch: "main", RemoteLock: true, RemoteLockTTL: 60}
+}
+
+func pushKey(expected string) string {
+	return key(gitcmd.PushRefToArgs(lockRef, lockSHA, gitcmd.ForceWithLeaseOpt(lockRef, expected)))
+}
+
+// fakeRemote answers the lock commands: pushes fail with exit 1 while
+// rejected returns true, and the holder's lock commit expires at expires.
+func fakeRemote(rejected func(push string) bool, expires time.Time) *gitcmd.FakeRunner {
+	f := gitcmd.NewFakeRunner()
+	f.Handler = func(name string, args []string) (string, error) {
+		k := key(args)
+		switch {
+		case args[0] == gitcmd.SubCmdCommitTree:
+			return lockSHA + "\n", nil
+		case args[0] == gitcmd.SubCmdPush && rejected(k):
+			return "", gitcmd.Fail(1)
+		case k == key(gitcmd.LsRemoteRefArgs(gitcmd.RefOrigin, lockRef)):
+			return holderSHA + "\t" + lockRef + "\n", nil
+		case k == key(gitcmd.CatFileCommitArgs(holderSHA)):
+			return "tree abc\n\nlock main\n\nOwner: owner/repo run 7/1 job build\nExpires: " +
+				expires.Format(time.RFC3339) + "\n", nil
+		}
+		return "", nil
+	}
+	return f
+}
+
+func TestLocker_AcquireAndRelease(t *testing.T) {
+	t.Chdir(t.TempDir())
+	f := fakeRemote(func(string) bool { return false }, time.Time{})
+	l := NewLockerWithRunner(lockConfig(), f)
+
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v, want nil", err)
+	}
+	if !f.Ran(pushKey("")) {
+		t.Errorf("Keys() = %v, want a push leased on a missing ref", f.Keys())
+	}
+
+	// A retried workflow acquires again; the lock is already held.
+	f.Reset()
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("second Acquire() error = %v, want nil", err)
+	}
+	if len(f.Keys()) != 0 {
+		t.Errorf("second Acquire() ran %v, want nothing", f.Keys())
+	}
+
+	l.Release()
+	want := key(gitcmd.PushRefToArgs(lockRef, "", gitcmd.ForceWithLeaseOpt(lockRef, lockSHA)))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want %q", f.Keys(), want)
+	}
+}
+
+func TestLocker_LockCommitMetadata(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	t.Setenv("GITHUB_RUN_ATTEMPT", "2")
+	t.Setenv("GITHUB_JOB", "release")
+	f := fakeRemote(func(string) bool { return false }, time.Time{})
+
+	if err := NewLockerWithRunner(lockConfig(), f).Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v, want nil", err)
+	}
+
+	var message string
+	for _, c := range f.Calls() {
+		if c.Args[0] == gitcmd.SubCmdCommitTree {
+			message = c.Args[len(c.Args)-1]
+		}
+	}
+	if !strings.Contains(message, "Owner: owner/repo run 42/2 job release\n") {
+		t.Errorf("lock message = %q, want the owner line", message)
+	}
+	_, expiry, ok := strings.Cut(message, "Expires: ")
+	if !ok {
+		t.Fatalf("lock message = %q, want an Expires line", message)
+	}
+	expires, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		t.Fatalf("Expires = %q: %v", expiry, err)
+	}
+	if d := time.Until(expires); d < 50*time.Second || d > 70*time.Second {
+		t.Errorf("Expires in %s, want about remote_lock_ttl (60s)", d)
+	}
+}
+
+// A lock whose holder expired is taken over with a lease on the holder's
+// commit, so that two runs cannot both take it over.
+func TestLocker_TakesOverExpiredLock(t *testing.T) {
+	t.Chdir(t.TempDir())
+	f := fakeRemote(func(push string) bool { return push == pushKey("") }, time.Now().Add(-time.Minute))
+	l := NewLockerWithRunner(lockConfig(), f)
+
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v, want nil", err)
+	}
+	if !f.Ran(pushKey(holderSHA)) {
+		t.Errorf("Keys() = %v, want a push leased on %s", f.Keys(), holderSHA)
+	}
+}
+
+func TestLocker_WaitsForHolder(t *testing.T) {
+	pollDelay = time.Millisecond
+	t.Cleanup(func() { pollDelay = time.Second })
+	t.Chdir(t.TempDir())
+
+	attempts := 0
+	f := fakeRemote(func(string) bool {
+		attempts++
+		return attempts < 3
+	}, time.Now().Add(time.Hour))
+
+	if err := NewLockerWithRunner(lockConfig(), f).Acquire(context.Background()); err != nil {
+		t.Fatalf("Acquire() error = %v, want nil", err)
+	}
+	if attempts != 3 {
+		t.Errorf("push attempts = %d, want 3", attempts)
+	}
+}
+
+// Waiting is bounded by the context; the timeout is not retried.
+func TestLocker_TimesOut(t *testing.T) {
+	pollDelay = time.Millisecond
+	t.Cleanup(func() { pollDelay = time.Second })
+	t.Chdir(t.TempDir())
+	f := fakeRemote(func(string) bool { return true }, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := NewLockerWithRunner(lockConfig(), f).Acquire(ctx)
+	if err == nil {
+		t.Fatal("Acquire() error = nil, want a timeout")
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("Acquire() error = %v, want a permanent error", err)
+	}
+	if !strings.Contains(err.Error(), "owner/repo run 7/1 job build") {
+		t.Errorf("Acquire() error = %v, want the holder", err)
+	}
+}
+
+// A push failing for another reason than a rejected ref is not waited out.
+func TestLocker_PushFailure(t *testing.T) {
+	t.Chdir(t.TempDir())
+	f := gitcmd.NewFakeRunner().Stub(pushKey(""), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
+	f.Default = gitcmd.FakeResult{Stdout: lockSHA + "\n"}
+
+	if err := NewLockerWithRunner(lockConfig(), f).Acquire(context.Background()); err == nil {
+		t.Fatal("Acquire() error = nil, want the push failure")
+	}
+	if f.Ran(key(gitcmd.LsRemoteRefArgs(gitcmd.RefOrigin, lockRef))) {
+		t.Errorf("Keys() = %v, want no lock read", f.Keys())
+	}
+}
+
+func TestLocker_Nil(t *testing.T) {
+	var l *Locker
+	if err := l.Acquire(context.Background()); err != nil {
+		t.Errorf("Acquire() error = %v, want nil", err)
+	}
+	l.Release()
+
+	if got := FromContext(context.Background()); got != nil {
+		t.Errorf("FromContext() = %v, want nil", got)
+	}
+}
@@ -2,6 +2,7 @@ package git
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 
@@ -24,40 +25,62 @@ func CreatePullRequest(ctx context.Context, r gitcmd.Runner, config *config.GitC
 	if err != nil {
 		return err
 	}
+	if config.AutoBranch {
+		setStagedFiles(result, branchMgr.StagedFiles())
+		if headSHA, err := shared.CurrentCommitSHA(r); err == nil {
+			recordBranchPush(ctx, r, sourceBranch, "", headSHA)
+		}
+	}
+
+	// Steps 2-4: Check the branch, create the PR and process the response
+	pull, err := openPullRequest(ctx, r, config, sourceBranch)
+	if pull.URL != "" {
+		// Capture commit SHA (works for both auto-branch and manual branch flows)
+		if commitSHA, err := shared.CurrentCommitSHA(r); err == nil {
+			result.Set(output.KeyCommitSHA, commitSHA)
+		}
+
+		// Capture PR outputs, created or found
+		result.Set(output.KeyPRURL, pull.URL)
+		result.Set(output.KeyPRNumber, strconv.Itoa(pull.Number))
+		if pull.Action != "" {
+			result.Set(output.KeyPRAction, pull.Action)
+		}
+		if pull.Checks != nil {
+			data, _ := json.Marshal(pull.Checks) // plain strings always encode
+			result.Set(output.KeyPRChecks, string(data))
+		}
+	}
+	if err != nil {
+		return err
+	}
 
+	fmt.Println("\nGit Commit Action Completed Successfully!\n" +
+		"=========================================")
+
+	return nil
+}
+
+// openPullRequest opens the pull request of sourceBranch, which is checked
+// out, or finds the open one, and applies labels, reviewers and the other
+// settings to it. The pull request is returned even when applying them
+// failed.
+func openPullRequest(ctx context.Context, r gitcmd.Runner, config *config.GitConfig, sourceBranch string) (pr.PullRequest, error) {
 	// Step 2: Check for differences between branches
 	diffChecker := pr.NewDiffCheckerWithRunner(config, r)
 	if err := diffChecker.CheckBranchDifferences(); err != nil {
-		return err
+		return pr.PullRequest{}, err
 	}
 
 	// Step 3: Create the actual pull request via GitHub API
 	creator := pr.NewCreatorWithRunner(config, r)
+	creator.SetChanges(diffChecker.Changes())
 	prResponse, err := creator.CreatePullRequest(ctx)
 	if err != nil {
-		return err
-	}
-
-	// Capture commit SHA (works for both auto-branch and manual branch flows)
-	if commitSHA, err := shared.CurrentCommitSHA(r); err == nil {
-		result.Set(output.KeyCommitSHA, commitSHA)
-	}
-
-	// Capture PR outputs
-	if prResponse.HTMLURL != "" {
-		result.Set(output.KeyPRURL, prResponse.HTMLURL)
-	}
-	if prResponse.HasNumber {
-		result.Set(output.KeyPRNumber, strconv.Itoa(prResponse.Number))
+		return pr.PullRequest{}, err
 	}
 
 	// Step 4: Process the PR response (labels, closing, etc.)
-	if err := creator.HandlePRResponse(ctx, prResponse, sourceBranch); err != nil {
-		return err
-	}
-
-	fmt.Println("\nGit Commit Action Completed Successfully!\n" +
-		"=========================================")
-
-	return nil
+	err = creator.HandlePRResponse(ctx, prResponse, sourceBranch)
+	return creator.PullRequest(), err
 }
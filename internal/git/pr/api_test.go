@@ -3,14 +3,19 @@ package pr
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 	"github.com/somaz94/go-git-commit-action/internal/github"
 )
@@ -76,6 +81,30 @@ func (f *fakeAPI) route(methodAndPath string, status int, body string) *fakeAPI
 	return f
 }
 
+// apiResponse is a canned JSON response of routeSequence.
+type apiResponse struct {
+	status int
+	body   string
+}
+
+// routeSequence registers responses for one endpoint that are returned in
+// turn, the last one from then on.
+func (f *fakeAPI) routeSequence(methodAndPath string, responses ...apiResponse) *fakeAPI {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	next := 0
+	f.routes[methodAndPath] = func(w http.ResponseWriter) {
+		f.mu.Lock()
+		resp := responses[min(next, len(responses)-1)]
+		next++
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}
+	return f
+}
+
 func (f *fakeAPI) Calls() []apiCall {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -174,6 +203,54 @@ func TestCreatePullRequest_GeneratedBodyCarriesCommitSHA(t *testing.T) {
 	}
 }
 
+// pr_body_file and pr_template render the body with the changes of the PR.
+func TestCreatePullRequest_BodyFromTemplate(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /pulls", http.StatusCreated, `{"html_url":"u","number":1}`)
+	cfg := prConfig()
+	cfg.PRTemplate = config.DefaultPRTemplate
+	cfg.PRBodyTemplate = "Files:{{ range .Changes }} {{ .Status }}:{{ .Path }}{{ end }}\n" +
+		"{{ .DiffStat }}\n{{ range .Commits }}- {{ .Subject }} ({{ .SHA }})\n{{ end }}{{ .RunURL }}"
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_RUN_ID", "42")
+	c, r := newAPICreator(t, cfg, api)
+	r.Stub(key(gitcmd.DiffNumstatArgs("origin/main", "origin/feature")),
+		gitcmd.FakeResult{Stdout: "3\t1\ta.txt\x00-\t-\tlogo.png\x00"}).
+		Stub(key(gitcmd.LogArgs("origin/main..origin/feature")),
+			gitcmd.FakeResult{Stdout: "abc1234\x1fbot\x1fUpdate a\x1f\x1e\n"})
+	c.SetChanges([]config.FileChange{{Status: "M", Path: "a.txt"}, {Status: "A", Path: "logo.png"}})
+
+	if _, err := c.CreatePullRequest(context.Background()); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v, want nil", err)
+	}
+	call, _ := api.called("POST /pulls")
+	want := "Files: M:a.txt A:logo.png\n" +
+		"| File | Added | Deleted |\n| --- | ---: | ---: |\n| `a.txt` | +3 | -1 |\n| `logo.png` | binary | binary |\n" +
+		"- Update a (abc1234)\nhttps://github.com/owner/repo/actions/runs/42"
+	if body, _ := call.Body["body"].(string); body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+// A repository template that is not a valid template is sent as it is.
+func TestCreatePullRequest_VerbatimBody(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /pulls", http.StatusCreated, `{"html_url":"u","number":1}`)
+	cfg := prConfig()
+	cfg.PRTemplate = config.DefaultPRTemplate
+	cfg.PRBodyTemplate = "Deployed ${{ github.sha }} {{ .Missing"
+	cfg.PRBodyVerbatim = true
+	c, _ := newAPICreator(t, cfg, api)
+
+	if _, err := c.CreatePullRequest(context.Background()); err != nil {
+		t.Fatalf("CreatePullRequest() error = %v, want nil", err)
+	}
+	call, _ := api.called("POST /pulls")
+	if body, _ := call.Body["body"].(string); body != cfg.PRBodyTemplate {
+		t.Errorf("body = %q, want %q", body, cfg.PRBodyTemplate)
+	}
+}
+
 // A failure reading the commit SHA must abort before any API call.
 func TestCreatePullRequest_CommitSHAFailureAborts(t *testing.T) {
 	api := newFakeAPI(t)
@@ -215,6 +292,10 @@ func TestHandlePRResponse_AppliesLabelsReviewersAssignees(t *testing.T) {
 			t.Errorf("Calls() = %v, want it to contain %q", api.Calls(), want)
 		}
 	}
+	want := PullRequest{Number: 7, URL: resp.HTMLURL, Action: PRActionCreated}
+	if got := c.PullRequest(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PullRequest() = %+v, want %+v", got, want)
+	}
 }
 
 func TestHandlePRResponse_ClosesPR(t *testing.T) {
@@ -236,6 +317,61 @@ func TestHandlePRResponse_ClosesPR(t *testing.T) {
 	}
 }
 
+// Rolling back a PR opened by the run removes its labels and closes it.
+func TestHandlePRResponse_RollbackClosesOpenedPR(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /issues/7/labels", http.StatusOK, `[]`).
+		route("DELETE /issues/7/labels/needs%20review", http.StatusOK, `[]`).
+		route("PATCH /pulls/7", http.StatusOK, `{}`)
+	cfg := prConfig()
+	cfg.PRLabels = []string{"needs review"}
+	c, _ := newAPICreator(t, cfg, api)
+	j := journal.New()
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	if err := c.HandlePRResponse(journal.NewContext(context.Background(), j), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+
+	calls := api.Calls()
+	var got []string
+	for _, call := range calls[1:] {
+		got = append(got, call.Method+" "+call.Path)
+	}
+	want := []string{"DELETE /issues/7/labels/needs%20review", "PATCH /pulls/7"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("rollback calls = %v, want %v", got, want)
+	}
+}
+
+// On a PR that already existed only the labels it did not carry are removed.
+func TestHandlePRResponse_RollbackKeepsExistingLabels(t *testing.T) {
+	api := newFakeAPI(t).
+		route("GET /issues/7/labels", http.StatusOK, `[{"name":"automated"}]`).
+		route("POST /issues/7/labels", http.StatusOK, `[]`).
+		route("DELETE /issues/7/labels/new", http.StatusOK, `[]`)
+	cfg := prConfig()
+	cfg.PRLabels = []string{"automated", "new"}
+	c, _ := newAPICreator(t, cfg, api)
+	j := journal.New()
+
+	if err := c.processExistingPR(journal.NewContext(context.Background(), j), 7); err != nil {
+		t.Fatalf("processExistingPR() error = %v, want nil", err)
+	}
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	if _, ok := api.called("DELETE /issues/7/labels/automated"); ok {
+		t.Error("removed a label the PR already had")
+	}
+	if _, ok := api.called("DELETE /issues/7/labels/new"); !ok {
+		t.Errorf("Calls() = %v, want the added label removed", api.Calls())
+	}
+}
+
 // A failing label call must surface as an error, not be swallowed.
 func TestHandlePRResponse_LabelFailurePropagates(t *testing.T) {
 	api := newFakeAPI(t) // no route → 404
@@ -275,8 +411,8 @@ func TestHandlePRResponse_ExistingPRIsReused(t *testing.T) {
 	cfg := prConfig()
 	cfg.PRLabels = []string{"automated"}
 	api := newFakeAPI(t).
-		route("GET /pulls?head="+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK,
-			`[{"number":42}]`).
+		route("GET /pulls?head=owner:"+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK,
+			`[{"number":42,"head":{"ref":"feature"}}]`).
 		route("POST /issues/42/labels", http.StatusOK, `[]`)
 	c, _ := newAPICreator(t, cfg, api)
 
@@ -292,6 +428,107 @@ func TestHandlePRResponse_ExistingPRIsReused(t *testing.T) {
 	}
 }
 
+// alreadyExists is the creation response for a branch with an open PR.
+var alreadyExists = PRResponse{
+	Message: "Validation Failed",
+	Errors:  []any{map[string]any{"message": "A pull request already exists for owner:feature."}},
+}
+
+// With pr_update_existing, the found PR gets the current title, body and
+// draft state, restored on rollback.
+func TestHandlePRResponse_UpdatesExistingPR(t *testing.T) {
+	cfg := prConfig()
+	cfg.PRUpdateExisting = true
+	cfg.PRTitle = "chore: sync v2"
+	cfg.PRBody = "Second run"
+	cfg.PRDraft = true
+	api := newFakeAPI(t).
+		route("GET /pulls?head=owner:"+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK,
+			`[{"number":42,"html_url":"https://github.com/owner/repo/pull/42","node_id":"PR_42","draft":false,
+			  "title":"chore: sync v1","body":"First run","head":{"ref":"feature"}}]`).
+		route("PATCH /pulls/42", http.StatusOK, `{}`).
+		route("POST /graphql", http.StatusOK, `{"data":{}}`)
+	c, _ := newAPICreator(t, cfg, api)
+	j := journal.New()
+
+	if err := c.HandlePRResponse(journal.NewContext(context.Background(), j), alreadyExists, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	call, ok := api.called("PATCH /pulls/42")
+	if !ok {
+		t.Fatalf("Calls() = %v, want a PATCH of PR #42", api.Calls())
+	}
+	for field, want := range map[string]string{"title": cfg.PRTitle, "body": cfg.PRBody} {
+		if got, _ := call.Body[field].(string); got != want {
+			t.Errorf("payload[%q] = %q, want %q", field, got, want)
+		}
+	}
+	if _, ok := call.Body["base"]; ok {
+		t.Errorf("payload = %v, want no base: the PR was found by it", call.Body)
+	}
+	call, ok = api.called("POST /graphql")
+	if query, _ := call.Body["query"].(string); !ok || !strings.Contains(query, "convertPullRequestToDraft") {
+		t.Errorf("GraphQL call = %v, want the PR converted to draft", call.Body)
+	}
+	want := PullRequest{Number: 42, URL: "https://github.com/owner/repo/pull/42", Action: PRActionUpdated}
+	if got := c.PullRequest(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PullRequest() = %+v, want %+v", got, want)
+	}
+
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	calls := api.Calls()
+	restore := calls[len(calls)-1]
+	if restore.Method != http.MethodPatch || restore.Body["title"] != "chore: sync v1" || restore.Body["body"] != "First run" {
+		t.Errorf("last rollback call = %+v, want the previous title and body restored", restore)
+	}
+	if query, _ := calls[len(calls)-2].Body["query"].(string); !strings.Contains(query, "markPullRequestReadyForReview") {
+		t.Errorf("rollback calls = %v, want the draft state restored", calls)
+	}
+}
+
+// A listed PR from another branch is never taken for the existing one.
+func TestHandlePRResponse_ExistingPRFromOtherBranchIgnored(t *testing.T) {
+	cfg := prConfig()
+	cfg.PRUpdateExisting = true
+	cfg.PRLabels = []string{"automated"}
+	api := newFakeAPI(t).
+		route("GET /pulls?head=owner:"+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK,
+			`[{"number":13,"title":"someone else's","head":{"ref":"other"}}]`)
+	c, _ := newAPICreator(t, cfg, api)
+
+	if err := c.HandlePRResponse(context.Background(), alreadyExists, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	if calls := api.Calls(); len(calls) != 1 {
+		t.Errorf("Calls() = %v, want only the lookup", calls)
+	}
+	if got := c.PullRequest(); got.Number != 0 {
+		t.Errorf("PullRequest() = %+v, want none", got)
+	}
+}
+
+// Without pr_update_existing the found PR is reported but left as it was.
+func TestHandlePRResponse_ExistingPRNotUpdated(t *testing.T) {
+	cfg := prConfig()
+	api := newFakeAPI(t).
+		route("GET /pulls?head=owner:"+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK,
+			`[{"number":42,"html_url":"u42","draft":true,"head":{"ref":"feature"}}]`)
+	c, _ := newAPICreator(t, cfg, api)
+
+	if err := c.HandlePRResponse(context.Background(), alreadyExists, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	if _, ok := api.called("PATCH /pulls/42"); ok {
+		t.Error("the existing PR was updated, want it left as it was")
+	}
+	want := PullRequest{Number: 42, URL: "u42", Action: PRActionExisting}
+	if got := c.PullRequest(); !reflect.DeepEqual(got, want) {
+		t.Errorf("PullRequest() = %+v, want %+v", got, want)
+	}
+}
+
 // An error message that is not "already exists" must fail.
 func TestHandlePRResponse_OtherAPIErrorFails(t *testing.T) {
 	api := newFakeAPI(t)
@@ -311,7 +548,7 @@ func TestHandlePRResponse_ExistingPRLookupEmpty(t *testing.T) {
 	cfg := prConfig()
 	cfg.PRLabels = []string{"automated"}
 	api := newFakeAPI(t).
-		route("GET /pulls?head="+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK, `[]`)
+		route("GET /pulls?head=owner:"+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK, `[]`)
 	c, _ := newAPICreator(t, cfg, api)
 
 	resp := PRResponse{
@@ -368,6 +605,314 @@ func TestHandlePRResponse_DeletesAutoSourceBranch(t *testing.T) {
 	}
 }
 
+// pr_auto_merge enables auto-merge through GraphQL, guarded by the head commit,
+// and a rollback disables it again.
+func TestHandlePRResponse_EnablesAutoMerge(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /graphql", http.StatusOK, `{"data":{}}`).
+		route("PATCH /pulls/7", http.StatusOK, `{}`)
+	cfg := prConfig()
+	cfg.PRAutoMerge = "squash"
+	c, _ := newAPICreator(t, cfg, api)
+	j := journal.New()
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true, NodeID: "PR_7"}
+	if err := c.HandlePRResponse(journal.NewContext(context.Background(), j), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	call, ok := api.called("POST /graphql")
+	if query, _ := call.Body["query"].(string); !ok || !strings.Contains(query, "enablePullRequestAutoMerge") {
+		t.Fatalf("Calls() = %v, want auto-merge enabled", api.Calls())
+	}
+	vars, _ := call.Body["variables"].(map[string]any)
+	for name, want := range map[string]string{"id": "PR_7", "method": "SQUASH", "sha": "abc1234"} {
+		if got, _ := vars[name].(string); got != want {
+			t.Errorf("variables[%q] = %q, want %q", name, got, want)
+		}
+	}
+
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	// Auto-merge is disabled before the opened PR is closed.
+	calls := api.Calls()
+	if query, _ := calls[len(calls)-2].Body["query"].(string); !strings.Contains(query, "disablePullRequestAutoMerge") {
+		t.Errorf("rollback calls = %v, want auto-merge disabled", calls)
+	}
+}
+
+// Without a node ID in the response it is read from the PR.
+func TestHandlePRResponse_AutoMergeLooksUpNodeID(t *testing.T) {
+	api := newFakeAPI(t).
+		route("GET /pulls/7", http.StatusOK, `{"number":7,"node_id":"PR_7"}`).
+		route("POST /graphql", http.StatusOK, `{"data":{}}`)
+	cfg := prConfig()
+	cfg.PRAutoMerge = "merge"
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	if err := c.HandlePRResponse(context.Background(), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	call, _ := api.called("POST /graphql")
+	if vars, _ := call.Body["variables"].(map[string]any); vars["id"] != "PR_7" {
+		t.Errorf("variables = %v, want the node ID of PR #7", vars)
+	}
+}
+
+// pr_merge_now merges through the REST endpoint with the head commit as the
+// expected SHA.
+func TestHandlePRResponse_MergesNow(t *testing.T) {
+	api := newFakeAPI(t).route("PUT /pulls/7/merge", http.StatusOK, `{"merged":true}`)
+	cfg := prConfig()
+	cfg.PRMergeNow = "rebase"
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	if err := c.HandlePRResponse(context.Background(), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	call, ok := api.called("PUT /pulls/7/merge")
+	if !ok {
+		t.Fatalf("Calls() = %v, want a PUT merging the PR", api.Calls())
+	}
+	for field, want := range map[string]string{"merge_method": "rebase", "sha": "abc1234"} {
+		if got, _ := call.Body[field].(string); got != want {
+			t.Errorf("payload[%q] = %q, want %q", field, got, want)
+		}
+	}
+}
+
+// An existing PR is merged only once its head is verified as pr_branch.
+func TestHandlePRResponse_MergesExistingPRFromBranch(t *testing.T) {
+	cfg := prConfig()
+	cfg.PRMergeNow = "squash"
+	api := newFakeAPI(t).
+		route("GET /pulls?head=owner:"+cfg.PRBranch+"&base="+cfg.PRBase, http.StatusOK,
+			`[{"number":42,"head":{"ref":"feature"}}]`).
+		route("PUT /pulls/42/merge", http.StatusOK, `{"merged":true}`)
+	c, _ := newAPICreator(t, cfg, api)
+
+	if err := c.HandlePRResponse(context.Background(), alreadyExists, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	if _, ok := api.called("PUT /pulls/42/merge"); !ok {
+		t.Errorf("Calls() = %v, want PR #42 merged", api.Calls())
+	}
+}
+
+// A PR from another branch is never merged or set to auto-merge.
+func TestMergeOrAutoMerge_RefusesOtherHead(t *testing.T) {
+	for _, tt := range []struct{ name, mergeNow, autoMerge string }{
+		{"merge now", "merge", ""},
+		{"auto-merge", "", "squash"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newFakeAPI(t)
+			cfg := prConfig()
+			cfg.PRMergeNow, cfg.PRAutoMerge = tt.mergeNow, tt.autoMerge
+			c, _ := newAPICreator(t, cfg, api)
+
+			err := c.mergeOrAutoMerge(context.Background(), 13, "other")
+			if !errors.IsPermanent(err) {
+				t.Errorf("mergeOrAutoMerge() error = %v, want a permanent refusal", err)
+			}
+			if calls := api.Calls(); len(calls) != 0 {
+				t.Errorf("Calls() = %v, want none", calls)
+			}
+		})
+	}
+}
+
+func TestHandlePRResponse_RejectedMergeFails(t *testing.T) {
+	api := newFakeAPI(t).
+		route("PUT /pulls/7/merge", http.StatusMethodNotAllowed, `{"message":"Pull Request is not mergeable"}`)
+	cfg := prConfig()
+	cfg.PRMergeNow = "merge"
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	err := c.HandlePRResponse(context.Background(), resp, "feature")
+	if err == nil || !strings.Contains(err.Error(), "not mergeable") {
+		t.Errorf("HandlePRResponse() error = %v, want the rejected merge to fail", err)
+	}
+}
+
+// pr_reviewers_from_codeowners requests the owners of the changed files from
+// the CODEOWNERS file of the base branch, along with pr_reviewers and
+// pr_team_reviewers, but not the PR author.
+func TestHandlePRResponse_RequestsCodeowners(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /pulls/7/requested_reviewers", http.StatusCreated, `{}`)
+	cfg := prConfig()
+	cfg.PRReviewers = []string{"carol", "@Bob"}
+	cfg.PRTeamReviewers = []string{"my-org/platform"}
+	cfg.PRCodeowners = true
+	c, r := newAPICreator(t, cfg, api)
+	r.Stub(key(gitcmd.CatFileBlobArgs("origin/main:.github/CODEOWNERS")), gitcmd.FakeResult{Stdout: "" +
+		"# Default owners\n" +
+		"*           @alice @my-org/core\n" +
+		"/docs/      @bob @my-org/docs # writers\n" +
+		"*.md        dev@example.com\n" +
+		"/vendor/\n"})
+	c.SetChanges([]config.FileChange{
+		{Status: "M", Path: "main.go"},
+		{Status: "A", Path: "docs/guide/setup.txt"},
+		{Status: "M", Path: "README.md"},
+		{Status: "D", Path: "vendor/lib/lib.go"},
+	})
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true, Author: "alice"}
+	if err := c.HandlePRResponse(context.Background(), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	call, ok := api.called("POST /pulls/7/requested_reviewers")
+	if !ok {
+		t.Fatalf("Calls() = %v, want reviewers requested", api.Calls())
+	}
+	for field, want := range map[string][]any{
+		"reviewers":      {"carol", "Bob"},
+		"team_reviewers": {"platform", "core", "docs"},
+	} {
+		if got, _ := call.Body[field].([]any); !reflect.DeepEqual(got, want) {
+			t.Errorf("payload[%q] = %v, want %v", field, got, want)
+		}
+	}
+}
+
+// A user who cannot review is left out with a warning and the others are
+// requested again.
+func TestHandlePRResponse_SkipsUnknownReviewers(t *testing.T) {
+	api := newFakeAPI(t).
+		routeSequence("POST /pulls/7/requested_reviewers",
+			apiResponse{http.StatusUnprocessableEntity, `{"message":"Reviews may only be requested from collaborators."}`},
+			apiResponse{http.StatusCreated, `{}`}).
+		route("GET /collaborators/alice/permission", http.StatusOK, `{"permission":"write"}`).
+		route("GET /collaborators/ghost/permission", http.StatusNotFound, `{"message":"Not Found"}`)
+	cfg := prConfig()
+	cfg.PRReviewers = []string{"alice", "ghost"}
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	if err := c.HandlePRResponse(context.Background(), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want the unknown reviewer skipped", err)
+	}
+	calls := api.Calls()
+	last := calls[len(calls)-1]
+	if last.Path != "/pulls/7/requested_reviewers" || !reflect.DeepEqual(last.Body["reviewers"], []any{"alice"}) {
+		t.Errorf("last call = %+v, want alice requested again without ghost", last)
+	}
+}
+
+// When every user can review, the rejection is reported.
+func TestHandlePRResponse_RejectedReviewersFail(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /pulls/7/requested_reviewers", http.StatusForbidden, `{"message":"Resource not accessible by integration"}`).
+		route("GET /collaborators/alice/permission", http.StatusOK, `{"permission":"write"}`)
+	cfg := prConfig()
+	cfg.PRReviewers = []string{"alice"}
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	err := c.HandlePRResponse(context.Background(), resp, "feature")
+	if err == nil || !strings.Contains(err.Error(), "Resource not accessible") {
+		t.Errorf("HandlePRResponse() error = %v, want the rejection", err)
+	}
+}
+
+// fastCheckPolls makes waitForChecks poll without waiting.
+func fastCheckPolls(t *testing.T) {
+	t.Helper()
+	checkPollDelay = time.Millisecond
+	t.Cleanup(func() { checkPollDelay = 5 * time.Second })
+}
+
+// pr_wait_for_checks polls until the pending checks pass, and only merges then.
+func TestHandlePRResponse_WaitsForChecksThenMerges(t *testing.T) {
+	fastCheckPolls(t)
+	api := newFakeAPI(t).
+		routeSequence("GET /commits/abc1234/status",
+			apiResponse{http.StatusOK, `{"state":"pending","statuses":[{"context":"ci/lint","state":"pending","target_url":"https://ci/lint"}]}`},
+			apiResponse{http.StatusOK, `{"state":"success","statuses":[{"context":"ci/lint","state":"success","target_url":"https://ci/lint"}]}`}).
+		route("GET /commits/abc1234/check-runs?per_page=100", http.StatusOK,
+			`{"total_count":1,"check_runs":[{"name":"test","status":"completed","conclusion":"success","html_url":"https://ci/test"}]}`).
+		route("PUT /pulls/7/merge", http.StatusOK, `{"merged":true}`)
+	cfg := prConfig()
+	cfg.PRWaitForChecks = true
+	cfg.PRMergeNow = "squash"
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	if err := c.HandlePRResponse(context.Background(), resp, "feature"); err != nil {
+		t.Fatalf("HandlePRResponse() error = %v, want nil", err)
+	}
+	want := []Check{
+		{Name: "ci/lint", Conclusion: "success", URL: "https://ci/lint"},
+		{Name: "test", Conclusion: "success", URL: "https://ci/test"},
+	}
+	if got := c.PullRequest().Checks; !reflect.DeepEqual(got, want) {
+		t.Errorf("Checks = %+v, want %+v", got, want)
+	}
+	calls := api.Calls()
+	if last := calls[len(calls)-1]; last.Method != http.MethodPut || last.Path != "/pulls/7/merge" {
+		t.Errorf("Calls() = %v, want the merge after the checks", calls)
+	}
+}
+
+// A failed check fails the step at once with the failing checks listed, and
+// the PR is not merged.
+func TestHandlePRResponse_FailingChecksFail(t *testing.T) {
+	fastCheckPolls(t)
+	api := newFakeAPI(t).
+		route("GET /commits/abc1234/status", http.StatusOK, `{"state":"pending","statuses":[]}`).
+		route("GET /commits/abc1234/check-runs?per_page=100", http.StatusOK, `{"total_count":2,"check_runs":[
+			{"name":"build","status":"in_progress","conclusion":null,"html_url":"https://ci/build"},
+			{"name":"test","status":"completed","conclusion":"failure","html_url":"https://ci/test"}]}`)
+	cfg := prConfig()
+	cfg.PRWaitForChecks = true
+	cfg.PRMergeNow = "merge"
+	c, _ := newAPICreator(t, cfg, api)
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	err := c.HandlePRResponse(context.Background(), resp, "feature")
+	var apiErr *errors.APIError
+	if !stderrors.As(err, &apiErr) || !strings.Contains(err.Error(), "test (failure)") {
+		t.Fatalf("HandlePRResponse() error = %v, want an APIError listing the failing check", err)
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("error = %v, want it permanent so that the run is not retried", err)
+	}
+	if _, ok := api.called("PUT /pulls/7/merge"); ok {
+		t.Error("PR was merged despite the failing check")
+	}
+	want := []Check{
+		{Name: "build", Conclusion: CheckPending, URL: "https://ci/build"},
+		{Name: "test", Conclusion: "failure", URL: "https://ci/test"},
+	}
+	if got := c.PullRequest().Checks; !reflect.DeepEqual(got, want) {
+		t.Errorf("Checks = %+v, want %+v", got, want)
+	}
+}
+
+// Waiting stops with the timeout, here the context deadline.
+func TestHandlePRResponse_ChecksTimeOut(t *testing.T) {
+	fastCheckPolls(t)
+	api := newFakeAPI(t).
+		route("GET /commits/abc1234/status", http.StatusOK, `{"state":"pending","statuses":[]}`).
+		route("GET /commits/abc1234/check-runs?per_page=100", http.StatusOK, `{"total_count":0,"check_runs":[]}`)
+	cfg := prConfig()
+	cfg.PRWaitForChecks = true
+	c, _ := newAPICreator(t, cfg, api)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resp := PRResponse{HTMLURL: "u", Number: 7, HasNumber: true}
+	err := c.HandlePRResponse(ctx, resp, "feature")
+	if err == nil || !strings.Contains(err.Error(), "no checks reported") {
+		t.Errorf("HandlePRResponse() error = %v, want a timeout without checks", err)
+	}
+}
+
 func TestNewClientWithBaseURL_TargetsGivenHost(t *testing.T) {
 	api := newFakeAPI(t).route("POST /pulls", http.StatusCreated, `{"number":1}`)
 	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
@@ -0,0 +1,178 @@
+package pr
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// maxPRBodyLength is the longest pull request body GitHub accepts, in
+// characters.
+const maxPRBodyLength = 65536
+
+// truncatedBodyNote ends a body that had to be cut short.
+const truncatedBodyNote = "\n\n_The description was truncated to fit GitHub's size limit._"
+
+// templateBody renders the pr_body_file or pr_template template for the pull
+// request whose head commit is headSHA.
+func (c *Creator) templateBody(headSHA string) (string, error) {
+	if c.config.PRBodyVerbatim {
+		return cutBody(c.config.PRBodyTemplate), nil
+	}
+	data, err := c.bodyData(headSHA)
+	if err != nil {
+		return "", err
+	}
+	field := c.config.PRBodyTemplateInput()
+	body, err := renderPRBody(field, c.config.PRBodyTemplate, data)
+	if err != nil {
+		return "", errors.NewConfigError(field, fmt.Sprintf("render template: %v", err))
+	}
+	return body, nil
+}
+
+// bodyData returns the variables of the body template: the changes found by
+// the DiffChecker, and the line counts and commits between the fetched base
+// and source branches.
+func (c *Creator) bodyData(headSHA string) (config.PRTemplateData, error) {
+	base, head := "origin/"+c.config.PRBase, "origin/"+c.config.PRBranch
+
+	out, err := c.runner.Output(gitcmd.CmdGit, gitcmd.DiffNumstatArgs(base, head)...)
+	if err != nil {
+		return config.PRTemplateData{}, errors.New("count changed lines", err)
+	}
+	stats := parseNumstat(string(out))
+
+	out, err = c.runner.Output(gitcmd.CmdGit, gitcmd.LogArgs(base+".."+head)...)
+	if err != nil {
+		return config.PRTemplateData{}, errors.NewWithPath("list commits", base+".."+head, err)
+	}
+	commits := parseCommitLog(string(out))
+
+	paths := make([]string, len(c.changes))
+	for i, ch := range c.changes {
+		paths[i] = ch.Path
+	}
+	data := config.PRTemplateData{
+		TemplateData: config.NewTemplateData(c.config.Branch, headSHA, paths),
+		Base:         c.config.PRBase,
+		Head:         c.config.PRBranch,
+		Changes:      c.changes,
+		Stats:        stats,
+		Commits:      commits,
+	}
+	if server := os.Getenv("GITHUB_SERVER_URL"); server != "" && data.Repo != "" && data.RunID != "" {
+		data.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, data.Repo, data.RunID)
+	}
+	return data, nil
+}
+
+// parseNumstat returns the line counts of NUL-terminated "git diff --numstat
+// --no-renames" output.
+func parseNumstat(out string) []config.FileStat {
+	var stats []config.FileStat
+	for _, record := range strings.Split(out, "\x00") {
+		// "<added>\t<deleted>\t<path>"
+		fields := strings.SplitN(record, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		stat := config.FileStat{Path: fields[2], Binary: fields[0] == "-"}
+		stat.Added, _ = strconv.Atoi(fields[0])
+		stat.Deleted, _ = strconv.Atoi(fields[1])
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// parseCommitLog returns the commits of "git log" output in
+// gitcmd.LogFormat.
+func parseCommitLog(out string) []config.CommitInfo {
+	var commits []config.CommitInfo
+	for _, record := range strings.Split(out, gitcmd.LogRecordSep) {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), gitcmd.LogFieldSep)
+		if len(fields) < 3 {
+			continue
+		}
+		commits = append(commits, config.CommitInfo{SHA: fields[0], Author: fields[1], Subject: fields[2]})
+	}
+	return commits
+}
+
+// renderPRBody renders text with data. A body longer than maxPRBodyLength is
+// rendered again with the lists of data cut to ever fewer entries; should
+// even empty lists not make it fit, the body itself is cut.
+func renderPRBody(name, text string, data config.PRTemplateData) (string, error) {
+	limit := max(len(data.Changes), len(data.Stats), len(data.Commits))
+	for {
+		body, err := config.RenderTemplate(name, text, truncateLists(data, limit))
+		if err != nil {
+			return "", err
+		}
+		if utf8.RuneCountInString(body) <= maxPRBodyLength || limit == 0 {
+			return cutBody(body), nil
+		}
+		limit /= 2
+	}
+}
+
+// cutBody returns body cut to maxPRBodyLength, ending with a note when it had
+// to be cut.
+func cutBody(body string) string {
+	if utf8.RuneCountInString(body) <= maxPRBodyLength {
+		return body
+	}
+	keep := maxPRBodyLength - utf8.RuneCountInString(truncatedBodyNote)
+	return string([]rune(body)[:keep]) + truncatedBodyNote
+}
+
+// truncateLists returns data with its lists cut to at most limit entries and
+// DiffStat made from the cut Stats.
+func truncateLists(data config.PRTemplateData, limit int) config.PRTemplateData {
+	omitted := 0
+	if len(data.Stats) > limit {
+		omitted = len(data.Stats) - limit
+		data.Stats = data.Stats[:limit]
+		data.Truncated = true
+	}
+	if len(data.Changes) > limit {
+		data.Changes = data.Changes[:limit]
+		data.ChangedFiles = data.ChangedFiles[:limit]
+		data.Truncated = true
+	}
+	if len(data.Commits) > limit {
+		data.Commits = data.Commits[:limit]
+		data.Truncated = true
+	}
+	data.DiffStat = diffStatTable(data.Stats, omitted)
+	return data
+}
+
+// diffStatTable renders stats as a Markdown table, with a last row for the
+// omitted files.
+func diffStatTable(stats []config.FileStat, omitted int) string {
+	if len(stats) == 0 && omitted == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("| File | Added | Deleted |\n| --- | ---: | ---: |")
+	for _, s := range stats {
+		path := strings.ReplaceAll(s.Path, "|", `\|`)
+		if s.Binary {
+			fmt.Fprintf(&b, "\n| `%s` | binary | binary |", path)
+		} else {
+			fmt.Fprintf(&b, "\n| `%s` | +%d | -%d |", path, s.Added, s.Deleted)
+		}
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&b, "\n| _%d more files_ | | |", omitted)
+	}
+	return b.String()
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/git/guard"
 	"github.com/somaz94/go-git-commit-action/internal/git/shared"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 )
@@ -17,6 +18,9 @@ const (
 type BranchManager struct {
 	config *config.GitConfig
 	runner gitcmd.Runner
+
+	// staged lists the paths committed to an auto branch; nil when unknown.
+	staged []string
 }
 
 // NewBranchManager creates a new BranchManager instance.
@@ -40,9 +44,15 @@ func (bm *BranchManager) PrepareSourceBranch() (string, error) {
 	return bm.checkoutExistingBranch()
 }
 
+// AutoBranchName returns the name of a new auto branch, made unique by the
+// current time.
+func AutoBranchName() string {
+	return fmt.Sprintf("update-files-%s", time.Now().Format(timestampFormat))
+}
+
 // createAutoBranch creates a new branch with a timestamp and commits changes to it.
 func (bm *BranchManager) createAutoBranch() (string, error) {
-	sourceBranch := fmt.Sprintf("update-files-%s", time.Now().Format(timestampFormat))
+	sourceBranch := AutoBranchName()
 	bm.config.PRBranch = sourceBranch
 
 	// Create and switch to a new branch
@@ -52,19 +62,53 @@ func (bm *BranchManager) createAutoBranch() (string, error) {
 	}
 
 	// Stage files using shared utility
-	if err := shared.StageFiles(bm.runner, bm.config.FilePattern); err != nil {
+	if err := shared.StageFiles(bm.runner, bm.config.FilePatterns(), bm.config.FileExcludes()); err != nil {
+		return "", err
+	}
+	if files, err := shared.StagedFiles(bm.runner); err == nil {
+		bm.staged = files
+	}
+	if err := guard.NewCheckerWithRunner(bm.config, bm.runner).Check(); err != nil {
 		return "", err
 	}
 
 	// Commit and push using shared utility (new branch — set upstream tracking)
-	if err := shared.CommitAndPush(bm.runner, bm.config.CommitMessage, sourceBranch,
-		shared.CommitPushOptions{SetUpstream: true}); err != nil {
+	if _, err := shared.CommitAndPush(bm.runner, bm.config.CommitMessage, sourceBranch,
+		shared.CommitOptionsFor(bm.config, shared.CommitPushOptions{SetUpstream: true})); err != nil {
 		return "", err
 	}
 
 	return sourceBranch, nil
 }
 
+// CreateSplitBranch creates sourceBranch from HEAD and commits the staged
+// changes, which are left to the caller to stage, and pushes it, for one of
+// the pull requests of pr_split_by. The branch stays checked out.
+func (bm *BranchManager) CreateSplitBranch(sourceBranch string) error {
+	bm.config.PRBranch = sourceBranch
+
+	if err := shared.RunStep(bm.runner, fmt.Sprintf("Creating new branch %s", sourceBranch),
+		gitcmd.CmdGit, gitcmd.CheckoutNewBranchArgs(sourceBranch)...); err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	if files, err := shared.StagedFiles(bm.runner); err == nil {
+		bm.staged = files
+	}
+	if err := guard.NewCheckerWithRunner(bm.config, bm.runner).Check(); err != nil {
+		return err
+	}
+
+	_, err := shared.CommitAndPush(bm.runner, bm.config.CommitMessage, sourceBranch,
+		shared.CommitOptionsFor(bm.config, shared.CommitPushOptions{SetUpstream: true}))
+	return err
+}
+
+// StagedFiles returns the paths staged for the commit of an auto branch, or
+// nil when PrepareSourceBranch staged nothing or could not list them.
+func (bm *BranchManager) StagedFiles() []string {
+	return bm.staged
+}
+
 // checkoutExistingBranch checks out the specified PR branch.
 func (bm *BranchManager) checkoutExistingBranch() (string, error) {
 	sourceBranch := bm.config.PRBranch
@@ -0,0 +1,140 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+)
+
+var (
+	// checkPollDelay is the first wait between looks at the checks; it
+	// doubles up to maxCheckPollDelay. Variables so that tests need not wait.
+	checkPollDelay    = 5 * time.Second
+	maxCheckPollDelay = 30 * time.Second
+)
+
+// CheckPending is the Conclusion of a check that has not completed.
+const CheckPending = "pending"
+
+// Check is a commit status or check run on the head commit of a PR.
+type Check struct {
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"` // e.g. success, failure, or CheckPending
+	URL        string `json:"url"`
+}
+
+// passed reports whether the check completed without failing.
+func (ch Check) passed() bool {
+	switch ch.Conclusion {
+	case "success", "neutral", "skipped":
+		return true
+	}
+	return false
+}
+
+// waitForChecks polls the commit statuses and check runs of the head commit
+// until all of them have passed, one has failed or ctx, bounded by the
+// timeout input, is done. The last view of the checks is kept in the
+// PullRequest. A commit without any checks is waited on as well, since they
+// are often reported some time after the push.
+func (c *Creator) waitForChecks(ctx context.Context, prNumber int) error {
+	if c.config.PRDryRun {
+		fmt.Printf("  - [DRY RUN] Would wait for checks on PR #%d... Skipped\n", prNumber)
+		return nil
+	}
+
+	headSHA, err := shared.CurrentCommitSHA(c.runner)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("  - Waiting for checks on PR #%d\n", prNumber)
+	delay := checkPollDelay
+	for {
+		checks, err := c.commitChecks(ctx, headSHA)
+		if err != nil {
+			return err
+		}
+		c.pr.Checks = checks
+
+		var pending, failing []string
+		for _, ch := range checks {
+			switch {
+			case ch.Conclusion == CheckPending:
+				pending = append(pending, ch.Name)
+			case !ch.passed():
+				failing = append(failing, fmt.Sprintf("%s (%s)", ch.Name, ch.Conclusion))
+			}
+		}
+		switch {
+		case len(failing) > 0:
+			fmt.Printf("  - Checks failed: %s\n", strings.Join(failing, ", "))
+			// Waiting or retrying the run does not change a failed check.
+			return errors.Permanent(errors.NewAPIError("wait for checks", "failing checks: "+strings.Join(failing, ", ")))
+		case len(checks) > 0 && len(pending) == 0:
+			fmt.Printf("  - All %d checks passed\n", len(checks))
+			return nil
+		case len(checks) == 0:
+			fmt.Printf("  - No checks reported yet, waiting %s\n", delay)
+		default:
+			fmt.Printf("  - %d of %d checks pending, waiting %s\n", len(pending), len(checks), delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			reason := "no checks reported"
+			if len(pending) > 0 {
+				reason = "pending checks: " + strings.Join(pending, ", ")
+			}
+			return errors.Permanent(errors.NewAPIError("wait for checks", "timed out with "+reason))
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxCheckPollDelay)
+	}
+}
+
+// commitChecks returns the commit statuses and check runs of sha, the
+// statuses first.
+func (c *Creator) commitChecks(ctx context.Context, sha string) ([]Check, error) {
+	status, err := c.client.Get(ctx, fmt.Sprintf("/commits/%s/status", sha))
+	if err != nil {
+		return nil, errors.NewAPIErrorFrom("get commit status", err)
+	}
+	if msg, _ := status["message"].(string); msg != "" {
+		return nil, errors.NewAPIError("get commit status", msg)
+	}
+	runs, err := c.client.Get(ctx, fmt.Sprintf("/commits/%s/check-runs?per_page=100", sha))
+	if err != nil {
+		return nil, errors.NewAPIErrorFrom("get check runs", err)
+	}
+	if msg, _ := runs["message"].(string); msg != "" {
+		return nil, errors.NewAPIError("get check runs", msg)
+	}
+
+	checks := []Check{}
+	statuses, _ := status["statuses"].([]any)
+	for _, s := range statuses {
+		m, _ := s.(map[string]any)
+		ch := Check{}
+		ch.Name, _ = m["context"].(string)
+		ch.Conclusion, _ = m["state"].(string) // pending, success, failure or error
+		ch.URL, _ = m["target_url"].(string)
+		checks = append(checks, ch)
+	}
+	checkRuns, _ := runs["check_runs"].([]any)
+	for _, r := range checkRuns {
+		m, _ := r.(map[string]any)
+		ch := Check{Conclusion: CheckPending}
+		ch.Name, _ = m["name"].(string)
+		ch.URL, _ = m["html_url"].(string)
+		if state, _ := m["status"].(string); state == "completed" {
+			ch.Conclusion, _ = m["conclusion"].(string)
+		}
+		checks = append(checks, ch)
+	}
+	return checks, nil
+}
@@ -0,0 +1,142 @@
+package pr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// codeownersPaths are the places GitHub looks for the CODEOWNERS file, in
+// the order it does.
+var codeownersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// codeownersRule is a line of a CODEOWNERS file: the paths matching pattern
+// are owned by owners, "@user", "@org/team" or email addresses. A rule
+// without owners leaves its paths unowned.
+type codeownersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// codeownerReviewers returns the users and teams that own the files the PR
+// changes according to the CODEOWNERS file of the base branch, which is the
+// one GitHub goes by. Owners given by email cannot be requested and are left
+// out with a warning.
+func (c *Creator) codeownerReviewers() (users, teams []string) {
+	rules, path := c.readCodeowners()
+	if path == "" {
+		fmt.Printf("  - [WARN] No CODEOWNERS file found on %s\n", c.config.PRBase)
+		return nil, nil
+	}
+
+	seen := map[string]bool{}
+	for _, ch := range c.changes {
+		for _, owner := range matchCodeowners(rules, ch.Path) {
+			if seen[strings.ToLower(owner)] {
+				continue
+			}
+			seen[strings.ToLower(owner)] = true
+			switch {
+			case !strings.HasPrefix(owner, "@"):
+				fmt.Printf("  - [WARN] Cannot request a review by email from %s (%s)\n", owner, path)
+			case strings.Contains(owner, "/"):
+				teams = append(teams, owner)
+			default:
+				users = append(users, owner)
+			}
+		}
+	}
+	return users, teams
+}
+
+// readCodeowners returns the rules of the first CODEOWNERS file found on the
+// fetched base branch and its path, or "" when there is none.
+func (c *Creator) readCodeowners() ([]codeownersRule, string) {
+	for _, path := range codeownersPaths {
+		out, err := c.runner.Output(gitcmd.CmdGit, gitcmd.CatFileBlobArgs("origin/"+c.config.PRBase+":"+path)...)
+		if err == nil {
+			return parseCodeowners(string(out)), path
+		}
+	}
+	return nil, ""
+}
+
+// parseCodeowners returns the rules of a CODEOWNERS file. "#" starts a
+// comment, unless escaped as "\#" in a pattern. Lines GitHub would reject,
+// such as ones with a "!" pattern, are skipped.
+func parseCodeowners(content string) []codeownersRule {
+	var rules []codeownersRule
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if strings.HasPrefix(field, "#") {
+				fields = fields[:i]
+				break
+			}
+		}
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+		pattern, err := codeownersPattern(strings.ReplaceAll(fields[0], `\#`, "#"))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, codeownersRule{pattern: pattern, owners: fields[1:]})
+	}
+	return rules
+}
+
+// codeownersPattern compiles a CODEOWNERS pattern, which follows the
+// gitignore rules: a pattern with a slash at the start or in the middle is
+// relative to the root, others match at any depth; "*" and "?" do not match
+// a slash, "**" matches across directories; a pattern naming a directory
+// matches everything beneath it. Unlike gitignore, and as GitHub documents,
+// "docs/*" does not match the files in subdirectories of docs.
+func codeownersPattern(pattern string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	p := strings.Trim(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case p[i] == '*':
+			b.WriteString("[^/]*")
+		case p[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(p[i : i+1]))
+		}
+	}
+	switch {
+	case dirOnly:
+		b.WriteString("/.*")
+	case !strings.HasSuffix(p, "*"):
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// matchCodeowners returns the owners of path: those of the last rule that
+// matches it.
+func matchCodeowners(rules []codeownersRule, path string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if rules[i].pattern.MatchString(path) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
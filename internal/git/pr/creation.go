@@ -3,11 +3,14 @@ package pr
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
 	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
 	"github.com/somaz94/go-git-commit-action/internal/git/shared"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 	"github.com/somaz94/go-git-commit-action/internal/github"
@@ -18,6 +21,32 @@ type Creator struct {
 	config *config.GitConfig
 	client *github.Client
 	runner gitcmd.Runner
+
+	// opened is the number of the PR created by this Creator, if any.
+	opened int
+	// pr is the PR created or found by HandlePRResponse, and nodeID its
+	// GraphQL ID when known.
+	pr     PullRequest
+	nodeID string
+	// author is the login of the user who opened the PR, when known.
+	author string
+	// changes are the files the PR changes, for the body template.
+	changes []config.FileChange
+}
+
+// Actions reported by PullRequest.Action.
+const (
+	PRActionCreated  = "created"  // a new PR was opened
+	PRActionUpdated  = "updated"  // an open PR was found and updated
+	PRActionExisting = "existing" // an open PR was found and left as it was
+)
+
+// PullRequest is the pull request HandlePRResponse created or found.
+type PullRequest struct {
+	Number int
+	URL    string  // "" when there is none
+	Action string  // one of the PRAction values; "" in dry-run mode
+	Checks []Check // the checks of its head commit, with pr_wait_for_checks
 }
 
 // NewCreator creates a new Creator instance.
@@ -42,6 +71,12 @@ func NewCreatorWithClient(cfg *config.GitConfig, r gitcmd.Runner, client *github
 	}
 }
 
+// SetChanges sets the files the PR changes, as found by the DiffChecker, for
+// the pr_body_file or pr_template template.
+func (c *Creator) SetChanges(changes []config.FileChange) {
+	c.changes = changes
+}
+
 // PRResponse is the typed view of a GitHub PR-creation API response, or of the
 // internal dry-run mock. It models only the fields the PR path consumes; any
 // other API fields are dropped. Errors is left as []any so the diagnostic
@@ -51,6 +86,8 @@ type PRResponse struct {
 	Number    int    // "number"; meaningful only when HasNumber is true
 	HasNumber bool   // true when the response carried a numeric "number"
 	DryRun    bool   // internal marker; set by the dry-run path, never from the API
+	NodeID    string // "node_id"; the GraphQL ID
+	Author    string // "user.login"
 	Message   string // "message"; non-empty on API error responses
 	Errors    []any  // "errors"; nil when the key is absent (presence == old ",ok")
 }
@@ -67,6 +104,12 @@ func parsePRResponse(m map[string]any) PRResponse {
 		r.Number = int(v)
 		r.HasNumber = true
 	}
+	if v, ok := m["node_id"].(string); ok {
+		r.NodeID = v
+	}
+	if user, ok := m["user"].(map[string]any); ok {
+		r.Author, _ = user["login"].(string)
+	}
 	if v, ok := m["dry_run"].(bool); ok {
 		r.DryRun = v
 	}
@@ -122,14 +165,15 @@ func (c *Creator) createActualPR(ctx context.Context) (PRResponse, error) {
 
 // preparePRData creates the data structure needed for the PR creation API call.
 func (c *Creator) preparePRData() (map[string]interface{}, error) {
-	runID := os.Getenv("GITHUB_RUN_ID")
-
 	commitSHA, err := shared.CurrentCommitSHA(c.runner)
 	if err != nil {
 		return nil, err
 	}
 
-	title, body := c.generatePRTitleAndBody(runID, commitSHA)
+	title, body, err := c.titleAndBody(commitSHA)
+	if err != nil {
+		return nil, err
+	}
 
 	data := map[string]interface{}{
 		"title": title,
@@ -145,6 +189,18 @@ func (c *Creator) preparePRData() (map[string]interface{}, error) {
 	return data, nil
 }
 
+// titleAndBody returns the title and body of the PR whose head commit is
+// commitSHA: those of generatePRTitleAndBody, unless pr_body_file or
+// pr_template gives the body.
+func (c *Creator) titleAndBody(commitSHA string) (string, string, error) {
+	title, body := c.generatePRTitleAndBody(os.Getenv("GITHUB_RUN_ID"), commitSHA)
+	if c.config.PRBody != "" || c.config.PRBodyTemplate == "" {
+		return title, body, nil
+	}
+	body, err := c.templateBody(commitSHA)
+	return title, body, err
+}
+
 // generatePRTitleAndBody creates default PR title and body if not specified.
 func (c *Creator) generatePRTitleAndBody(runID string, commitSHA string) (string, string) {
 	title := c.config.PRTitle
@@ -161,6 +217,12 @@ func (c *Creator) generatePRTitleAndBody(runID string, commitSHA string) (string
 	return title, body
 }
 
+// PullRequest returns the pull request HandlePRResponse created, found or,
+// in dry-run mode, would have created. Its URL is empty when there is none.
+func (c *Creator) PullRequest() PullRequest {
+	return c.pr
+}
+
 // HandlePRResponse processes the PR creation response and performs follow-up actions.
 func (c *Creator) HandlePRResponse(ctx context.Context, response PRResponse, sourceBranch string) error {
 	if response.DryRun {
@@ -176,6 +238,7 @@ func (c *Creator) HandlePRResponse(ctx context.Context, response PRResponse, sou
 
 // handleDryRunResponse handles the dry run response without making actual changes.
 func (c *Creator) handleDryRunResponse(response PRResponse) error {
+	c.pr = PullRequest{Number: response.Number, URL: response.HTMLURL}
 	fmt.Printf("\n[DRY RUN] Pull request would be created at: %s\n", response.HTMLURL)
 	fmt.Printf("No actual PR was created (dry run mode)\n")
 
@@ -184,6 +247,14 @@ func (c *Creator) handleDryRunResponse(response PRResponse) error {
 	fmt.Printf("  - Source branch: %s\n", c.config.PRBranch)
 	fmt.Printf("  - Target branch: %s\n", c.config.PRBase)
 
+	if c.config.PRBodyTemplate != "" && c.config.PRBody == "" {
+		path := c.config.PRBodyFile
+		if path == "" {
+			path = c.config.PRTemplate
+		}
+		fmt.Printf("  - Body: rendered from %s\n", path)
+	}
+
 	if len(c.config.PRLabels) > 0 {
 		fmt.Printf("  - Labels: %s\n", strings.Join(c.config.PRLabels, ", "))
 	}
@@ -196,10 +267,22 @@ func (c *Creator) handleDryRunResponse(response PRResponse) error {
 		fmt.Printf("  - Reviewers: %s\n", strings.Join(c.config.PRReviewers, ", "))
 	}
 
+	if len(c.config.PRTeamReviewers) > 0 {
+		fmt.Printf("  - Team reviewers: %s\n", strings.Join(c.config.PRTeamReviewers, ", "))
+	}
+
+	if c.config.PRCodeowners {
+		fmt.Printf("  - Reviewers from CODEOWNERS: Yes\n")
+	}
+
 	if len(c.config.PRAssignees) > 0 {
 		fmt.Printf("  - Assignees: %s\n", strings.Join(c.config.PRAssignees, ", "))
 	}
 
+	if c.config.PRWaitForChecks {
+		fmt.Printf("  - Would wait for checks: Yes\n")
+	}
+
 	if c.config.PRClosed {
 		fmt.Printf("  - Would be closed immediately: Yes\n")
 	}
@@ -246,9 +329,21 @@ func (c *Creator) handleSuccessfulPR(ctx context.Context, response PRResponse, s
 
 	fmt.Println("Done")
 	fmt.Printf("Pull request created: %s\n", response.HTMLURL)
+	c.pr = PullRequest{Number: response.Number, URL: response.HTMLURL, Action: PRActionCreated}
+	c.nodeID, c.author = response.NodeID, response.Author
 
 	if response.HasNumber {
-		if err := c.processExistingPR(ctx, response.Number); err != nil {
+		prNumber := response.Number
+		c.opened = prNumber
+		journal.FromContext(ctx).Record(fmt.Sprintf("opened pull request #%d", prNumber), func(ctx context.Context) error {
+			return c.closePullRequest(ctx, prNumber)
+		})
+
+		if err := c.processExistingPR(ctx, prNumber); err != nil {
+			return err
+		}
+		// The PR was just created from pr_branch.
+		if err := c.mergeOrAutoMerge(ctx, prNumber, c.config.PRBranch); err != nil {
 			return err
 		}
 	}
@@ -268,24 +363,140 @@ func (c *Creator) handleSuccessfulPR(ctx context.Context, response PRResponse, s
 func (c *Creator) handleExistingPR(ctx context.Context) error {
 	fmt.Println("[WARN] Pull request already exists")
 
-	endpoint := fmt.Sprintf("/pulls?head=%s&base=%s", c.config.PRBranch, c.config.PRBase)
+	// GitHub ignores a head filter without the owner, listing every open PR
+	// into the base instead.
+	head := c.config.PRBranch
+	if owner, _, ok := strings.Cut(c.client.Repo(), "/"); ok {
+		head = owner + ":" + head
+	}
+	endpoint := fmt.Sprintf("/pulls?head=%s&base=%s", head, c.config.PRBase)
 	prs, err := c.client.GetArray(ctx, endpoint)
 	if err != nil {
 		return err
 	}
 
-	if len(prs) > 0 {
-		if number, ok := prs[0]["number"].(float64); ok {
-			prNumber := int(number)
-			fmt.Printf("Found existing PR #%d\n", prNumber)
-			return c.processExistingPR(ctx, prNumber)
+	existing := findPRFromBranch(prs, c.config.PRBranch)
+	if existing == nil {
+		fmt.Printf("[WARN] No open pull request from %s to %s found\n", c.config.PRBranch, c.config.PRBase)
+		return nil
+	}
+	number, ok := existing["number"].(float64)
+	if !ok {
+		return nil
+	}
+
+	prNumber := int(number)
+	fmt.Printf("Found existing PR #%d\n", prNumber)
+	c.pr = PullRequest{Number: prNumber, Action: PRActionExisting}
+	c.pr.URL, _ = existing["html_url"].(string)
+	c.nodeID, _ = existing["node_id"].(string)
+	if user, ok := existing["user"].(map[string]any); ok {
+		c.author, _ = user["login"].(string)
+	}
+
+	if c.config.PRUpdateExisting {
+		if err := c.updatePullRequest(ctx, prNumber, existing); err != nil {
+			return err
 		}
+		c.pr.Action = PRActionUpdated
 	}
+	if err := c.processExistingPR(ctx, prNumber); err != nil {
+		return err
+	}
+	from, _ := existing["head"].(map[string]any)
+	ref, _ := from["ref"].(string)
+	return c.mergeOrAutoMerge(ctx, prNumber, ref)
+}
 
+// findPRFromBranch returns the first of prs, as listed by the API, whose head
+// is branch, or nil if there is none.
+func findPRFromBranch(prs []map[string]any, branch string) map[string]any {
+	for _, pr := range prs {
+		if head, ok := pr["head"].(map[string]any); ok && head["ref"] == branch {
+			return pr
+		}
+	}
+	return nil
+}
+
+// updatePullRequest brings an existing PR, as listed by the API, up to date
+// with the title, body and draft state this run would have created it with.
+// Its base needs no update: the PR was looked up by pr_base.
+func (c *Creator) updatePullRequest(ctx context.Context, prNumber int, existing map[string]any) error {
+	commitSHA, err := shared.CurrentCommitSHA(c.runner)
+	if err != nil {
+		return err
+	}
+	title, body, err := c.titleAndBody(commitSHA)
+	if err != nil {
+		return err
+	}
+
+	// Learn the previous values first, for rollback.
+	previous := map[string]string{}
+	previous["title"], _ = existing["title"].(string)
+	previous["body"], _ = existing["body"].(string)
+
+	err = c.applyToPR(
+		ctx,
+		fmt.Sprintf("  - [DRY RUN] Would update title and body of PR #%d... Skipped", prNumber),
+		fmt.Sprintf("Updating pull request #%d", prNumber),
+		"update PR",
+		fmt.Sprintf("/pulls/%d", prNumber),
+		c.client.Patch,
+		map[string]string{"title": title, "body": body},
+	)
+	if err != nil {
+		return err
+	}
+	journal.FromContext(ctx).Record(fmt.Sprintf("updated pull request #%d", prNumber), func(ctx context.Context) error {
+		return c.applyToPR(ctx, "", fmt.Sprintf("Restoring pull request #%d", prNumber), "restore PR",
+			fmt.Sprintf("/pulls/%d", prNumber), c.client.Patch, previous)
+	})
+
+	draft, ok := existing["draft"].(bool)
+	nodeID, _ := existing["node_id"].(string)
+	if !ok || draft == c.config.PRDraft || nodeID == "" {
+		return nil
+	}
+	if err := c.setDraft(ctx, prNumber, nodeID, c.config.PRDraft); err != nil {
+		return err
+	}
+	journal.FromContext(ctx).Record(fmt.Sprintf("changed draft state of pull request #%d", prNumber), func(ctx context.Context) error {
+		return c.setDraft(ctx, prNumber, nodeID, draft)
+	})
+	return nil
+}
+
+// Mutations that change the draft state of a PR, which the REST API cannot.
+const (
+	convertToDraftMutation = `mutation($id: ID!) { convertPullRequestToDraft(input: {pullRequestId: $id}) { pullRequest { isDraft } } }`
+	readyForReviewMutation = `mutation($id: ID!) { markPullRequestReadyForReview(input: {pullRequestId: $id}) { pullRequest { isDraft } } }`
+)
+
+// setDraft converts the PR with GraphQL node ID nodeID to a draft, or marks
+// it ready for review.
+func (c *Creator) setDraft(ctx context.Context, prNumber int, nodeID string, draft bool) error {
+	progress, mutation := fmt.Sprintf("Marking PR #%d ready for review", prNumber), readyForReviewMutation
+	if draft {
+		progress, mutation = fmt.Sprintf("Converting PR #%d to draft", prNumber), convertToDraftMutation
+	}
+	if c.config.PRDryRun {
+		fmt.Printf("  - [DRY RUN] Would %s... Skipped\n", strings.ToLower(progress[:1])+progress[1:])
+		return nil
+	}
+
+	fmt.Printf("  - %s... ", progress)
+	if _, err := c.client.GraphQL(ctx, mutation, map[string]interface{}{"id": nodeID}); err != nil {
+		fmt.Println("FAILED")
+		return errors.NewAPIErrorFrom("set draft state", err)
+	}
+	fmt.Println("Done")
 	return nil
 }
 
 // processExistingPR applies operations like adding labels, reviewers, assignees, or closing to an existing PR.
+// With pr_wait_for_checks, it waits for the checks to pass before closing.
 func (c *Creator) processExistingPR(ctx context.Context, prNumber int) error {
 	if len(c.config.PRLabels) > 0 {
 		if err := c.addLabelsToIssue(ctx, prNumber); err != nil {
@@ -293,7 +504,7 @@ func (c *Creator) processExistingPR(ctx context.Context, prNumber int) error {
 		}
 	}
 
-	if len(c.config.PRReviewers) > 0 {
+	if len(c.config.PRReviewers) > 0 || len(c.config.PRTeamReviewers) > 0 || c.config.PRCodeowners {
 		if err := c.requestReviewers(ctx, prNumber); err != nil {
 			return err
 		}
@@ -305,6 +516,12 @@ func (c *Creator) processExistingPR(ctx context.Context, prNumber int) error {
 		}
 	}
 
+	if c.config.PRWaitForChecks {
+		if err := c.waitForChecks(ctx, prNumber); err != nil {
+			return err
+		}
+	}
+
 	if c.config.PRClosed {
 		if err := c.closePullRequest(ctx, prNumber); err != nil {
 			return err
@@ -352,7 +569,16 @@ func (c *Creator) applyToPR(
 
 // addLabelsToIssue adds labels to an issue/PR.
 func (c *Creator) addLabelsToIssue(ctx context.Context, prNumber int) error {
-	return c.applyToPR(
+	// For rollback, learn which labels an existing PR already carries so
+	// that only the ones added here are removed again.
+	j := journal.FromContext(ctx)
+	var existing []string
+	known := prNumber == c.opened
+	if j != nil && !known && !c.config.PRDryRun {
+		existing, known = c.issueLabels(ctx, prNumber)
+	}
+
+	err := c.applyToPR(
 		ctx,
 		fmt.Sprintf("  - [DRY RUN] Would add labels %v to PR #%d... Skipped", c.config.PRLabels, prNumber),
 		fmt.Sprintf("Adding labels to PR #%d", prNumber),
@@ -361,21 +587,151 @@ func (c *Creator) addLabelsToIssue(ctx context.Context, prNumber int) error {
 		c.client.Post,
 		map[string]interface{}{"labels": c.config.PRLabels},
 	)
+	if err != nil || j == nil || c.config.PRDryRun {
+		return err
+	}
+
+	var added []string
+	for _, label := range c.config.PRLabels {
+		if !slices.Contains(existing, label) {
+			added = append(added, label)
+		}
+	}
+	action := fmt.Sprintf("added labels %s to PR #%d", strings.Join(added, ", "), prNumber)
+	switch {
+	case !known:
+		j.Record(action, nil)
+	case len(added) > 0:
+		j.Record(action, func(ctx context.Context) error {
+			return c.removeLabels(ctx, prNumber, added)
+		})
+	}
+	return nil
+}
+
+// issueLabels returns the names of the labels on an issue/PR. ok is false
+// when they could not be read.
+func (c *Creator) issueLabels(ctx context.Context, prNumber int) ([]string, bool) {
+	labels, err := c.client.GetArray(ctx, fmt.Sprintf("/issues/%d/labels", prNumber))
+	if err != nil {
+		return nil, false
+	}
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if name, ok := label["name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, true
+}
+
+// removeLabels removes labels from an issue/PR.
+func (c *Creator) removeLabels(ctx context.Context, prNumber int, labels []string) error {
+	for _, label := range labels {
+		if err := c.client.Delete(ctx, fmt.Sprintf("/issues/%d/labels/%s", prNumber, url.PathEscape(label))); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// requestReviewers requests reviewers for a pull request.
+// requestReviewers requests reviews for a pull request from pr_reviewers,
+// pr_team_reviewers and, with pr_reviewers_from_codeowners, the owners of the
+// changed files. Should GitHub reject the request, the users who cannot
+// review, such as unknown ones, are left out with a warning and the others
+// requested again.
 func (c *Creator) requestReviewers(ctx context.Context, prNumber int) error {
+	users, teams := c.config.PRReviewers, c.config.PRTeamReviewers
+	if c.config.PRCodeowners {
+		ownerUsers, ownerTeams := c.codeownerReviewers()
+		users = append(slices.Clone(users), ownerUsers...)
+		teams = append(slices.Clone(teams), ownerTeams...)
+	}
+	users, teams = c.reviewers(users, teams)
+	if len(users) == 0 && len(teams) == 0 {
+		fmt.Printf("  - No reviewers to request for PR #%d\n", prNumber)
+		return nil
+	}
+
+	err := c.postReviewers(ctx, prNumber, users, teams)
+	if err == nil || c.config.PRDryRun {
+		return err
+	}
+	reviewable := c.reviewableUsers(ctx, users)
+	if len(reviewable) == len(users) {
+		return err
+	}
+	if len(reviewable) == 0 && len(teams) == 0 {
+		return nil
+	}
+	return c.postReviewers(ctx, prNumber, reviewable, teams)
+}
+
+// postReviewers requests reviews from users and teams.
+func (c *Creator) postReviewers(ctx context.Context, prNumber int, users, teams []string) error {
+	payload := map[string]interface{}{}
+	requested := fmt.Sprint(users)
+	if len(users) > 0 {
+		payload["reviewers"] = users
+	}
+	if len(teams) > 0 {
+		payload["team_reviewers"] = teams
+		requested += fmt.Sprintf(" and teams %v", teams)
+	}
 	return c.applyToPR(
 		ctx,
-		fmt.Sprintf("  - [DRY RUN] Would request reviewers %v for PR #%d... Skipped", c.config.PRReviewers, prNumber),
+		fmt.Sprintf("  - [DRY RUN] Would request reviewers %s for PR #%d... Skipped", requested, prNumber),
 		fmt.Sprintf("Requesting reviewers for PR #%d", prNumber),
 		"request reviewers",
 		fmt.Sprintf("/pulls/%d/requested_reviewers", prNumber),
 		c.client.Post,
-		map[string]interface{}{"reviewers": c.config.PRReviewers},
+		payload,
 	)
 }
 
+// reviewers returns users and teams without duplicates, compared ignoring
+// case as GitHub does, the "@" of users removed and teams given by their
+// slug. The author of the PR is left out: GitHub refuses to request their
+// review.
+func (c *Creator) reviewers(users, teams []string) ([]string, []string) {
+	seen := map[string]bool{strings.ToLower(c.author): c.author != ""}
+	var outUsers, outTeams []string
+	for _, user := range users {
+		user = strings.TrimPrefix(user, "@")
+		if !seen[strings.ToLower(user)] {
+			seen[strings.ToLower(user)] = true
+			outUsers = append(outUsers, user)
+		}
+	}
+	for _, team := range teams {
+		// "@org/team" or "org/team" to the slug "team"
+		team = team[strings.LastIndex(team, "/")+1:]
+		team = strings.TrimPrefix(team, "@")
+		if !seen["team:"+strings.ToLower(team)] {
+			seen["team:"+strings.ToLower(team)] = true
+			outTeams = append(outTeams, team)
+		}
+	}
+	return outUsers, outTeams
+}
+
+// reviewableUsers returns the users that have access to the repository,
+// warning about the others. A user whose access cannot be read is kept.
+func (c *Creator) reviewableUsers(ctx context.Context, users []string) []string {
+	var reviewable []string
+	for _, user := range users {
+		resp, err := c.client.Get(ctx, fmt.Sprintf("/collaborators/%s/permission", url.PathEscape(user)))
+		if err == nil {
+			if msg, _ := resp["message"].(string); msg != "" {
+				fmt.Printf("  - [WARN] Cannot request a review from %s: %s\n", user, msg)
+				continue
+			}
+		}
+		reviewable = append(reviewable, user)
+	}
+	return reviewable
+}
+
 // addAssignees adds assignees to a pull request.
 func (c *Creator) addAssignees(ctx context.Context, prNumber int) error {
 	return c.applyToPR(
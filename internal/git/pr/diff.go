@@ -3,6 +3,8 @@ package pr
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
@@ -12,6 +14,9 @@ import (
 type DiffChecker struct {
 	config *config.GitConfig
 	runner gitcmd.Runner
+
+	// changes are the files changed between the branches, once checked.
+	changes []config.FileChange
 }
 
 // NewDiffChecker creates a new DiffChecker instance.
@@ -40,6 +45,55 @@ func (dc *DiffChecker) CheckBranchDifferences() error {
 	return dc.displayChangedFiles()
 }
 
+// Changes returns the files CheckBranchDifferences found changed between the
+// PR base branch and the source branch.
+func (dc *DiffChecker) Changes() []config.FileChange {
+	return dc.changes
+}
+
+// StagedPaths returns the paths of the staged changes matched by pathspecs,
+// or of all of them without pathspecs, read from their name-status listing.
+func (dc *DiffChecker) StagedPaths(pathspecs ...string) ([]string, error) {
+	out, err := dc.runner.Output(gitcmd.CmdGit, gitcmd.DiffCachedNameStatusArgs(pathspecs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list staged changes: %w", err)
+	}
+	return parseNameStatus(string(out)), nil
+}
+
+// parseNameStatus returns the paths of NUL-terminated "git diff
+// --name-status --no-renames" output, a status followed by the path for each
+// change.
+func parseNameStatus(out string) []string {
+	fields := strings.Split(out, "\x00")
+	var paths []string
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] != "" {
+			paths = append(paths, fields[i+1])
+		}
+	}
+	return paths
+}
+
+// parseNameStatusLines returns the changes listed by "git diff --name-status":
+// a status, with a similarity score for renames and copies, and a path, or
+// the old and the new path, per line. Paths git quoted are unquoted.
+func parseNameStatusLines(out string) []config.FileChange {
+	var changes []config.FileChange
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 || fields[0] == "" {
+			continue
+		}
+		path := fields[len(fields)-1]
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		changes = append(changes, config.FileChange{Status: fields[0][:1], Path: path})
+	}
+	return changes
+}
+
 // displayChangedFiles shows the changed files between branches and validates if changes exist.
 func (dc *DiffChecker) displayChangedFiles() error {
 	// Check the changed files
@@ -60,6 +114,7 @@ func (dc *DiffChecker) displayChangedFiles() error {
 	}
 
 	fmt.Printf("%s\n", string(filesOutput))
+	dc.changes = parseNameStatusLines(string(filesOutput))
 
 	// Display the PR URL for manual creation if needed
 	dc.displayPRURL()
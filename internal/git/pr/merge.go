@@ -0,0 +1,123 @@
+package pr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+)
+
+// Mutations that turn auto-merge on and off. expectedHeadOid keeps GitHub
+// from merging commits pushed after the ones this run checked.
+const (
+	enableAutoMergeMutation = `mutation($id: ID!, $method: PullRequestMergeMethod!, $sha: GitObjectID) {
+  enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method, expectedHeadOid: $sha}) { pullRequest { number } }
+}`
+	disableAutoMergeMutation = `mutation($id: ID!) { disablePullRequestAutoMerge(input: {pullRequestId: $id}) { pullRequest { number } } }`
+)
+
+// mergeOrAutoMerge merges the PR with pr_merge_now, or enables auto-merge
+// with pr_auto_merge. head is the branch the PR is from, as created or listed:
+// only a PR from pr_branch is merged. Both are also guarded by the head commit
+// of this run: a PR whose branch moved since is not merged.
+func (c *Creator) mergeOrAutoMerge(ctx context.Context, prNumber int, head string) error {
+	if c.config.PRMergeNow == "" && c.config.PRAutoMerge == "" {
+		return nil
+	}
+	if head != c.config.PRBranch {
+		return errors.Permanent(errors.NewAPIError("merge PR",
+			fmt.Sprintf("PR #%d is from %q, not %q; refusing to merge it", prNumber, head, c.config.PRBranch)))
+	}
+
+	switch {
+	case c.config.PRMergeNow != "":
+		return c.mergeNow(ctx, prNumber)
+	case c.config.PRAutoMerge != "":
+		return c.enableAutoMerge(ctx, prNumber)
+	}
+	return nil
+}
+
+// mergeNow merges the PR through the REST merge endpoint.
+func (c *Creator) mergeNow(ctx context.Context, prNumber int) error {
+	headSHA, err := shared.CurrentCommitSHA(c.runner)
+	if err != nil {
+		return err
+	}
+
+	err = c.applyToPR(
+		ctx,
+		fmt.Sprintf("  - [DRY RUN] Would %s PR #%d... Skipped", c.config.PRMergeNow, prNumber),
+		fmt.Sprintf("Merging PR #%d (%s)", prNumber, c.config.PRMergeNow),
+		"merge PR",
+		fmt.Sprintf("/pulls/%d/merge", prNumber),
+		c.client.Put,
+		map[string]string{"merge_method": c.config.PRMergeNow, "sha": headSHA},
+	)
+	if err != nil || c.config.PRDryRun {
+		return err
+	}
+	// A merge cannot be undone.
+	journal.FromContext(ctx).Record(fmt.Sprintf("merged pull request #%d", prNumber), nil)
+	return nil
+}
+
+// enableAutoMerge enables auto-merge on the PR through GraphQL, the only API
+// that offers it, so that GitHub merges it once the required checks pass.
+func (c *Creator) enableAutoMerge(ctx context.Context, prNumber int) error {
+	method := c.config.PRAutoMerge
+	if c.config.PRDryRun {
+		fmt.Printf("  - [DRY RUN] Would enable auto-merge (%s) for PR #%d... Skipped\n", method, prNumber)
+		return nil
+	}
+
+	headSHA, err := shared.CurrentCommitSHA(c.runner)
+	if err != nil {
+		return err
+	}
+	nodeID, err := c.pullRequestNodeID(ctx, prNumber)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("  - Enabling auto-merge (%s) for PR #%d... ", method, prNumber)
+	variables := map[string]interface{}{"id": nodeID, "method": strings.ToUpper(method), "sha": headSHA}
+	if _, err := c.client.GraphQL(ctx, enableAutoMergeMutation, variables); err != nil {
+		fmt.Println("FAILED")
+		return errors.NewAPIErrorFrom("enable auto-merge", err)
+	}
+	fmt.Println("Done")
+
+	journal.FromContext(ctx).Record(fmt.Sprintf("enabled auto-merge for pull request #%d", prNumber), func(ctx context.Context) error {
+		fmt.Printf("  - Disabling auto-merge for PR #%d... ", prNumber)
+		if _, err := c.client.GraphQL(ctx, disableAutoMergeMutation, map[string]interface{}{"id": nodeID}); err != nil {
+			fmt.Println("FAILED")
+			return errors.NewAPIErrorFrom("disable auto-merge", err)
+		}
+		fmt.Println("Done")
+		return nil
+	})
+	return nil
+}
+
+// pullRequestNodeID returns the GraphQL ID of the PR, read from the API when
+// the response that created or found it did not carry it.
+func (c *Creator) pullRequestNodeID(ctx context.Context, prNumber int) (string, error) {
+	if c.nodeID != "" {
+		return c.nodeID, nil
+	}
+	resp, err := c.client.Get(ctx, fmt.Sprintf("/pulls/%d", prNumber))
+	if err != nil {
+		return "", errors.NewAPIErrorFrom("get PR", err)
+	}
+	nodeID, _ := resp["node_id"].(string)
+	if nodeID == "" {
+		msg, _ := resp["message"].(string)
+		return "", errors.NewAPIError("get PR", fmt.Sprintf("no node ID for PR #%d: %s", prNumber, msg))
+	}
+	c.nodeID = nodeID
+	return nodeID, nil
+}
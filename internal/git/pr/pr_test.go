@@ -2,6 +2,7 @@ package pr
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"reflect"
 	"strings"
@@ -168,8 +169,9 @@ func TestParsePRResponse(t *testing.T) {
 	}{
 		{
 			name: "success response with number",
-			in:   map[string]any{"html_url": "https://example.com/pr/7", "number": float64(7)},
-			want: PRResponse{HTMLURL: "https://example.com/pr/7", Number: 7, HasNumber: true},
+			in: map[string]any{"html_url": "https://example.com/pr/7", "number": float64(7), "node_id": "PR_7",
+				"user": map[string]any{"login": "octocat"}},
+			want: PRResponse{HTMLURL: "https://example.com/pr/7", Number: 7, HasNumber: true, NodeID: "PR_7", Author: "octocat"},
 		},
 		{
 			name: "number zero is still present",
@@ -205,6 +207,8 @@ func TestParsePRResponse(t *testing.T) {
 			if got.HTMLURL != tt.want.HTMLURL ||
 				got.Number != tt.want.Number ||
 				got.HasNumber != tt.want.HasNumber ||
+				got.NodeID != tt.want.NodeID ||
+				got.Author != tt.want.Author ||
 				got.DryRun != tt.want.DryRun ||
 				got.Message != tt.want.Message ||
 				!reflect.DeepEqual(got.Errors, tt.want.Errors) {
@@ -647,3 +651,88 @@ func BenchmarkGeneratePRTitleAndBody(b *testing.B) {
 		c.generatePRTitleAndBody("123", "abc123")
 	}
 }
+
+// A body over GitHub's limit is rendered again with shorter lists.
+func TestRenderPRBody_TruncatesLists(t *testing.T) {
+	data := config.PRTemplateData{}
+	for i := range 5000 {
+		path := fmt.Sprintf("dir/file-%04d.txt", i)
+		data.Changes = append(data.Changes, config.FileChange{Status: "M", Path: path})
+		data.ChangedFiles = append(data.ChangedFiles, path)
+		data.Stats = append(data.Stats, config.FileStat{Path: path, Added: 1})
+	}
+	text := "{{ range .Changes }}{{ .Path }}\n{{ end }}{{ .DiffStat }}{{ if .Truncated }}\n(truncated){{ end }}"
+
+	body, err := renderPRBody("pr_template", text, data)
+	if err != nil {
+		t.Fatalf("renderPRBody() error = %v", err)
+	}
+	if len(body) > maxPRBodyLength {
+		t.Errorf("len(body) = %d, want at most %d", len(body), maxPRBodyLength)
+	}
+	if !strings.HasSuffix(body, "more files_ | | |\n(truncated)") {
+		t.Errorf("body ends with %q, want the omitted files and the truncated flag", body[len(body)-60:])
+	}
+}
+
+// A body that does not fit even without lists is cut.
+func TestRenderPRBody_CutsLongText(t *testing.T) {
+	text := strings.Repeat("x", maxPRBodyLength+10)
+
+	body, err := renderPRBody("pr_body_file", text, config.PRTemplateData{})
+	if err != nil {
+		t.Fatalf("renderPRBody() error = %v", err)
+	}
+	if len(body) != maxPRBodyLength || !strings.HasSuffix(body, truncatedBodyNote) {
+		t.Errorf("len(body) = %d, want %d ending with the truncation note", len(body), maxPRBodyLength)
+	}
+}
+
+func TestCodeownersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*", "a/b/c.go", true},
+		{"*.js", "web/app.js", true},
+		{"*.js", "web/app.jsx", false},
+		{"/build/logs/", "build/logs/x/y.log", true},
+		{"/build/logs/", "src/build/logs/y.log", false},
+		{"apps/", "src/apps/main.go", true},
+		{"apps/", "apps", false},
+		{"docs/*", "docs/setup.md", true},
+		{"docs/*", "docs/guide/setup.md", false},
+		{"docs/**", "docs/guide/setup.md", true},
+		{"**/logs", "a/b/logs/x.log", true},
+		{"/script", "script/run.sh", true},
+		{"/script", "lib/script", false},
+		{"?.txt", "a.txt", true},
+		{"?.txt", "ab.txt", false},
+	}
+	for _, tt := range tests {
+		re, err := codeownersPattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("codeownersPattern(%q) error = %v", tt.pattern, err)
+		}
+		if got := re.MatchString(tt.path); got != tt.want {
+			t.Errorf("pattern %q matches %q = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+// The last matching rule wins, even one without owners.
+func TestMatchCodeowners_LastRuleWins(t *testing.T) {
+	rules := parseCodeowners("* @all\n\\#notes @notes # comment\n/vendor/\n")
+
+	for path, want := range map[string][]string{
+		"main.go":          {"@all"},
+		"#notes":           {"@notes"},
+		"vendor/lib/x.go":  {},
+		"docs/vendor/x.go": {"@all"},
+	} {
+		if got := matchCodeowners(rules, path); !reflect.DeepEqual(got, want) {
+			t.Errorf("matchCodeowners(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package pr
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
@@ -14,6 +15,11 @@ func key(args []string) string {
 	return gitcmd.Call{Name: gitcmd.CmdGit, Args: args}.Key()
 }
 
+// commitKey renders the commit of message as made without run_hooks.
+func commitKey(message string) string {
+	return key(append(gitcmd.CommitArgs(message), gitcmd.OptNoVerify))
+}
+
 func prConfig() *config.GitConfig {
 	return &config.GitConfig{
 		CommitMessage: "chore: pr commit",
@@ -56,7 +62,8 @@ func TestPrepareSourceBranch_CheckoutFailure(t *testing.T) {
 func TestPrepareSourceBranch_CreatesAutoBranch(t *testing.T) {
 	cfg := prConfig()
 	cfg.AutoBranch = true
-	f := gitcmd.NewFakeRunner()
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DiffCachedNamesArgs()), gitcmd.FakeResult{Stdout: "a.txt\x00"})
 	bm := NewBranchManagerWithRunner(cfg, f)
 
 	got, err := bm.PrepareSourceBranch()
@@ -73,11 +80,14 @@ func TestPrepareSourceBranch_CreatesAutoBranch(t *testing.T) {
 
 	wantSeq := []string{
 		key(gitcmd.CheckoutNewBranchArgs(got)),
-		key(gitcmd.AddArgs(".")),
-		key(gitcmd.CommitArgs(cfg.CommitMessage)),
+		key(gitcmd.AddPathspecsArgs([]string{"."})),
+		commitKey(cfg.CommitMessage),
 		key(gitcmd.PushUpstreamArgs(gitcmd.RefOrigin, got)),
 	}
 	assertSequence(t, f.Keys(), wantSeq)
+	if staged := bm.StagedFiles(); !reflect.DeepEqual(staged, []string{"a.txt"}) {
+		t.Errorf("StagedFiles() = %v, want [a.txt]", staged)
+	}
 }
 
 func TestPrepareSourceBranch_AutoBranchCreateFailure(t *testing.T) {
@@ -162,6 +172,18 @@ func TestCheckBranchDifferences_ReportsChangedFiles(t *testing.T) {
 		key(gitcmd.FetchArgs(gitcmd.RefOrigin, "feature")),
 		key(gitcmd.DiffNameStatusArgs("origin/main", "origin/feature")),
 	})
+	want := []config.FileChange{{Status: "M", Path: "a.txt"}, {Status: "A", Path: "b.txt"}}
+	if got := dc.Changes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %v, want %v", got, want)
+	}
+}
+
+func TestParseNameStatusLines_RenamesAndQuotedPaths(t *testing.T) {
+	out := "R087\told.txt\tnew.txt\nD\t\"caf\\303\\251.txt\"\n"
+	want := []config.FileChange{{Status: "R", Path: "new.txt"}, {Status: "D", Path: "café.txt"}}
+	if got := parseNameStatusLines(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameStatusLines() = %v, want %v", got, want)
+	}
 }
 
 // With no diff and skip_if_empty off, an empty PR is an error.
@@ -225,3 +247,19 @@ func assertSequence(t *testing.T, got, want []string) {
 		t.Errorf("command sequence\n got: %v\nwant (in order): %v", got, want)
 	}
 }
+
+// Deleted, added and modified paths are all listed, unusual names unquoted.
+func TestStagedPaths_ParsesNameStatus(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DiffCachedNameStatusArgs()), gitcmd.FakeResult{
+			Stdout: "M\x00docs/a.md\x00D\x00old file.go\x00A\x00svc/new.go\x00"})
+
+	got, err := NewDiffCheckerWithRunner(&config.GitConfig{}, f).StagedPaths()
+	if err != nil {
+		t.Fatalf("StagedPaths() error = %v, want nil", err)
+	}
+	want := []string{"docs/a.md", "old file.go", "svc/new.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("StagedPaths() = %v, want %v", got, want)
+	}
+}
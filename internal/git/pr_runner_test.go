@@ -2,6 +2,9 @@ package git
 
 import (
 	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
@@ -85,11 +88,11 @@ func TestHandlePullRequestFlow_CommitsBeforeCreatingPR(t *testing.T) {
 
 	// An empty diff aborts before the API call, which is enough to assert that
 	// the commit happened first.
-	_ = handlePullRequestFlow(context.Background(), f, cfg, result)
+	_ = handlePullRequestFlow(context.Background(), f, cfg, result, "")
 
 	assertSequence(t, f.Keys(), []string{
-		key(gitcmd.AddArgs(".")),
-		key(gitcmd.CommitArgs(cfg.CommitMessage)),
+		key(gitcmd.AddPathspecsArgs([]string{"."})),
+		commitKey(cfg.CommitMessage),
 		key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch)),
 	})
 }
@@ -101,10 +104,10 @@ func TestHandlePullRequestFlow_DryRunSkipsCommit(t *testing.T) {
 		Stub(key(gitcmd.DiffNameStatusArgs("origin/main", "origin/feature")),
 			gitcmd.FakeResult{Stdout: "M\ta.txt\n"})
 
-	if err := handlePullRequestFlow(context.Background(), f, cfg, output.NewResult()); err != nil {
+	if err := handlePullRequestFlow(context.Background(), f, cfg, output.NewResult(), ""); err != nil {
 		t.Fatalf("handlePullRequestFlow() error = %v, want nil", err)
 	}
-	if f.Ran(key(gitcmd.CommitArgs(cfg.CommitMessage))) {
+	if f.Ran(commitKey(cfg.CommitMessage)) {
 		t.Error("a commit was issued in dry-run mode, want none")
 	}
 	if f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch))) {
@@ -122,7 +125,7 @@ func TestHandlePullRequestFlow_AutoBranchSkipsDirectCommit(t *testing.T) {
 			gitcmd.FakeResult{Stdout: "M\ta.txt\n"})
 	// The diff key depends on the generated branch name, so tolerate the empty
 	// diff and assert only on the branch-creation shape.
-	_ = handlePullRequestFlow(context.Background(), f, cfg, output.NewResult())
+	_ = handlePullRequestFlow(context.Background(), f, cfg, output.NewResult(), "")
 
 	if !f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch))) {
 		// Correct: the direct-commit push to the configured branch must not happen.
@@ -139,7 +142,150 @@ func TestRunGitCommit_UsesExecRunner(t *testing.T) {
 	cfg.PRBranch = "" // fails validation before any command executes
 	cfg.GitHubToken = "token"
 
-	if err := RunGitCommit(context.Background(), cfg, output.NewResult()); err == nil {
+	if err := RunGitCommit(context.Background(), cfg, output.NewResult(), nil); err == nil {
 		t.Fatal("RunGitCommit() error = nil, want the validation failure")
 	}
 }
+
+// splitIndex simulates the index for pr_split_by: adding literal pathspecs
+// stages those paths, adding the pathspecs of adds stages the files listed for
+// them, and a commit or reset empties the index. Every branch differs from
+// the base.
+func splitIndex(f *gitcmd.FakeRunner, nameStatus string, adds map[string][]string) {
+	var index []string
+	f.Handler = func(name string, args []string) (string, error) {
+		k := key(args)
+		switch {
+		case k == key(gitcmd.DiffCachedNameStatusArgs()):
+			return nameStatus, nil
+		case k == key(gitcmd.DiffCachedNamesArgs()):
+			return strings.Join(index, "\x00"), nil
+		case k == key(gitcmd.ResetIndexArgs()), args[0] == gitcmd.SubCmdCommit:
+			index = nil
+		case k == key(gitcmd.RevParseArgs("HEAD")):
+			return "cafebabe\n", nil
+		case args[0] == gitcmd.SubCmdDiff && strings.Contains(strings.Join(args, " "), ".."):
+			return "M\tfile\n", nil
+		case adds[k] != nil:
+			index = append(index, adds[k]...)
+		case args[0] == gitcmd.SubCmdAdd:
+			for _, arg := range args[2:] {
+				if path, ok := strings.CutPrefix(arg, gitcmd.PathspecLiteral); ok {
+					index = append(index, path)
+				}
+			}
+		}
+		return "", nil
+	}
+}
+
+// splitPullRequests decodes the pull_requests output.
+func splitPullRequests(t *testing.T, result *output.Result) []splitPullRequest {
+	t.Helper()
+	var prs []splitPullRequest
+	if err := json.Unmarshal([]byte(result.Get(output.KeyPullRequests)), &prs); err != nil {
+		t.Fatalf("pull_requests output %q: %v", result.Get(output.KeyPullRequests), err)
+	}
+	return prs
+}
+
+// By directory, one branch and PR is made per top-level directory, in the
+// order the changes are listed, and the branch is checked out after each.
+func TestHandlePullRequestFlow_SplitByDirectory(t *testing.T) {
+	cfg := prDryRunConfig()
+	cfg.AutoBranch = true
+	cfg.PRSplitBy = config.PRSplitDirectory
+	cfg.CommitMessage = "chore: bump {{ join .ChangedFiles \", \" }}"
+	f := gitcmd.NewFakeRunner()
+	splitIndex(f, "M\x00docs/a.md\x00D\x00api/old.go\x00M\x00docs/b.md\x00M\x00go.mod\x00", nil)
+	result := output.NewResult()
+
+	if err := handlePullRequestFlow(context.Background(), f, cfg, result, ""); err != nil {
+		t.Fatalf("handlePullRequestFlow() error = %v, want nil", err)
+	}
+
+	checkout := key(gitcmd.CheckoutArgs(cfg.Branch))
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.AddPathspecsArgs([]string{"."})),
+		key(gitcmd.DiffCachedNameStatusArgs()),
+		key(gitcmd.ResetIndexArgs()),
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)docs/a.md", ":(literal)docs/b.md"})),
+		commitKey("chore: bump docs/a.md, docs/b.md"),
+		checkout,
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)api/old.go"})),
+		commitKey("chore: bump api/old.go"),
+		checkout,
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)go.mod"})),
+		commitKey("chore: bump go.mod"),
+		checkout,
+	})
+
+	prs := splitPullRequests(t, result)
+	if len(prs) != 3 {
+		t.Fatalf("pull_requests = %+v, want 3 entries", prs)
+	}
+	wantSuffixes := []string{"-dir-docs", "-dir-api", "-root"}
+	wantPaths := [][]string{{"docs/a.md", "docs/b.md"}, {"api/old.go"}, {"go.mod"}}
+	for i, p := range prs {
+		if !strings.HasPrefix(p.Branch, "update-files-") || !strings.HasSuffix(p.Branch, wantSuffixes[i]) {
+			t.Errorf("pull_requests[%d].branch = %q, want an auto branch ending in %q", i, p.Branch, wantSuffixes[i])
+		}
+		if !reflect.DeepEqual(p.Paths, wantPaths[i]) {
+			t.Errorf("pull_requests[%d].paths = %v, want %v", i, p.Paths, wantPaths[i])
+		}
+		if !f.Ran(key(gitcmd.PushUpstreamArgs(gitcmd.RefOrigin, p.Branch))) {
+			t.Errorf("branch %s was not pushed", p.Branch)
+		}
+		if p.PRURL == "" {
+			t.Errorf("pull_requests[%d].pr_url is empty, want the dry-run URL", i)
+		}
+	}
+	if got, want := result.Get(output.KeyStagedFiles), `["docs/a.md","docs/b.md","api/old.go","go.mod"]`; got != want {
+		t.Errorf("staged_files output = %q, want %q", got, want)
+	}
+}
+
+// By group, each commit_groups entry gets a PR with its message, and the
+// unmatched changes a last one.
+func TestHandlePullRequestFlow_SplitByGroup(t *testing.T) {
+	cfg := prDryRunConfig()
+	cfg.AutoBranch = true
+	cfg.PRSplitBy = config.PRSplitGroup
+	cfg.CommitGroupSpec = "- paths: docs/\n  message: 'docs: update'\n- paths: vendor/\n"
+	f := gitcmd.NewFakeRunner()
+	groupIndex(f, []string{"docs/a.md", "main.go"})
+	result := output.NewResult()
+
+	if err := handlePullRequestFlow(context.Background(), f, cfg, result, ""); err != nil {
+		t.Fatalf("handlePullRequestFlow() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)docs/a.md"})),
+		commitKey("docs: update"),
+		key(gitcmd.CheckoutArgs(cfg.Branch)),
+		key(gitcmd.AddPathspecsArgs([]string{"."})),
+		commitKey(cfg.CommitMessage),
+	})
+	prs := splitPullRequests(t, result)
+	if len(prs) != 2 || !strings.HasSuffix(prs[0].Branch, "-group-1") || !strings.HasSuffix(prs[1].Branch, "-rest") {
+		t.Errorf("pull_requests = %+v, want group-1 and rest", prs)
+	}
+}
+
+// Nothing to split fails like an empty PR, unless skip_if_empty is set.
+func TestHandlePullRequestFlow_SplitNothingToCommit(t *testing.T) {
+	cfg := prDryRunConfig()
+	cfg.AutoBranch = true
+	cfg.PRSplitBy = config.PRSplitDirectory
+	f := gitcmd.NewFakeRunner()
+	splitIndex(f, "", nil)
+
+	if err := handlePullRequestFlow(context.Background(), f, cfg, output.NewResult(), ""); err == nil {
+		t.Fatal("handlePullRequestFlow() error = nil, want no changes to fail")
+	}
+	cfg.SkipIfEmpty = true
+	if err := handlePullRequestFlow(context.Background(), f, cfg, output.NewResult(), ""); err != nil {
+		t.Fatalf("handlePullRequestFlow() error = %v, want nil with skip_if_empty", err)
+	}
+}
@@ -0,0 +1,195 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/pr"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// prSplit is the part of the changes that goes in one pull request of
+// pr_split_by.
+type prSplit struct {
+	name      string   // directory or commit_groups entry, for the log
+	suffix    string   // appended to the auto branch name
+	pathspecs []string // stage the part
+	excludes  []string
+	message   string // commit message template; "" for commit_message
+}
+
+// splitPullRequest is an entry of the pull_requests output.
+type splitPullRequest struct {
+	Branch   string   `json:"branch"`
+	PRNumber int      `json:"pr_number"`
+	PRURL    string   `json:"pr_url"`
+	Paths    []string `json:"paths"`
+}
+
+// branchSuffixUnsafe matches the runs of characters left out of an auto
+// branch suffix.
+var branchSuffixUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// createSplitPullRequests opens one pull request per part of the changes
+// that pr_split_by selects, each from its own auto branch created from the
+// branch. Every pull request gets the same labels, reviewers and other
+// settings; the templates of cfg, which is not rendered yet, are rendered
+// with the files of each. The branch is checked out again at the end.
+func createSplitPullRequests(ctx context.Context, r gitcmd.Runner, cfg *config.GitConfig, result *output.Result) error {
+	fmt.Println("\nCreating Pull Requests:")
+
+	splits, err := planSplits(r, cfg)
+	if err != nil {
+		return err
+	}
+
+	base := pr.AutoBranchName()
+	prs := []splitPullRequest{}
+	var staged []string
+	for i, split := range splits {
+		fmt.Printf("\nPull Request %d/%d (%s):\n", i+1, len(splits), split.name)
+		if err := shared.StageFiles(r, split.pathspecs, split.excludes); err != nil {
+			return err
+		}
+		files, err := shared.StagedFiles(r)
+		if err != nil {
+			return errors.New("list staged files", err)
+		}
+		if len(files) == 0 {
+			fmt.Println("  - No changes matched, skipping")
+			continue
+		}
+
+		prCfg, err := renderSplitTemplates(r, cfg, split, files)
+		if err != nil {
+			return err
+		}
+		branch := base + "-" + split.suffix
+		if err := pr.NewBranchManagerWithRunner(prCfg, r).CreateSplitBranch(branch); err != nil {
+			return err
+		}
+		staged = append(staged, files...)
+		if headSHA, err := shared.CurrentCommitSHA(r); err == nil {
+			recordBranchPush(ctx, r, branch, "", headSHA)
+		}
+
+		pull, err := openPullRequest(ctx, r, prCfg, branch)
+		if err != nil {
+			return err
+		}
+		prs = append(prs, splitPullRequest{Branch: branch, PRNumber: pull.Number, PRURL: pull.URL, Paths: files})
+
+		if err := shared.RunStep(r, fmt.Sprintf("Checking out branch %s", cfg.Branch),
+			gitcmd.CmdGit, gitcmd.CheckoutArgs(cfg.Branch)...); err != nil {
+			return fmt.Errorf("failed to checkout branch: %w", err)
+		}
+	}
+
+	setStagedFiles(result, staged)
+	data, _ := json.Marshal(prs) // plain strings and ints always encode
+	result.Set(output.KeyPullRequests, string(data))
+
+	if len(prs) == 0 {
+		fmt.Println("No changes detected")
+		if cfg.SkipIfEmpty {
+			return nil
+		}
+		return fmt.Errorf("no changes to create PR")
+	}
+
+	fmt.Println("\nGit Commit Action Completed Successfully!\n" +
+		"=========================================")
+
+	return nil
+}
+
+// planSplits returns the parts of the changes to open pull requests for,
+// leaving the index empty. By directory, the changes matching file_pattern
+// are listed from their name-status and split by top-level directory, the
+// files at the root forming their own part. By group, each commit_groups
+// entry is a part, followed by the changes no entry matched unless
+// commit_groups_unmatched leaves them.
+func planSplits(r gitcmd.Runner, cfg *config.GitConfig) ([]prSplit, error) {
+	if cfg.PRSplitBy == config.PRSplitGroup {
+		// Files staged before, such as the changelog, go with the unmatched
+		// changes.
+		prestaged, err := unstageAll(r)
+		if err != nil {
+			return nil, err
+		}
+		groups := cfg.CommitGroups()
+		matched, err := matchGroups(r, cfg, groups, prestaged)
+		if err != nil {
+			return nil, err
+		}
+
+		var splits []prSplit
+		for i, group := range groups {
+			splits = append(splits, prSplit{
+				name:      strings.Join(group.Paths, " "),
+				suffix:    fmt.Sprintf("group-%d", i+1),
+				pathspecs: matched[i],
+				message:   group.Message,
+			})
+		}
+		rest := prSplit{name: "remaining changes", suffix: "rest", pathspecs: prestaged}
+		if cfg.GroupsUnmatched != config.GroupsUnmatchedLeave {
+			rest.pathspecs = append(cfg.FilePatterns(), prestaged...)
+			rest.excludes = cfg.FileExcludes()
+		}
+		if len(rest.pathspecs) > 0 {
+			splits = append(splits, rest)
+		}
+		return splits, nil
+	}
+
+	if err := shared.StageFiles(r, cfg.FilePatterns(), cfg.FileExcludes()); err != nil {
+		return nil, err
+	}
+	paths, err := pr.NewDiffCheckerWithRunner(cfg, r).StagedPaths()
+	if err != nil {
+		return nil, err
+	}
+	if err := shared.RunStep(r, "Unstaging files", gitcmd.CmdGit, gitcmd.ResetIndexArgs()...); err != nil {
+		return nil, errors.New("unstage files", err)
+	}
+
+	var splits []prSplit
+	index := map[string]int{}
+	for _, p := range paths {
+		dir, _, nested := strings.Cut(p, "/")
+		if !nested {
+			dir = "."
+		}
+		i, ok := index[dir]
+		if !ok {
+			suffix := "root"
+			if dir != "." {
+				suffix = "dir-" + strings.Trim(branchSuffixUnsafe.ReplaceAllString(dir, "-"), "-.")
+			}
+			i = len(splits)
+			index[dir] = i
+			splits = append(splits, prSplit{name: dir, suffix: suffix})
+		}
+		splits[i].pathspecs = append(splits[i].pathspecs, gitcmd.PathspecLiteral+p)
+	}
+	return splits, nil
+}
+
+// renderSplitTemplates returns a copy of cfg for the pull request of split,
+// whose staged changes are files: commit_message, or the message of the
+// commit_groups entry, pr_title and pr_body are rendered with them.
+func renderSplitTemplates(r gitcmd.Runner, cfg *config.GitConfig, split prSplit, files []string) (*config.GitConfig, error) {
+	splitCfg := *cfg
+	if split.message != "" {
+		splitCfg.CommitMessage = split.message
+	}
+	return renderTemplates(r, &splitCfg, files)
+}
@@ -0,0 +1,273 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/github"
+)
+
+// apiCall is one request the fake GitHub API received.
+type apiCall struct {
+	Method string
+	Path   string
+	Body   map[string]any
+
+	// Raw and ContentType let upload tests see non-JSON bodies.
+	Raw         string
+	ContentType string
+}
+
+// fakeAPI is an httptest-backed stand-in for the GitHub REST API. routes maps
+// "<METHOD> <path>" to a canned response; anything unrouted returns 404 so an
+// unexpected call fails loudly rather than silently passing.
+type fakeAPI struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	calls  []apiCall
+	routes map[string]func(w http.ResponseWriter)
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+	f := &fakeAPI{routes: make(map[string]func(http.ResponseWriter))}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeAPI) handle(w http.ResponseWriter, r *http.Request) {
+	raw, _ := io.ReadAll(r.Body)
+	var body map[string]any
+	_ = json.Unmarshal(raw, &body)
+
+	// The client prefixes every endpoint with /repos/<owner>/<repo>.
+	path := strings.TrimPrefix(r.URL.RequestURI(), "/repos/owner/repo")
+
+	f.mu.Lock()
+	f.calls = append(f.calls, apiCall{
+		Method: r.Method, Path: path, Body: body,
+		Raw: string(raw), ContentType: r.Header.Get("Content-Type"),
+	})
+	handler, ok := f.routes[r.Method+" "+path]
+	f.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		return
+	}
+	handler(w)
+}
+
+// route registers a JSON response for one endpoint.
+func (f *fakeAPI) route(methodAndPath string, status int, body string) *fakeAPI {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[methodAndPath] = func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+	return f
+}
+
+func (f *fakeAPI) Calls() []apiCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]apiCall(nil), f.calls...)
+}
+
+// called reports whether an endpoint was hit, returning the first matching call.
+func (f *fakeAPI) called(methodAndPath string) (apiCall, bool) {
+	for _, c := range f.Calls() {
+		if c.Method+" "+c.Path == methodAndPath {
+			return c, true
+		}
+	}
+	return apiCall{}, false
+}
+
+func releaseConfig() *config.GitConfig {
+	return &config.GitConfig{
+		TagName:       "v1.2.0",
+		CreateRelease: true,
+		GitHubToken:   "token",
+	}
+}
+
+// newAPIPublisher wires a Publisher to the fake API.
+func newAPIPublisher(t *testing.T, cfg *config.GitConfig, api *fakeAPI) *Publisher {
+	t.Helper()
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+	return NewPublisherWithClient(cfg, github.NewClientWithBaseURL(cfg.GitHubToken, api.server.URL))
+}
+
+func TestPublish_PostsRelease(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /releases", http.StatusCreated,
+			`{"id":17,"html_url":"https://github.com/owner/repo/releases/tag/v1.2.0","upload_url":"https://uploads.example/assets{?name,label}"}`)
+	cfg := releaseConfig()
+	cfg.ReleasePrerelease = true
+	cfg.ReleaseGenerateNotes = true
+	p := newAPIPublisher(t, cfg, api)
+
+	rel, err := p.Publish(context.Background(), "v1.2.0", "## v1.2.0\n")
+	if err != nil {
+		t.Fatalf("Publish() error = %v, want nil", err)
+	}
+	if rel.ID != 17 || rel.HTMLURL == "" || rel.UploadURL == "" {
+		t.Errorf("Publish() = %+v, want the parsed release", rel)
+	}
+
+	call, ok := api.called("POST /releases")
+	if !ok {
+		t.Fatalf("Calls() = %v, want a POST /releases", api.Calls())
+	}
+	if call.Body["tag_name"] != "v1.2.0" || call.Body["name"] != "v1.2.0" {
+		t.Errorf("payload = %v, want tag_name and name defaulted to the tag", call.Body)
+	}
+	if call.Body["body"] != "## v1.2.0\n" {
+		t.Errorf("payload body = %v, want the default body", call.Body["body"])
+	}
+	if call.Body["prerelease"] != true || call.Body["draft"] != false || call.Body["generate_release_notes"] != true {
+		t.Errorf("payload flags = %v", call.Body)
+	}
+}
+
+func TestPublish_BodyPrecedence(t *testing.T) {
+	notes := filepath.Join(t.TempDir(), "NOTES.md")
+	os.WriteFile(notes, []byte("from file"), 0644)
+
+	tests := []struct {
+		name     string
+		body     string
+		bodyFile string
+		want     string
+	}{
+		{"explicit body", "explicit", "", "explicit"},
+		{"body file", "", notes, "from file"},
+		{"default", "", "", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := releaseConfig()
+			cfg.ReleaseBody = tt.body
+			cfg.ReleaseBodyFile = tt.bodyFile
+			payload, err := NewPublisherWithClient(cfg, github.NewClient("")).buildPayload("v1.2.0", "default")
+			if err != nil {
+				t.Fatalf("buildPayload() error = %v", err)
+			}
+			if payload["body"] != tt.want {
+				t.Errorf("body = %v, want %q", payload["body"], tt.want)
+			}
+		})
+	}
+}
+
+func TestPublish_MissingBodyFileFails(t *testing.T) {
+	cfg := releaseConfig()
+	cfg.ReleaseBodyFile = filepath.Join(t.TempDir(), "missing.md")
+	api := newFakeAPI(t)
+	p := newAPIPublisher(t, cfg, api)
+
+	if _, err := p.Publish(context.Background(), "v1.2.0", ""); err == nil {
+		t.Fatal("Publish() error = nil, want the read failure")
+	}
+	if len(api.Calls()) != 0 {
+		t.Errorf("Calls() = %v, want no API call", api.Calls())
+	}
+}
+
+func TestPublish_DryRunPostsNothing(t *testing.T) {
+	cfg := releaseConfig()
+	cfg.ReleaseDryRun = true
+	api := newFakeAPI(t)
+	p := newAPIPublisher(t, cfg, api)
+
+	rel, err := p.Publish(context.Background(), "v1.2.0", "")
+	if err != nil {
+		t.Fatalf("Publish() error = %v, want nil", err)
+	}
+	if !rel.DryRun {
+		t.Error("Publish() DryRun = false, want true")
+	}
+	if len(api.Calls()) != 0 {
+		t.Errorf("Calls() = %v, want no API call in dry run", api.Calls())
+	}
+}
+
+func TestPublish_ExistingReleaseIsUpdated(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /releases", http.StatusUnprocessableEntity,
+			`{"message":"Validation Failed","errors":[{"resource":"Release","code":"already_exists","field":"tag_name"}]}`).
+		route("GET /releases/tags/v1.2.0", http.StatusOK, `{"id":5}`).
+		route("PATCH /releases/5", http.StatusOK, `{"id":5,"html_url":"https://github.com/owner/repo/releases/tag/v1.2.0"}`)
+	p := newAPIPublisher(t, releaseConfig(), api)
+
+	rel, err := p.Publish(context.Background(), "v1.2.0", "")
+	if err != nil {
+		t.Fatalf("Publish() error = %v, want the existing release to be updated", err)
+	}
+	if rel.ID != 5 {
+		t.Errorf("Publish() ID = %d, want 5", rel.ID)
+	}
+}
+
+// Only a release created by the run is deleted on rollback; an updated one is
+// left alone.
+func TestPublish_RollbackDeletesCreatedRelease(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /releases", http.StatusCreated, `{"id":9,"html_url":"https://github.com/owner/repo/releases/tag/v1.2.0"}`).
+		route("DELETE /releases/9", http.StatusNoContent, ``)
+	p := newAPIPublisher(t, releaseConfig(), api)
+	j := journal.New()
+
+	if _, err := p.Publish(journal.NewContext(context.Background(), j), "v1.2.0", ""); err != nil {
+		t.Fatalf("Publish() error = %v, want nil", err)
+	}
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	if _, ok := api.called("DELETE /releases/9"); !ok {
+		t.Errorf("Calls() = %v, want the release deleted", api.Calls())
+	}
+}
+
+func TestPublish_APIErrorFails(t *testing.T) {
+	api := newFakeAPI(t).
+		route("POST /releases", http.StatusForbidden, `{"message":"Resource not accessible by integration"}`)
+	p := newAPIPublisher(t, releaseConfig(), api)
+
+	_, err := p.Publish(context.Background(), "v1.2.0", "")
+	if err == nil || !strings.Contains(err.Error(), "Resource not accessible") {
+		t.Fatalf("Publish() error = %v, want the API message", err)
+	}
+}
+
+func TestFindByTag_NotFound(t *testing.T) {
+	p := newAPIPublisher(t, releaseConfig(), newFakeAPI(t))
+
+	_, found, err := p.FindByTag(context.Background(), "v9.9.9")
+	if err != nil || found {
+		t.Errorf("FindByTag() = (found=%v, %v), want (false, nil)", found, err)
+	}
+}
+
+func TestNewPublisher_HasClient(t *testing.T) {
+	if p := NewPublisher(releaseConfig()); p.client == nil {
+		t.Error("NewPublisher() client = nil, want a default client")
+	}
+}
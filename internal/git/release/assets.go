@@ -0,0 +1,271 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+)
+
+// sniffLen is how much of a file http.DetectContentType looks at.
+const sniffLen = 512
+
+// assetContentTypes covers the artifact extensions the system MIME table is
+// unlikely to know about. Lookups are on the lower-cased extension; compound
+// extensions such as .tar.gz resolve through their last part.
+var assetContentTypes = map[string]string{
+	".gz":     "application/gzip",
+	".tgz":    "application/gzip",
+	".bz2":    "application/x-bzip2",
+	".xz":     "application/x-xz",
+	".zst":    "application/zstd",
+	".zip":    "application/zip",
+	".tar":    "application/x-tar",
+	".deb":    "application/vnd.debian.binary-package",
+	".rpm":    "application/x-rpm",
+	".apk":    "application/vnd.android.package-archive",
+	".dmg":    "application/x-apple-diskimage",
+	".exe":    "application/vnd.microsoft.portable-executable",
+	".msi":    "application/x-msi",
+	".jar":    "application/java-archive",
+	".sha256": "text/plain; charset=utf-8",
+	".sha512": "text/plain; charset=utf-8",
+	".sig":    "application/pgp-signature",
+	".asc":    "application/pgp-signature",
+	".txt":    "text/plain; charset=utf-8",
+	".md":     "text/markdown; charset=utf-8",
+	".json":   "application/json",
+	".yaml":   "application/yaml",
+	".yml":    "application/yaml",
+}
+
+// Asset is the typed view of an uploaded release asset, in the shape published
+// as the release_assets output.
+type Asset struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	URL         string `json:"url"` // "browser_download_url"
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// EnsureRelease returns the release for tagName, creating it through Publish
+// when none exists yet. In dry run no lookup is made and Publish reports what
+// it would create.
+func (p *Publisher) EnsureRelease(ctx context.Context, tagName, defaultBody string) (Release, error) {
+	if p.config.ReleaseDryRun {
+		return p.Publish(ctx, tagName, defaultBody)
+	}
+
+	fmt.Printf("  - Looking up release for %s... ", tagName)
+	existing, found, err := p.FindByTag(ctx, tagName)
+	if err != nil {
+		fmt.Println("FAILED")
+		return Release{}, err
+	}
+	if !found {
+		fmt.Println("not found")
+		return p.Publish(ctx, tagName, defaultBody)
+	}
+
+	fmt.Printf("found #%d\n", existing.ID)
+	return existing, nil
+}
+
+// UploadAssets uploads every file matched by release_assets to rel. An asset
+// already on the release with the same name, for instance from an earlier
+// attempt, is deleted first so the upload replaces it.
+func (p *Publisher) UploadAssets(ctx context.Context, rel Release) ([]Asset, error) {
+	files, err := ResolveAssets(p.config.ReleaseAssets)
+	if err != nil {
+		return nil, err
+	}
+
+	if rel.DryRun {
+		for _, file := range files {
+			fmt.Printf("  - [DRY RUN] Would upload %s as %s... Skipped\n", file, filepath.Base(file))
+		}
+		return nil, nil
+	}
+
+	existing, err := p.listAssets(ctx, rel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	assets := make([]Asset, 0, len(files))
+	for _, file := range files {
+		name := filepath.Base(file)
+		if id, ok := existing[name]; ok {
+			fmt.Printf("  - Removing existing asset %s... ", name)
+			if err := p.client.Delete(ctx, fmt.Sprintf("/releases/assets/%d", id)); err != nil {
+				fmt.Println("FAILED")
+				return nil, errors.NewAPIErrorFrom("delete release asset", err)
+			}
+			fmt.Println("Done")
+		}
+
+		asset, err := p.uploadAsset(ctx, rel.UploadURL, file, name)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+
+	return assets, nil
+}
+
+// uploadAsset streams one file to the release upload URL.
+func (p *Publisher) uploadAsset(ctx context.Context, uploadURL, path, name string) (Asset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Asset{}, errors.NewWithPath("open release asset", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return Asset{}, errors.NewWithPath("stat release asset", path, err)
+	}
+	contentType, err := detectContentType(f, name)
+	if err != nil {
+		return Asset{}, errors.NewWithPath("detect content type", path, err)
+	}
+
+	fmt.Printf("  - Uploading %s (%s, %d bytes)... ", name, contentType, info.Size())
+	resp, err := p.client.Upload(ctx, uploadURL, name, f, info.Size(), contentType)
+	if err != nil {
+		fmt.Println("FAILED")
+		return Asset{}, errors.NewAPIErrorFrom("upload release asset", err)
+	}
+	if msg, ok := resp["message"].(string); ok && msg != "" {
+		fmt.Println("FAILED")
+		return Asset{}, errors.NewAPIError("upload release asset", fmt.Sprintf("%s: %s", name, msg))
+	}
+	fmt.Println("Done")
+
+	asset := parseAsset(resp)
+	if asset.ContentType == "" {
+		asset.ContentType = contentType
+	}
+	if asset.Size == 0 {
+		asset.Size = info.Size()
+	}
+	return asset, nil
+}
+
+// assetsPerPage is the page size of the release assets listing, the largest
+// the API allows.
+const assetsPerPage = 100
+
+// listAssets returns the IDs of the assets already on a release, keyed by
+// name. It reads page after page until one comes back short.
+func (p *Publisher) listAssets(ctx context.Context, releaseID int) (map[string]int, error) {
+	existing := make(map[string]int)
+	for page := 1; ; page++ {
+		list, err := p.client.GetArray(ctx, fmt.Sprintf("/releases/%d/assets?per_page=%d&page=%d", releaseID, assetsPerPage, page))
+		if err != nil {
+			return nil, errors.NewAPIErrorFrom("list release assets", err)
+		}
+		for _, m := range list {
+			asset := parseAsset(m)
+			existing[asset.Name] = asset.ID
+		}
+		if len(list) < assetsPerPage {
+			return existing, nil
+		}
+	}
+}
+
+// parseAsset decodes the generic client map into a typed Asset.
+func parseAsset(m map[string]any) Asset {
+	var a Asset
+	if v, ok := m["id"].(float64); ok {
+		a.ID = int(v)
+	}
+	if v, ok := m["name"].(string); ok {
+		a.Name = v
+	}
+	if v, ok := m["browser_download_url"].(string); ok {
+		a.URL = v
+	}
+	if v, ok := m["content_type"].(string); ok {
+		a.ContentType = v
+	}
+	if v, ok := m["size"].(float64); ok {
+		a.Size = int64(v)
+	}
+	return a
+}
+
+// ResolveAssets expands the space-separated glob patterns of release_assets
+// into the files to upload, in pattern order without duplicates. A pattern
+// that matches no file is an error, as are two files that would be uploaded
+// under the same name.
+func ResolveAssets(patterns string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	names := make(map[string]string)
+
+	for _, pattern := range strings.Fields(patterns) {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.NewConfigError("release_assets", fmt.Sprintf("invalid pattern %q", pattern))
+		}
+
+		matched := false
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			matched = true
+			if seen[match] {
+				continue
+			}
+
+			name := filepath.Base(match)
+			if other, ok := names[name]; ok {
+				return nil, errors.NewConfigError("release_assets", fmt.Sprintf("%s and %s would both be uploaded as %s", other, match, name))
+			}
+			seen[match] = true
+			names[name] = match
+			files = append(files, match)
+		}
+
+		if !matched {
+			return nil, errors.NewConfigError("release_assets", fmt.Sprintf("pattern %q matched no files", pattern))
+		}
+	}
+
+	return files, nil
+}
+
+// detectContentType picks the upload Content-Type for an asset: the known
+// artifact extensions first, then the system MIME table, then sniffing the
+// first bytes of the file. f is rewound before returning.
+func detectContentType(f io.ReadSeeker, name string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ct, ok := assetContentTypes[ext]; ok {
+		return ct, nil
+	}
+	if ct := mime.TypeByExtension(ext); ct != "" {
+		return ct, nil
+	}
+
+	buf := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
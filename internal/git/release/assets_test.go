@@ -0,0 +1,245 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeAssets creates files under a temp dir and returns the dir.
+func writeAssets(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	return dir
+}
+
+func TestResolveAssets(t *testing.T) {
+	dir := writeAssets(t, map[string]string{
+		"dist/app.tar.gz":        "gz",
+		"dist/app.tar.gz.sha256": "sum",
+		"dist/notes.txt":         "notes",
+	})
+	os.MkdirAll(filepath.Join(dir, "dist", "sub.tar.gz"), 0755)
+
+	got, err := ResolveAssets(filepath.Join(dir, "dist/*.tar.gz") + " " + filepath.Join(dir, "dist/*.sha256") + " " + filepath.Join(dir, "dist/app.*"))
+	if err != nil {
+		t.Fatalf("ResolveAssets() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "dist/app.tar.gz"),
+		filepath.Join(dir, "dist/app.tar.gz.sha256"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ResolveAssets() = %v, want %v (directories skipped, duplicates dropped)", got, want)
+	}
+}
+
+func TestResolveAssets_Errors(t *testing.T) {
+	dir := writeAssets(t, map[string]string{
+		"a/app.zip": "1",
+		"b/app.zip": "2",
+	})
+
+	tests := []struct {
+		name     string
+		patterns string
+		want     string
+	}{
+		{"no match", filepath.Join(dir, "*.deb"), "matched no files"},
+		{"bad pattern", "[", "invalid pattern"},
+		{"name clash", filepath.Join(dir, "*/app.zip"), "would both be uploaded as app.zip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveAssets(tt.patterns)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ResolveAssets(%q) error = %v, want %q", tt.patterns, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	dir := writeAssets(t, map[string]string{
+		"app.tar.gz":  "x",
+		"SUMS.SHA256": "x",
+		"page.html":   "x",
+		"blob":        "\x00\x01\x02",
+		"readme":      "plain words",
+	})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"app.tar.gz", "application/gzip"},
+		{"SUMS.SHA256", "text/plain; charset=utf-8"},
+		{"page.html", "text/html; charset=utf-8"},
+		{"blob", "application/octet-stream"},
+		{"readme", "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, _ := os.Open(filepath.Join(dir, tt.name))
+			defer f.Close()
+
+			got, err := detectContentType(f, tt.name)
+			if err != nil || got != tt.want {
+				t.Errorf("detectContentType() = (%q, %v), want %q", got, err, tt.want)
+			}
+			if pos, _ := f.Seek(0, 1); pos != 0 {
+				t.Errorf("file offset = %d, want rewound to 0", pos)
+			}
+		})
+	}
+}
+
+func TestUploadAssets_ReplacesSameName(t *testing.T) {
+	dir := writeAssets(t, map[string]string{
+		"app.tar.gz": "new build",
+		"app.sha256": "abc  app.tar.gz\n",
+	})
+	api := newFakeAPI(t)
+	uploadURL := api.server.URL + "/uploads/assets{?name,label}"
+	api.
+		route("GET /releases/5/assets?per_page=100&page=1", http.StatusOK, `[{"id":70,"name":"app.tar.gz"},{"id":71,"name":"other.zip"}]`).
+		route("DELETE /releases/assets/70", http.StatusNoContent, ``).
+		route("POST /uploads/assets?name=app.tar.gz", http.StatusCreated,
+			`{"id":80,"name":"app.tar.gz","browser_download_url":"https://dl/app.tar.gz","content_type":"application/gzip","size":9}`).
+		route("POST /uploads/assets?name=app.sha256", http.StatusCreated,
+			`{"id":81,"name":"app.sha256","browser_download_url":"https://dl/app.sha256","content_type":"text/plain","size":16}`)
+
+	cfg := releaseConfig()
+	cfg.ReleaseAssets = filepath.Join(dir, "*.tar.gz") + " " + filepath.Join(dir, "*.sha256")
+	p := newAPIPublisher(t, cfg, api)
+
+	assets, err := p.UploadAssets(context.Background(), Release{ID: 5, UploadURL: uploadURL})
+	if err != nil {
+		t.Fatalf("UploadAssets() error = %v, want nil", err)
+	}
+
+	var methods []string
+	for _, c := range api.Calls() {
+		methods = append(methods, c.Method+" "+c.Path)
+	}
+	wantCalls := []string{
+		"GET /releases/5/assets?per_page=100&page=1",
+		"DELETE /releases/assets/70",
+		"POST /uploads/assets?name=app.tar.gz",
+		"POST /uploads/assets?name=app.sha256",
+	}
+	if !reflect.DeepEqual(methods, wantCalls) {
+		t.Errorf("calls = %v, want %v", methods, wantCalls)
+	}
+
+	upload, _ := api.called("POST /uploads/assets?name=app.tar.gz")
+	if upload.Raw != "new build" || upload.ContentType != "application/gzip" {
+		t.Errorf("upload = (%q, %q), want the file streamed as application/gzip", upload.Raw, upload.ContentType)
+	}
+
+	want := []Asset{
+		{ID: 80, Name: "app.tar.gz", URL: "https://dl/app.tar.gz", ContentType: "application/gzip", Size: 9},
+		{ID: 81, Name: "app.sha256", URL: "https://dl/app.sha256", ContentType: "text/plain", Size: 16},
+	}
+	if !reflect.DeepEqual(assets, want) {
+		t.Errorf("UploadAssets() = %+v, want %+v", assets, want)
+	}
+}
+
+// An asset listed past the first page is found and replaced as well.
+func TestUploadAssets_ReplacesSameNameOnLaterPage(t *testing.T) {
+	dir := writeAssets(t, map[string]string{"app.zip": "zip"})
+	firstPage := make([]string, assetsPerPage)
+	for i := range firstPage {
+		firstPage[i] = fmt.Sprintf(`{"id":%d,"name":"asset-%d.txt"}`, i+1, i+1)
+	}
+	api := newFakeAPI(t)
+	api.
+		route("GET /releases/5/assets?per_page=100&page=1", http.StatusOK, "["+strings.Join(firstPage, ",")+"]").
+		route("GET /releases/5/assets?per_page=100&page=2", http.StatusOK, `[{"id":170,"name":"app.zip"}]`).
+		route("DELETE /releases/assets/170", http.StatusNoContent, ``).
+		route("POST /uploads/assets?name=app.zip", http.StatusCreated, `{"id":180,"name":"app.zip"}`)
+
+	cfg := releaseConfig()
+	cfg.ReleaseAssets = filepath.Join(dir, "app.zip")
+	p := newAPIPublisher(t, cfg, api)
+
+	if _, err := p.UploadAssets(context.Background(), Release{ID: 5, UploadURL: api.server.URL + "/uploads/assets{?name,label}"}); err != nil {
+		t.Fatalf("UploadAssets() error = %v, want nil", err)
+	}
+	if _, ok := api.called("DELETE /releases/assets/170"); !ok {
+		t.Errorf("Calls() = %v, want the asset of the second page deleted", api.Calls())
+	}
+}
+
+func TestUploadAssets_UploadErrorFails(t *testing.T) {
+	dir := writeAssets(t, map[string]string{"app.zip": "zip"})
+	api := newFakeAPI(t)
+	api.
+		route("GET /releases/5/assets?per_page=100&page=1", http.StatusOK, `[]`).
+		route("POST /uploads/assets?name=app.zip", http.StatusUnprocessableEntity, `{"message":"Validation Failed"}`)
+
+	cfg := releaseConfig()
+	cfg.ReleaseAssets = filepath.Join(dir, "app.zip")
+	p := newAPIPublisher(t, cfg, api)
+
+	_, err := p.UploadAssets(context.Background(), Release{ID: 5, UploadURL: api.server.URL + "/uploads/assets{?name,label}"})
+	if err == nil || !strings.Contains(err.Error(), "app.zip: Validation Failed") {
+		t.Errorf("UploadAssets() error = %v, want the API message for app.zip", err)
+	}
+}
+
+func TestUploadAssets_DryRunUploadsNothing(t *testing.T) {
+	dir := writeAssets(t, map[string]string{"app.zip": "zip"})
+	cfg := releaseConfig()
+	cfg.ReleaseAssets = filepath.Join(dir, "*.zip")
+	api := newFakeAPI(t)
+	p := newAPIPublisher(t, cfg, api)
+
+	assets, err := p.UploadAssets(context.Background(), Release{DryRun: true})
+	if err != nil || assets != nil {
+		t.Errorf("UploadAssets() = (%v, %v), want (nil, nil)", assets, err)
+	}
+	if len(api.Calls()) != 0 {
+		t.Errorf("Calls() = %v, want no API call in dry run", api.Calls())
+	}
+}
+
+func TestEnsureRelease(t *testing.T) {
+	t.Run("existing release is reused", func(t *testing.T) {
+		api := newFakeAPI(t).
+			route("GET /releases/tags/v1.2.0", http.StatusOK, `{"id":5,"html_url":"https://github.com/owner/repo/releases/tag/v1.2.0"}`)
+		p := newAPIPublisher(t, releaseConfig(), api)
+
+		rel, err := p.EnsureRelease(context.Background(), "v1.2.0", "")
+		if err != nil || rel.ID != 5 {
+			t.Fatalf("EnsureRelease() = (%+v, %v), want release 5", rel, err)
+		}
+		if _, posted := api.called("POST /releases"); posted {
+			t.Error("EnsureRelease() created a release although one exists")
+		}
+	})
+
+	t.Run("missing release is created", func(t *testing.T) {
+		api := newFakeAPI(t).
+			route("POST /releases", http.StatusCreated, `{"id":6,"html_url":"https://github.com/owner/repo/releases/tag/v1.2.0"}`)
+		p := newAPIPublisher(t, releaseConfig(), api)
+
+		rel, err := p.EnsureRelease(context.Background(), "v1.2.0", "")
+		if err != nil || rel.ID != 6 {
+			t.Fatalf("EnsureRelease() = (%+v, %v), want the created release", rel, err)
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/github"
+)
+
+// Publisher creates GitHub releases for tags pushed by the TagManager.
+type Publisher struct {
+	config *config.GitConfig
+	client *github.Client
+}
+
+// NewPublisher creates a new Publisher instance.
+func NewPublisher(cfg *config.GitConfig) *Publisher {
+	return NewPublisherWithClient(cfg, github.NewClient(cfg.GitHubToken))
+}
+
+// NewPublisherWithClient creates a Publisher with an explicit API client,
+// allowing tests to drive the release paths against an httptest server.
+func NewPublisherWithClient(cfg *config.GitConfig, client *github.Client) *Publisher {
+	return &Publisher{config: cfg, client: client}
+}
+
+// Release is the typed view of a GitHub release API response. It models only
+// the fields the action consumes.
+type Release struct {
+	ID        int    // "id"
+	HTMLURL   string // "html_url"
+	UploadURL string // "upload_url"; RFC 6570 template for asset uploads
+	DryRun    bool   // set by the dry-run path, never from the API
+}
+
+// parseRelease decodes the generic client map into a typed Release.
+func parseRelease(m map[string]any) Release {
+	var r Release
+	if v, ok := m["id"].(float64); ok {
+		r.ID = int(v)
+	}
+	if v, ok := m["html_url"].(string); ok {
+		r.HTMLURL = v
+	}
+	if v, ok := m["upload_url"].(string); ok {
+		r.UploadURL = v
+	}
+	return r
+}
+
+// Publish creates the release for tagName. defaultBody is used when neither
+// release_body nor release_body_file is set (typically the rendered
+// changelog). When a release for the tag already exists, for instance from an
+// earlier attempt, it is updated in place instead.
+func (p *Publisher) Publish(ctx context.Context, tagName, defaultBody string) (Release, error) {
+	payload, err := p.buildPayload(tagName, defaultBody)
+	if err != nil {
+		return Release{}, err
+	}
+
+	if p.config.ReleaseDryRun {
+		return p.publishDryRun(tagName, payload)
+	}
+
+	fmt.Printf("  - Creating release for %s... ", tagName)
+	resp, err := p.client.Post(ctx, "/releases", payload)
+	if err != nil {
+		fmt.Println("FAILED")
+		return Release{}, errors.NewAPIErrorFrom("create release", err)
+	}
+
+	if msg, ok := resp["message"].(string); ok && msg != "" {
+		if !isAlreadyExists(resp) {
+			fmt.Println("FAILED")
+			return Release{}, errors.NewAPIError("create release", msg)
+		}
+		fmt.Println("already exists")
+		return p.updateExisting(ctx, tagName, payload)
+	}
+
+	release := parseRelease(resp)
+	if release.HTMLURL == "" {
+		fmt.Println("FAILED")
+		return Release{}, errors.NewAPIError("create release", "failed to get release URL from response")
+	}
+
+	fmt.Println("Done")
+	fmt.Printf("Release created: %s\n", release.HTMLURL)
+
+	journal.FromContext(ctx).Record("created release for "+tagName, func(ctx context.Context) error {
+		return p.client.Delete(ctx, fmt.Sprintf("/releases/%d", release.ID))
+	})
+	return release, nil
+}
+
+// FindByTag looks up the release for tagName. found is false when the API
+// reports that no such release exists.
+func (p *Publisher) FindByTag(ctx context.Context, tagName string) (Release, bool, error) {
+	resp, err := p.client.Get(ctx, "/releases/tags/"+url.PathEscape(tagName))
+	if err != nil {
+		return Release{}, false, errors.NewAPIErrorFrom("get release", err)
+	}
+	if msg, ok := resp["message"].(string); ok && msg != "" {
+		if msg == "Not Found" {
+			return Release{}, false, nil
+		}
+		return Release{}, false, errors.NewAPIError("get release", msg)
+	}
+	return parseRelease(resp), true, nil
+}
+
+// updateExisting applies payload to the release already attached to tagName.
+func (p *Publisher) updateExisting(ctx context.Context, tagName string, payload map[string]interface{}) (Release, error) {
+	existing, found, err := p.FindByTag(ctx, tagName)
+	if err != nil {
+		return Release{}, err
+	}
+	if !found {
+		return Release{}, errors.NewAPIError("create release", fmt.Sprintf("release for %s reported as existing but not found", tagName))
+	}
+
+	fmt.Printf("  - Updating release #%d... ", existing.ID)
+	resp, err := p.client.Patch(ctx, fmt.Sprintf("/releases/%d", existing.ID), payload)
+	if err != nil {
+		fmt.Println("FAILED")
+		return Release{}, errors.NewAPIErrorFrom("update release", err)
+	}
+	if msg, ok := resp["message"].(string); ok && msg != "" {
+		fmt.Println("FAILED")
+		return Release{}, errors.NewAPIError("update release", msg)
+	}
+
+	fmt.Println("Done")
+	release := parseRelease(resp)
+	fmt.Printf("Release updated: %s\n", release.HTMLURL)
+	return release, nil
+}
+
+// publishDryRun prints the payload that would be posted.
+func (p *Publisher) publishDryRun(tagName string, payload map[string]interface{}) (Release, error) {
+	fmt.Printf("  - [DRY RUN] Would create release for %s... Skipped\n", tagName)
+
+	data, err := json.MarshalIndent(payload, "    ", "  ")
+	if err != nil {
+		return Release{}, errors.New("marshal release payload", err)
+	}
+	fmt.Printf("\nRelease payload that would be submitted:\n    %s\n", data)
+
+	return Release{DryRun: true}, nil
+}
+
+// buildPayload creates the data structure for the release API call.
+func (p *Publisher) buildPayload(tagName, defaultBody string) (map[string]interface{}, error) {
+	name := p.config.ReleaseName
+	if name == "" {
+		name = tagName
+	}
+
+	body, err := p.releaseBody(defaultBody)
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{
+		"tag_name":   tagName,
+		"name":       name,
+		"draft":      p.config.ReleaseDraft,
+		"prerelease": p.config.ReleasePrerelease,
+	}
+	if body != "" {
+		data["body"] = body
+	}
+	if p.config.ReleaseGenerateNotes {
+		data["generate_release_notes"] = true
+	}
+
+	return data, nil
+}
+
+// releaseBody resolves the release notes from release_body, release_body_file
+// or the supplied default, in that order.
+func (p *Publisher) releaseBody(defaultBody string) (string, error) {
+	if p.config.ReleaseBody != "" {
+		return p.config.ReleaseBody, nil
+	}
+	if p.config.ReleaseBodyFile != "" {
+		content, err := os.ReadFile(p.config.ReleaseBodyFile)
+		if err != nil {
+			return "", errors.NewWithPath("read release body file", p.config.ReleaseBodyFile, err)
+		}
+		return string(content), nil
+	}
+	return defaultBody, nil
+}
+
+// isAlreadyExists reports whether a create-release error response means a
+// release for the tag is already present.
+func isAlreadyExists(resp map[string]interface{}) bool {
+	details, ok := resp["errors"].([]any)
+	if !ok {
+		return false
+	}
+	for _, d := range details {
+		if m, ok := d.(map[string]any); ok && m["code"] == "already_exists" {
+			return true
+		}
+	}
+	return false
+}
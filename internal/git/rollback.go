@@ -0,0 +1,55 @@
+package git
+
+import (
+	"context"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// recordBranchPush records in the journal of ctx that branch was moved on the
+// remote from before to after. An empty before means the push created the
+// branch, which is undone by deleting it unless it is already gone (as with
+// delete_source_branch); otherwise the branch is pointed back at before, but
+// only while it is still at after, so that commits pushed by others since are
+// never discarded.
+func recordBranchPush(ctx context.Context, r gitcmd.Runner, branch, before, after string) {
+	if before == after {
+		return
+	}
+
+	j := journal.FromContext(ctx)
+	if before == "" {
+		j.Record("created branch "+branch, func(context.Context) error {
+			out, err := r.Output(gitcmd.CmdGit, gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, branch)...)
+			if err == nil && strings.TrimSpace(string(out)) == "" {
+				return nil
+			}
+			return r.Run(gitcmd.CmdGit, gitcmd.PushDeleteBranchArgs(gitcmd.RefOrigin, branch)...)
+		})
+		return
+	}
+
+	j.Record("pushed "+shortenCommitSHA(after)+" to "+branch, func(context.Context) error {
+		return r.Run(gitcmd.CmdGit, gitcmd.PushRefToArgs(gitcmd.RefHeads+branch, before,
+			gitcmd.ForceWithLeaseOpt(branch, after))...)
+	})
+}
+
+// recordTagPush records in the journal of ctx that tag was pushed. before is
+// the commit the tag pointed at until then, restored on undo; an empty before
+// means the tag was new and is deleted.
+func recordTagPush(ctx context.Context, r gitcmd.Runner, tag, before string) {
+	j := journal.FromContext(ctx)
+	if before == "" {
+		j.Record("pushed tag "+tag, func(context.Context) error {
+			return r.Run(gitcmd.CmdGit, gitcmd.DeleteRemoteTagArgs(tag)...)
+		})
+		return
+	}
+
+	j.Record("moved tag "+tag, func(context.Context) error {
+		return r.Run(gitcmd.CmdGit, gitcmd.PushRefToArgs(gitcmd.RefTags+tag, before, gitcmd.OptForce)...)
+	})
+}
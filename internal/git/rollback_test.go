@@ -0,0 +1,159 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// A commit pushed to an existing branch is rolled back by pointing the branch
+// at its previous commit, leased on the pushed one.
+func TestRollback_CommitPush(t *testing.T) {
+	j := journal.New()
+	ctx := journal.NewContext(context.Background(), j)
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "cafebabe\n"})
+
+	if err := commitChanges(ctx, f, baseConfig(), output.NewResult(), "9f1c0de", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+
+	want := key(gitcmd.PushRefToArgs("refs/heads/main", "9f1c0de", gitcmd.ForceWithLeaseOpt("main", "cafebabe")))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want %q", f.Keys(), want)
+	}
+}
+
+// A push that was rejected and rebased onto new remote commits is rolled back
+// to the remote tip it was rebased onto, keeping the commits pushed by others.
+func TestRollback_RebasedPushKeepsRemoteCommits(t *testing.T) {
+	cfg := baseConfig()
+	cfg.PushConflictStrategy = config.PushConflictRebase
+	cfg.PushRetries = 3
+	j := journal.New()
+	ctx := journal.NewContext(context.Background(), j)
+	pushes := 0
+	f := gitcmd.NewFakeRunner()
+	f.Handler = func(name string, args []string) (string, error) {
+		switch (gitcmd.Call{Name: name, Args: args}).Key() {
+		case key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")):
+			if pushes++; pushes == 1 {
+				return "", gitcmd.Fail(1)
+			}
+		case key(gitcmd.MergeBaseIsAncestorArgs("origin/main", "HEAD")):
+			return "", gitcmd.Fail(1)
+		case key(gitcmd.RevParseArgs("origin/main")):
+			return "d00dfeed\n", nil
+		case key(gitcmd.RevParseArgs("HEAD")):
+			return "cafebabe\n", nil
+		}
+		return "", nil
+	}
+
+	if err := commitChanges(ctx, f, cfg, output.NewResult(), "9f1c0de", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+
+	want := key(gitcmd.PushRefToArgs("refs/heads/main", "d00dfeed", gitcmd.ForceWithLeaseOpt("main", "cafebabe")))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want %q", f.Keys(), want)
+	}
+	if stale := key(gitcmd.PushRefToArgs("refs/heads/main", "9f1c0de", gitcmd.ForceWithLeaseOpt("main", "cafebabe"))); f.Ran(stale) {
+		t.Errorf("Keys() = %v, want the branch not reset to the commit seen before the rebase", f.Keys())
+	}
+}
+
+// Nothing is recorded when the push left the branch where it was.
+func TestRollback_NothingPushedRecordsNothing(t *testing.T) {
+	j := journal.New()
+	ctx := journal.NewContext(context.Background(), j)
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "9f1c0de\n"})
+
+	if err := commitChanges(ctx, f, baseConfig(), output.NewResult(), "9f1c0de", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	if len(j.Entries()) != 0 {
+		t.Errorf("Entries() = %v, want none", j.Entries())
+	}
+}
+
+func TestRollback_CreatedBranchIsDeleted(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Branch = "feature"
+	j := journal.New()
+	ctx := journal.NewContext(context.Background(), j)
+	lsRemote := key(gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, "feature"))
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.RevParseArgs("feature")), gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
+		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "cafebabe\n"})
+
+	if _, err := handleBranch(ctx, f, cfg); err != nil {
+		t.Fatalf("handleBranch() error = %v, want nil", err)
+	}
+
+	// The branch now exists on the remote.
+	f.Stub(lsRemote, gitcmd.FakeResult{Stdout: "cafebabe\trefs/heads/feature\n"})
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	if !f.Ran(key(gitcmd.PushDeleteBranchArgs(gitcmd.RefOrigin, "feature"))) {
+		t.Errorf("Keys() = %v, want the branch deleted", f.Keys())
+	}
+}
+
+// A branch already deleted, for instance by delete_source_branch, is not
+// deleted again.
+func TestRollback_CreatedBranchAlreadyGone(t *testing.T) {
+	j := journal.New()
+	f := gitcmd.NewFakeRunner()
+	recordBranchPush(journal.NewContext(context.Background(), j), f, "feature", "", "cafebabe")
+
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+	if f.Ran(key(gitcmd.PushDeleteBranchArgs(gitcmd.RefOrigin, "feature"))) {
+		t.Error("deleted a branch that no longer exists")
+	}
+}
+
+// A new tag is deleted; a tag that existed before is moved back.
+func TestRollback_TagPush(t *testing.T) {
+	cfg := tagConfig("v1.2.3")
+	cfg.AliasTags = true
+	cfg.TagPrefix = "v"
+	j := journal.New()
+	ctx := journal.NewContext(context.Background(), j)
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.RevParseArgs("refs/tags/v1.2.3")), gitcmd.FakeResult{Err: gitcmd.Fail(128)}).
+		Stub(key(gitcmd.RevParseArgs("refs/tags/v1")), gitcmd.FakeResult{Stdout: "1111111\n"}).
+		Stub(key(gitcmd.RevParseArgs("refs/tags/v1.2")), gitcmd.FakeResult{Stdout: "2222222\n"})
+	tm := NewTagManagerWithRunner(cfg, f)
+
+	if err := tm.HandleGitTag(ctx, output.NewResult()); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if err := j.Rollback(context.Background()); err != nil {
+		t.Fatalf("Rollback() error = %v, want nil", err)
+	}
+
+	for _, want := range []string{
+		key(gitcmd.DeleteRemoteTagArgs("v1.2.3")),
+		key(gitcmd.PushRefToArgs("refs/tags/v1", "1111111", gitcmd.OptForce)),
+		key(gitcmd.PushRefToArgs("refs/tags/v1.2", "2222222", gitcmd.OptForce)),
+	} {
+		if !f.Ran(want) {
+			t.Errorf("Keys() = %v, want %q", f.Keys(), want)
+		}
+	}
+}
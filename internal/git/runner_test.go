@@ -2,10 +2,15 @@ package git
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/lock"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 	"github.com/somaz94/go-git-commit-action/internal/output"
 )
@@ -16,6 +21,11 @@ func key(args []string) string {
 	return gitcmd.Call{Name: gitcmd.CmdGit, Args: args}.Key()
 }
 
+// commitKey renders the commit of message as made without run_hooks.
+func commitKey(message string) string {
+	return key(append(gitcmd.CommitArgs(message), gitcmd.OptNoVerify))
+}
+
 // baseConfig returns a config that keeps the workflow on the direct-commit path
 // and out of any working-directory change.
 func baseConfig() *config.GitConfig {
@@ -196,7 +206,7 @@ func TestHandleBranch_ExistingLocalBranchDoesNothing(t *testing.T) {
 	// Both probes succeed → the branch is already checked out.
 	f := gitcmd.NewFakeRunner()
 
-	if err := handleBranch(f, baseConfig()); err != nil {
+	if _, err := handleBranch(context.Background(), f, baseConfig()); err != nil {
 		t.Fatalf("handleBranch() error = %v, want nil", err)
 	}
 	if len(f.Calls()) != 2 {
@@ -213,7 +223,7 @@ func TestHandleBranch_CreatesMissingBranch(t *testing.T) {
 		Stub(key(gitcmd.RevParseArgs("feature")), gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
 		Stub(key(gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, "feature")), gitcmd.FakeResult{Stdout: ""})
 
-	if err := handleBranch(f, cfg); err != nil {
+	if _, err := handleBranch(context.Background(), f, cfg); err != nil {
 		t.Fatalf("handleBranch() error = %v, want nil", err)
 	}
 	assertSequence(t, f.Keys(), []string{
@@ -230,7 +240,7 @@ func TestHandleBranch_UnreachableRemoteCreatesBranch(t *testing.T) {
 		Stub(key(gitcmd.RevParseArgs("feature")), gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
 		Stub(key(gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, "feature")), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
 
-	if err := handleBranch(f, cfg); err != nil {
+	if _, err := handleBranch(context.Background(), f, cfg); err != nil {
 		t.Fatalf("handleBranch() error = %v, want nil", err)
 	}
 	if !f.Ran(key(gitcmd.CheckoutNewBranchArgs("feature"))) {
@@ -247,7 +257,7 @@ func TestHandleBranch_ChecksOutRemoteOnlyBranch(t *testing.T) {
 		Stub(key(gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, "feature")),
 			gitcmd.FakeResult{Stdout: "9f1c0de\trefs/heads/feature\n"})
 
-	if err := handleBranch(f, cfg); err != nil {
+	if _, err := handleBranch(context.Background(), f, cfg); err != nil {
 		t.Fatalf("handleBranch() error = %v, want nil", err)
 	}
 	assertSequence(t, f.Keys(), []string{
@@ -370,7 +380,7 @@ func TestCheckIfEmpty_StatusFailure(t *testing.T) {
 	}
 }
 
-func TestCountChangedFiles(t *testing.T) {
+func TestChangedFiles_Count(t *testing.T) {
 	tests := []struct {
 		name   string
 		status string
@@ -387,52 +397,209 @@ func TestCountChangedFiles(t *testing.T) {
 			f := gitcmd.NewFakeRunner().
 				Stub(key(gitcmd.StatusPorcelainArgs()), gitcmd.FakeResult{Stdout: tt.status})
 
-			if got := countChangedFiles(f); got != tt.want {
-				t.Errorf("countChangedFiles() = %d, want %d", got, tt.want)
+			if got := len(changedFiles(f)); got != tt.want {
+				t.Errorf("len(changedFiles()) = %d, want %d", got, tt.want)
 			}
 		})
 	}
 }
 
-func TestCountChangedFiles_FailureReturnsZero(t *testing.T) {
+func TestChangedFiles_FailureReturnsNone(t *testing.T) {
 	f := &gitcmd.FakeRunner{Default: gitcmd.FakeResult{Err: gitcmd.Fail(128)}}
 
-	if got := countChangedFiles(f); got != 0 {
-		t.Errorf("countChangedFiles() = %d, want 0 when status fails", got)
+	if got := changedFiles(f); len(got) != 0 {
+		t.Errorf("changedFiles() = %v, want none when status fails", got)
+	}
+}
+
+func TestChangedFiles_Paths(t *testing.T) {
+	status := " M a.txt\n?? dir/b.txt\nR  old.txt -> new.txt\n?? \"with space.txt\"\n"
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.StatusPorcelainArgs()), gitcmd.FakeResult{Stdout: status})
+
+	want := []string{"a.txt", "dir/b.txt", "new.txt", "with space.txt"}
+	if got := changedFiles(f); !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFiles() = %v, want %v", got, want)
 	}
 }
 
 func TestCommitChanges_StagesCommitsPushesAndRecordsSHA(t *testing.T) {
 	cfg := baseConfig()
 	cfg.FilePattern = "a.txt b.txt"
+	cfg.FileExclude = "b.txt"
 	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DiffCachedNamesArgs()), gitcmd.FakeResult{Stdout: "a.txt\x00"}).
 		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "cafebabe\n"})
 	result := output.NewResult()
 
-	if err := commitChanges(f, cfg, result); err != nil {
+	if err := commitChanges(context.Background(), f, cfg, result, "", nil); err != nil {
 		t.Fatalf("commitChanges() error = %v, want nil", err)
 	}
 
 	assertSequence(t, f.Keys(), []string{
-		key(gitcmd.AddArgs("a.txt")),
-		key(gitcmd.AddArgs("b.txt")),
-		key(gitcmd.CommitArgs(cfg.CommitMessage)),
+		key(gitcmd.AddPathspecsArgs([]string{"a.txt", "b.txt", ":(exclude)b.txt"})),
+		commitKey(cfg.CommitMessage),
 		key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch)),
 		key(gitcmd.RevParseArgs("HEAD")),
 	})
 	if got := result.Get(output.KeyCommitSHA); got != "cafebabe" {
 		t.Errorf("commit_sha output = %q, want %q", got, "cafebabe")
 	}
+	if got, want := result.Get(output.KeyStagedFiles), `["a.txt"]`; got != want {
+		t.Errorf("staged_files output = %q, want %q", got, want)
+	}
+}
+
+// pre_commit_command runs before the changes are detected, and the files
+// staged before it ran are staged again.
+func TestRunGitCommitWithRunner_RunsPreCommitCommand(t *testing.T) {
+	cfg := baseConfig()
+	cfg.PreCommitCommand = "gofmt -w ."
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DiffCachedRawArgs()), gitcmd.FakeResult{
+			Stdout: ":100644 100644 " + strings.Repeat("1", 40) + " " + strings.Repeat("2", 40) + " M\x00CHANGELOG.md\x00" +
+				":100644 000000 " + strings.Repeat("3", 40) + " " + strings.Repeat("0", 40) + " D\x00old.go\x00"}).
+		Stub(key(gitcmd.StatusPorcelainArgs()), gitcmd.FakeResult{Stdout: " M main.go\n"})
+
+	if err := RunGitCommitWithRunner(context.Background(), f, cfg, output.NewResult(), nil); err != nil {
+		t.Fatalf("RunGitCommitWithRunner() error = %v, want nil", err)
+	}
+	assertSequence(t, f.Keys(), []string{
+		gitcmd.Call{Name: gitcmd.CmdShell, Args: gitcmd.ShellCommandArgs(cfg.PreCommitCommand)}.Key(),
+		key(gitcmd.AddPathspecsArgs([]string{gitcmd.PathspecLiteral + "CHANGELOG.md"})),
+		key(gitcmd.StatusPorcelainArgs()),
+		commitKey(cfg.CommitMessage),
+	})
+}
+
+// A failing pre_commit_command reports its output and is not retried.
+func TestRunGitCommitWithRunner_PreCommitCommandFails(t *testing.T) {
+	cfg := baseConfig()
+	cfg.PreCommitCommand = "make fmt"
+	cfg.RetryCount = 3
+	shell := gitcmd.Call{Name: gitcmd.CmdShell, Args: gitcmd.ShellCommandArgs(cfg.PreCommitCommand)}.Key()
+	f := gitcmd.NewFakeRunner().
+		Stub(shell, gitcmd.FakeResult{Stdout: "make: *** No rule to make target 'fmt'.\n", Err: gitcmd.Fail(2)})
+
+	err := RunGitCommitWithRunner(context.Background(), f, cfg, output.NewResult(), nil)
+	if err == nil || !strings.Contains(err.Error(), "make fmt exited with status 2:\n    make: *** No rule") {
+		t.Fatalf("RunGitCommitWithRunner() error = %v, want the command status and output", err)
+	}
+	runs := 0
+	for _, k := range f.Keys() {
+		if k == shell {
+			runs++
+		}
+	}
+	if runs != 1 {
+		t.Errorf("pre_commit_command ran %d times, want 1", runs)
+	}
+}
+
+// A guardrail violation stops the workflow after staging, before anything is
+// committed, and is not retried.
+func TestCommitChanges_GuardrailViolationBlocksCommit(t *testing.T) {
+	cfg := baseConfig()
+	cfg.ForbidBinary = true
+	sha := strings.Repeat("b", 40)
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DiffCachedRawArgs()), gitcmd.FakeResult{
+			Stdout: ":000000 100644 " + strings.Repeat("0", 40) + " " + sha + " A\x00dist/app.bin\x00"}).
+		Stub(key(gitcmd.DiffCachedNumstatArgs()), gitcmd.FakeResult{Stdout: "-\t-\tdist/app.bin\x00"}).
+		Stub(key(gitcmd.CatFileSizeArgs(sha)), gitcmd.FakeResult{Stdout: "2048\n"})
+
+	err := commitChanges(context.Background(), f, cfg, output.NewResult(), "", nil)
+	if err == nil || !strings.Contains(err.Error(), "dist/app.bin: forbid_binary") {
+		t.Fatalf("commitChanges() error = %v, want a forbid_binary violation", err)
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("commitChanges() error = %v, want a permanent error", err)
+	}
+	if f.Ran(commitKey(cfg.CommitMessage)) {
+		t.Errorf("Keys() = %v, want no commit", f.Keys())
+	}
+}
+
+func TestHandleBranch_ReturnsRemoteSHA(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Branch = "feature"
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.LsRemoteHeadsArgs(gitcmd.RefOrigin, "feature")),
+			gitcmd.FakeResult{Stdout: "9f1c0de\trefs/heads/feature\n"})
+
+	sha, err := handleBranch(context.Background(), f, cfg)
+	if err != nil {
+		t.Fatalf("handleBranch() error = %v, want nil", err)
+	}
+	if sha != "9f1c0de" {
+		t.Errorf("handleBranch() = %q, want the ls-remote SHA", sha)
+	}
+}
+
+// A branch the action just pushed stands at HEAD.
+func TestHandleBranch_NewBranchReturnsHEAD(t *testing.T) {
+	cfg := baseConfig()
+	cfg.Branch = "feature"
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.RevParseArgs("feature")), gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
+		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "cafebabe\n"})
+
+	sha, err := handleBranch(context.Background(), f, cfg)
+	if err != nil {
+		t.Fatalf("handleBranch() error = %v, want nil", err)
+	}
+	if sha != "cafebabe" {
+		t.Errorf("handleBranch() = %q, want HEAD", sha)
+	}
+}
+
+func TestCommitChanges_PushForce(t *testing.T) {
+	tests := []struct {
+		name  string
+		force string
+		want  []string
+	}{
+		{
+			name:  "lease",
+			force: config.PushForceLease,
+			want:  gitcmd.PushArgs(gitcmd.RefOrigin, "main", gitcmd.ForceWithLeaseOpt("main", "9f1c0de")),
+		},
+		{
+			name:  "force",
+			force: config.PushForceForce,
+			want:  gitcmd.PushArgs(gitcmd.RefOrigin, "main", gitcmd.OptForce),
+		},
+		{
+			name:  "none",
+			force: config.PushForceNone,
+			want:  gitcmd.PushArgs(gitcmd.RefOrigin, "main"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseConfig()
+			cfg.PushForce = tt.force
+			f := gitcmd.NewFakeRunner()
+
+			if err := commitChanges(context.Background(), f, cfg, output.NewResult(), "9f1c0de", nil); err != nil {
+				t.Fatalf("commitChanges() error = %v, want nil", err)
+			}
+			if !f.Ran(key(tt.want)) {
+				t.Errorf("Keys() = %v, want %v", f.Keys(), tt.want)
+			}
+		})
+	}
 }
 
 func TestCommitChanges_StageFailureAborts(t *testing.T) {
 	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.AddArgs(".")), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
+		Stub(key(gitcmd.AddPathspecsArgs([]string{"."})), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
 
-	if err := commitChanges(f, baseConfig(), output.NewResult()); err == nil {
+	if err := commitChanges(context.Background(), f, baseConfig(), output.NewResult(), "", nil); err == nil {
 		t.Fatal("commitChanges() error = nil, want the staging failure")
 	}
-	if f.Ran(key(gitcmd.CommitArgs("chore: auto commit"))) {
+	if f.Ran(commitKey("chore: auto commit")) {
 		t.Error("commit ran after a staging failure, want it skipped")
 	}
 }
@@ -440,10 +607,10 @@ func TestCommitChanges_StageFailureAborts(t *testing.T) {
 func TestStageFiles_DelegatesToShared(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 
-	if err := StageFiles(f, "x.txt"); err != nil {
+	if err := StageFiles(f, []string{"x.txt"}, nil); err != nil {
 		t.Fatalf("StageFiles() error = %v, want nil", err)
 	}
-	if !f.Ran(key(gitcmd.AddArgs("x.txt"))) {
+	if !f.Ran(key(gitcmd.AddPathspecsArgs([]string{"x.txt"}))) {
 		t.Errorf("Keys() = %v, want a git add for the pattern", f.Keys())
 	}
 }
@@ -455,7 +622,7 @@ func TestRunGitCommitWithRunner_SkipsWhenEmpty(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 	result := output.NewResult()
 
-	if err := RunGitCommitWithRunner(context.Background(), f, cfg, result); err != nil {
+	if err := RunGitCommitWithRunner(context.Background(), f, cfg, result, nil); err != nil {
 		t.Fatalf("RunGitCommitWithRunner() error = %v, want nil", err)
 	}
 	if got := result.Get(output.KeySkipped); got != "true" {
@@ -464,7 +631,7 @@ func TestRunGitCommitWithRunner_SkipsWhenEmpty(t *testing.T) {
 	if got := result.Get(output.KeyChangedFiles); got != "0" {
 		t.Errorf("changed_files output = %q, want %q", got, "0")
 	}
-	if f.Ran(key(gitcmd.CommitArgs(cfg.CommitMessage))) {
+	if f.Ran(commitKey(cfg.CommitMessage)) {
 		t.Error("a commit was issued on the skip path, want none")
 	}
 }
@@ -477,7 +644,7 @@ func TestRunGitCommitWithRunner_CommitsWhenChangesExist(t *testing.T) {
 		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "abc1234\n"})
 	result := output.NewResult()
 
-	if err := RunGitCommitWithRunner(context.Background(), f, cfg, result); err != nil {
+	if err := RunGitCommitWithRunner(context.Background(), f, cfg, result, nil); err != nil {
 		t.Fatalf("RunGitCommitWithRunner() error = %v, want nil", err)
 	}
 	if got := result.Get(output.KeySkipped); got != "false" {
@@ -486,18 +653,39 @@ func TestRunGitCommitWithRunner_CommitsWhenChangesExist(t *testing.T) {
 	if got := result.Get(output.KeyChangedFiles); got != "1" {
 		t.Errorf("changed_files output = %q, want %q", got, "1")
 	}
-	if !f.Ran(key(gitcmd.CommitArgs(cfg.CommitMessage))) {
+	if !f.Ran(commitKey(cfg.CommitMessage)) {
 		t.Errorf("Keys() = %v, want a commit to be issued", f.Keys())
 	}
 }
 
+func TestRunGitCommitWithRunner_RendersCommitMessageTemplate(t *testing.T) {
+	t.Setenv("GITHUB_RUN_ID", "42")
+	cfg := baseConfig()
+	cfg.CommitMessage = "chore: update {{ len .ChangedFiles }} files on {{ .Branch }} ({{ join .ChangedFiles \", \" }}, run {{ .RunID }}, base {{ .SHA }})"
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.StatusPorcelainArgs()), gitcmd.FakeResult{Stdout: " M a.txt\n?? b.txt\n"}).
+		Stub(key(gitcmd.RevParseArgs("HEAD")), gitcmd.FakeResult{Stdout: "abc1234\n"})
+
+	if err := RunGitCommitWithRunner(context.Background(), f, cfg, output.NewResult(), nil); err != nil {
+		t.Fatalf("RunGitCommitWithRunner() error = %v, want nil", err)
+	}
+
+	want := "chore: update 2 files on main (a.txt, b.txt, run 42, base abc1234)"
+	if !f.Ran(commitKey(want)) {
+		t.Errorf("Keys() = %v, want a commit with message %q", f.Keys(), want)
+	}
+	if !strings.Contains(cfg.CommitMessage, "{{") {
+		t.Error("the configured template was overwritten, want it kept for a retry")
+	}
+}
+
 func TestRunGitCommitWithRunner_InvalidConfigFails(t *testing.T) {
 	cfg := baseConfig()
 	cfg.CreatePR = true
 	cfg.PRBranch = "" // create_pr without auto_branch requires pr_branch
 	f := gitcmd.NewFakeRunner()
 
-	if err := RunGitCommitWithRunner(context.Background(), f, cfg, output.NewResult()); err == nil {
+	if err := RunGitCommitWithRunner(context.Background(), f, cfg, output.NewResult(), nil); err == nil {
 		t.Fatal("RunGitCommitWithRunner() error = nil, want the validation failure")
 	}
 }
@@ -506,8 +694,233 @@ func TestRunGitCommitWithRunner_CancelledContext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	err := RunGitCommitWithRunner(ctx, gitcmd.NewFakeRunner(), baseConfig(), output.NewResult())
+	err := RunGitCommitWithRunner(ctx, gitcmd.NewFakeRunner(), baseConfig(), output.NewResult(), nil)
 	if err == nil {
 		t.Fatal("RunGitCommitWithRunner() error = nil, want the cancelled context to abort")
 	}
 }
+
+func TestWithRetry_StopsOnPermanentError(t *testing.T) {
+	calls := 0
+	err := withRetry(context.Background(), 3, func() error {
+		calls++
+		return errors.Permanent(errors.New("rebase", fmt.Errorf("conflicts in a.txt")))
+	})
+
+	if err == nil || !errors.IsPermanent(err) {
+		t.Fatalf("withRetry() error = %v, want the permanent error", err)
+	}
+	if calls != 1 {
+		t.Errorf("operation ran %d times, want 1", calls)
+	}
+}
+
+// With remote_lock the lock is taken once git is configured, before the
+// branch is read.
+func TestRunGitCommitWithRunner_AcquiresRemoteLock(t *testing.T) {
+	cfg := baseConfig()
+	cfg.RemoteLock, cfg.RemoteLockTTL = true, 60
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.StatusPorcelainArgs()), gitcmd.FakeResult{Stdout: " M a.txt\n"})
+	ctx := lock.NewContext(context.Background(), lock.NewLockerWithRunner(cfg, f))
+
+	if err := RunGitCommitWithRunner(ctx, f, cfg, output.NewResult(), nil); err != nil {
+		t.Fatalf("RunGitCommitWithRunner() error = %v, want nil", err)
+	}
+	// The fake commit-tree prints no SHA, so the lock commit is empty here.
+	lockPush := key(gitcmd.PushRefToArgs("refs/locks/main", "", gitcmd.ForceWithLeaseOpt("refs/locks/main", "")))
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.ConfigListArgs()),
+		lockPush,
+		key(gitcmd.RevParseArgs("main")),
+	})
+}
+
+// groupIndex simulates the working tree and index for commit_groups: changes
+// are the changed paths, with the prestaged ones staged to begin with. Adding
+// pathspecs stages the changes they match, a commit empties the index, whose
+// paths are no longer changed, and HEAD moves to the next SHA. Pathspecs are
+// literal, "." or a directory prefix.
+func groupIndex(f *gitcmd.FakeRunner, changes []string, prestaged ...string) {
+	changes = append(append([]string(nil), changes...), prestaged...)
+	index := append([]string(nil), prestaged...)
+	commits := 0
+	matches := func(pathspecs []string, path string) bool {
+		for _, spec := range pathspecs {
+			if literal, ok := strings.CutPrefix(spec, gitcmd.PathspecLiteral); ok {
+				if literal == path {
+					return true
+				}
+			} else if spec == "." || strings.HasPrefix(path, spec) {
+				return true
+			}
+		}
+		return false
+	}
+	f.Handler = func(name string, args []string) (string, error) {
+		k := key(args)
+		pathspecs := args[slices.Index(args, gitcmd.OptEndOfOptions)+1:]
+		switch {
+		case k == key(gitcmd.DiffCachedNamesArgs()):
+			return strings.Join(index, "\x00"), nil
+		case k == key(gitcmd.DiffCachedRawArgs()):
+			var out string
+			for _, path := range index {
+				out += ":100644 100644 " + strings.Repeat("1", 40) + " " + strings.Repeat("2", 40) + " M\x00" + path + "\x00"
+			}
+			return out, nil
+		case k == key(gitcmd.DiffCachedNameStatusArgs(pathspecs...)):
+			var out string
+			for _, path := range index {
+				if matches(pathspecs, path) {
+					out += "M\x00" + path + "\x00"
+				}
+			}
+			return out, nil
+		case k == key(gitcmd.ResetIndexArgs()):
+			index = nil
+		case k == key(gitcmd.RevParseArgs("HEAD")):
+			return fmt.Sprintf("sha%d\n", commits), nil
+		case len(args) > 0 && args[0] == gitcmd.SubCmdCommit:
+			commits++
+			changes = slices.DeleteFunc(changes, func(path string) bool { return slices.Contains(index, path) })
+			index = nil
+		case len(args) > 0 && args[0] == gitcmd.SubCmdAdd:
+			for _, path := range changes {
+				if matches(pathspecs, path) && !slices.Contains(index, path) {
+					index = append(index, path)
+				}
+			}
+		case len(args) > 0 && args[0] == gitcmd.SubCmdDiff && strings.Contains(strings.Join(args, " "), ".."):
+			return "M\tfile\n", nil
+		}
+		return "", nil
+	}
+}
+
+// Each group is committed on its own, then the unmatched changes together
+// with the files staged earlier, and the branch is pushed once.
+func TestCommitChanges_CommitGroups(t *testing.T) {
+	cfg := baseConfig()
+	cfg.CommitGroupSpec = "- paths: docs/\n  message: 'docs: {{ len .ChangedFiles }} file(s)'\n" +
+		"- paths: [api/, web/]\n  message: 'feat: clients'\n" +
+		"- paths: vendor/\n  message: 'chore: vendor'\n"
+	f := gitcmd.NewFakeRunner()
+	groupIndex(f, []string{"docs/a.md", "docs/b.md", "api/x.go", "main.go"}, "CHANGELOG.md")
+	result := output.NewResult()
+
+	if err := commitChanges(context.Background(), f, cfg, result, "", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+
+	push := key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch))
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.ResetIndexArgs()),
+		key(gitcmd.AddPathspecsArgs([]string{"."})),
+		key(gitcmd.ResetIndexArgs()),
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)docs/a.md", ":(literal)docs/b.md"})),
+		commitKey("docs: 2 file(s)"),
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)api/x.go"})),
+		commitKey("feat: clients"),
+		key(gitcmd.AddPathspecsArgs([]string{"."})),
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)CHANGELOG.md"})),
+		commitKey(cfg.CommitMessage),
+		push,
+	})
+	if f.Ran(commitKey("chore: vendor")) {
+		t.Error("committed a group matching no change, want it skipped")
+	}
+	pushes := 0
+	for _, k := range f.Keys() {
+		if k == push {
+			pushes++
+		}
+	}
+	if pushes != 1 {
+		t.Errorf("pushed %d times, want once", pushes)
+	}
+	if got, want := result.Get(output.KeyCommitSHAs), `["sha1","sha2","sha3"]`; got != want {
+		t.Errorf("commit_shas output = %q, want %q", got, want)
+	}
+	if got, want := result.Get(output.KeyCommitSHA), "sha3"; got != want {
+		t.Errorf("commit_sha output = %q, want %q", got, want)
+	}
+	if got, want := result.Get(output.KeyStagedFiles), `["docs/a.md","docs/b.md","api/x.go","main.go","CHANGELOG.md"]`; got != want {
+		t.Errorf("staged_files output = %q, want %q", got, want)
+	}
+}
+
+// Groups only take the changes file_pattern selects, and a group path that
+// no longer exists matches nothing instead of failing git add.
+func TestCommitChanges_CommitGroupsWithinFilePattern(t *testing.T) {
+	cfg := baseConfig()
+	cfg.FilePattern = "docs/"
+	cfg.CommitGroupSpec = "- paths: [docs/, api/, removed/]\n  message: 'docs: update'\n"
+	f := gitcmd.NewFakeRunner()
+	groupIndex(f, []string{"docs/a.md", "api/x.go"})
+	result := output.NewResult()
+
+	if err := commitChanges(context.Background(), f, cfg, result, "", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.AddPathspecsArgs([]string{"docs/"})),
+		key(gitcmd.DiffCachedNameStatusArgs("docs/", "api/", "removed/")),
+		key(gitcmd.AddPathspecsArgs([]string{":(literal)docs/a.md"})),
+		commitKey("docs: update"),
+	})
+	if f.Ran(key(gitcmd.AddPathspecsArgs([]string{"docs/", "api/", "removed/"}))) {
+		t.Errorf("Keys() = %v, want the group paths never passed to git add", f.Keys())
+	}
+	if got, want := result.Get(output.KeyStagedFiles), `["docs/a.md"]`; got != want {
+		t.Errorf("staged_files output = %q, want %q", got, want)
+	}
+}
+
+// With commit_groups_unmatched: leave, only the grouped changes are committed.
+func TestCommitChanges_CommitGroupsLeaveUnmatched(t *testing.T) {
+	cfg := baseConfig()
+	cfg.CommitGroupSpec = "- paths: docs/\n"
+	cfg.GroupsUnmatched = config.GroupsUnmatchedLeave
+	f := gitcmd.NewFakeRunner()
+	groupIndex(f, []string{"docs/a.md", "main.go"})
+	result := output.NewResult()
+
+	if err := commitChanges(context.Background(), f, cfg, result, "", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	adds := 0
+	for _, k := range f.Keys() {
+		if k == key(gitcmd.AddPathspecsArgs([]string{"."})) {
+			adds++
+		}
+	}
+	if adds != 1 {
+		t.Errorf("Keys() = %v, want file_pattern staged only to match the groups", f.Keys())
+	}
+	if got, want := result.Get(output.KeyCommitSHAs), `["sha1"]`; got != want {
+		t.Errorf("commit_shas output = %q, want %q", got, want)
+	}
+	if !f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch))) {
+		t.Errorf("Keys() = %v, want a push", f.Keys())
+	}
+}
+
+// Without any change the groups make no commit and nothing is pushed.
+func TestCommitChanges_CommitGroupsNothingToCommit(t *testing.T) {
+	cfg := baseConfig()
+	cfg.CommitGroupSpec = "- paths: docs/\n"
+	f := gitcmd.NewFakeRunner()
+	groupIndex(f, nil)
+	result := output.NewResult()
+
+	if err := commitChanges(context.Background(), f, cfg, result, "", nil); err != nil {
+		t.Fatalf("commitChanges() error = %v, want nil", err)
+	}
+	if f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, cfg.Branch))) {
+		t.Errorf("Keys() = %v, want no push", f.Keys())
+	}
+	if got := result.Get(output.KeyCommitSHAs); got != "" {
+		t.Errorf("commit_shas output = %q, want none", got)
+	}
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+)
+
+// semverPattern matches a Semantic Versioning 2.0.0 string without a prefix.
+// Numeric components must not carry leading zeros.
+var semverPattern = regexp.MustCompile(
+	`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// semVersion is a parsed semantic version. Build metadata is kept only so the
+// original tag can be reported; it never takes part in precedence.
+type semVersion struct {
+	Major, Minor, Patch int
+	Pre                 []string
+	Build               string
+}
+
+// parseSemver parses s (without prefix) as a semantic version.
+func parseSemver(s string) (semVersion, bool) {
+	m := semverPattern.FindStringSubmatch(s)
+	if m == nil {
+		return semVersion{}, false
+	}
+
+	var v semVersion
+	var err error
+	if v.Major, err = strconv.Atoi(m[1]); err != nil {
+		return semVersion{}, false
+	}
+	if v.Minor, err = strconv.Atoi(m[2]); err != nil {
+		return semVersion{}, false
+	}
+	if v.Patch, err = strconv.Atoi(m[3]); err != nil {
+		return semVersion{}, false
+	}
+	if m[4] != "" {
+		v.Pre = strings.Split(m[4], ".")
+	}
+	v.Build = m[5]
+	return v, true
+}
+
+// String renders the version without prefix or build metadata.
+func (v semVersion) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Pre) > 0 {
+		s += "-" + strings.Join(v.Pre, ".")
+	}
+	return s
+}
+
+// compare returns -1, 0 or 1 following semver precedence rules.
+func (v semVersion) compare(o semVersion) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+
+	// A version without pre-release identifiers has higher precedence.
+	switch {
+	case len(v.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		if c := comparePreIdent(v.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return sign(len(v.Pre) - len(o.Pre))
+}
+
+// comparePreIdent compares two pre-release identifiers: numeric identifiers
+// compare numerically and always sort before alphanumeric ones.
+func comparePreIdent(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return sign(an - bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func sign(d int) int {
+	switch {
+	case d < 0:
+		return -1
+	case d > 0:
+		return 1
+	}
+	return 0
+}
+
+// bump returns the next version for the given tag_bump mode. A pre-release is
+// promoted rather than skipped: bumping 2.0.0-rc.1 by major yields 2.0.0.
+func (v semVersion) bump(mode, preID string) (semVersion, error) {
+	next := semVersion{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	isPre := len(v.Pre) > 0
+
+	switch mode {
+	case config.TagBumpMajor:
+		if !isPre || v.Minor != 0 || v.Patch != 0 {
+			next = semVersion{Major: v.Major + 1}
+		}
+	case config.TagBumpMinor:
+		if !isPre || v.Patch != 0 {
+			next = semVersion{Major: v.Major, Minor: v.Minor + 1}
+		}
+	case config.TagBumpPatch:
+		if !isPre {
+			next.Patch++
+		}
+	case config.TagBumpPrerelease:
+		next.Pre = nextPrerelease(v, preID)
+		if !isPre {
+			next.Patch++
+		}
+	default:
+		return semVersion{}, fmt.Errorf("unsupported bump mode %q", mode)
+	}
+
+	return next, nil
+}
+
+// nextPrerelease computes the pre-release identifiers that follow v. The
+// counter continues when v already carries preID; otherwise it restarts at 1.
+func nextPrerelease(v semVersion, preID string) []string {
+	if len(v.Pre) >= 2 && v.Pre[0] == preID {
+		last := v.Pre[len(v.Pre)-1]
+		if n, err := strconv.Atoi(last); err == nil {
+			pre := append([]string(nil), v.Pre[:len(v.Pre)-1]...)
+			return append(pre, strconv.Itoa(n+1))
+		}
+	}
+	return []string{preID, "1"}
+}
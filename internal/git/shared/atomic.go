@@ -0,0 +1,98 @@
+package shared
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// AtomicPush collects the branch and tags prepared during a run so that they
+// are published in a single "git push --atomic": the remote either receives
+// the commit together with its tag or neither of them.
+//
+// Adding a ref again replaces it, so a retried step does not push it twice.
+type AtomicPush struct {
+	branch       string
+	branchBefore string
+	branchOpts   []string
+	tags         []string
+}
+
+// NewAtomicPush returns an empty AtomicPush.
+func NewAtomicPush() *AtomicPush {
+	return &AtomicPush{}
+}
+
+// SetBranch records branch to be pushed, with opts (such as a
+// --force-with-lease option) placed before the remote. before is the commit
+// the remote branch points at until the push, "" if it does not exist.
+func (p *AtomicPush) SetBranch(branch, before string, opts []string) {
+	p.branch = branch
+	p.branchBefore = before
+	p.branchOpts = opts
+}
+
+// Branch returns the recorded branch and the commit it pointed at before.
+func (p *AtomicPush) Branch() (branch, before string) {
+	return p.branch, p.branchBefore
+}
+
+// AddTags records tags to be pushed. Tags are force-pushed like the
+// non-atomic tag push, but through a "+" refspec so that the force does not
+// extend to the branch.
+func (p *AtomicPush) AddTags(tags ...string) {
+	for _, tag := range tags {
+		if !slices.Contains(p.tags, tag) {
+			p.tags = append(p.tags, tag)
+		}
+	}
+}
+
+// Empty reports whether nothing has been recorded.
+func (p *AtomicPush) Empty() bool {
+	return p.branch == "" && len(p.tags) == 0
+}
+
+// Refspecs returns the refspecs to push, the branch first.
+func (p *AtomicPush) Refspecs() []string {
+	var refspecs []string
+	if p.branch != "" {
+		refspecs = append(refspecs, p.branch)
+	}
+	for _, tag := range p.tags {
+		refspecs = append(refspecs, "+"+gitcmd.RefTags+tag)
+	}
+	return refspecs
+}
+
+// Push publishes the recorded refs in one atomic push. It does nothing when
+// nothing was recorded.
+//
+// A push rejected because the remote branch has moved on is not recovered
+// (rebasing would leave the tag on a commit that is not on the branch) and
+// would only be rejected again, so it is reported as a permanent error.
+func (p *AtomicPush) Push(r gitcmd.Runner) error {
+	if p.Empty() {
+		fmt.Println("  - Nothing to push")
+		return nil
+	}
+
+	args := gitcmd.PushAtomicArgs(gitcmd.RefOrigin, p.Refspecs(), p.branchOpts...)
+	err := RunStep(r, "Pushing refs atomically", gitcmd.CmdGit, args...)
+	if err == nil {
+		return nil
+	}
+	if p.branch != "" {
+		rejected, checkErr := isPushRejected(r, p.branch, err)
+		if checkErr != nil {
+			return fmt.Errorf("failed to push: %w", checkErr)
+		}
+		if rejected {
+			return errors.Permanent(errors.New("push",
+				fmt.Errorf("remote branch %s has new commits; the atomic push was rejected", p.branch)))
+		}
+	}
+	return fmt.Errorf("failed to push: %w", err)
+}
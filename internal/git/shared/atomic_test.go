@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+func TestAtomicPush_Refspecs(t *testing.T) {
+	p := NewAtomicPush()
+	p.AddTags("v1.2.3", "v1")
+	p.SetBranch("main", "", nil)
+	p.AddTags("v1.2.3")
+
+	want := []string{"main", "+refs/tags/v1.2.3", "+refs/tags/v1"}
+	if got := p.Refspecs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Refspecs() = %v, want %v", got, want)
+	}
+}
+
+func TestAtomicPush_EmptyPushesNothing(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+
+	if err := NewAtomicPush().Push(f); err != nil {
+		t.Fatalf("Push() error = %v, want nil", err)
+	}
+	if len(f.Calls()) != 0 {
+		t.Errorf("Keys() = %v, want no push", f.Keys())
+	}
+}
+
+// The branch push options, such as the lease, apply to the atomic push.
+func TestCommitAndPush_DeferredRecordsBranch(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+	p := NewAtomicPush()
+	opts := CommitPushOptions{Force: config.PushForceLease, ExpectedSHA: "9f1c0de", Deferred: p}
+
+	if _, err := CommitAndPush(f, "msg", "main", opts); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want nil", err)
+	}
+	if f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, "main", gitcmd.ForceWithLeaseOpt("main", "9f1c0de")))) {
+		t.Error("the branch was pushed, want the push deferred")
+	}
+
+	if err := p.Push(f); err != nil {
+		t.Fatalf("Push() error = %v, want nil", err)
+	}
+	want := key(gitcmd.PushAtomicArgs(gitcmd.RefOrigin, []string{"main"}, gitcmd.ForceWithLeaseOpt("main", "9f1c0de")))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want %q", f.Keys(), want)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 )
 
@@ -24,22 +26,100 @@ func RunStep(r gitcmd.Runner, desc, name string, args ...string) error {
 	return nil
 }
 
-// StageFiles adds the specified files to the Git staging area.
-// It handles multiple file patterns separated by spaces.
-func StageFiles(r gitcmd.Runner, filePattern string) error {
-	fmt.Printf("  - Adding files... ")
+// StageFiles adds the files matched by patterns, minus those matched by
+// excludes, to the Git staging area in a single git add. Both are pathspecs
+// and may use pathspec magic such as ":(glob)"; excludes are turned into
+// ":(exclude)" pathspecs. Nothing is staged without patterns.
+func StageFiles(r gitcmd.Runner, patterns, excludes []string) error {
+	if len(patterns) == 0 {
+		return nil
+	}
 
-	for _, pattern := range strings.Fields(filePattern) {
-		if err := executeGitAdd(r, pattern); err != nil {
-			fmt.Println("FAILED")
-			return fmt.Errorf("failed to add pattern %s: %w", pattern, err)
-		}
+	pathspecs := append([]string(nil), patterns...)
+	for _, exclude := range excludes {
+		pathspecs = append(pathspecs, excludePathspec(exclude))
 	}
 
+	fmt.Printf("  - Adding files... ")
+	if err := r.Run(gitcmd.CmdGit, gitcmd.AddPathspecsArgs(pathspecs)...); err != nil {
+		fmt.Println("FAILED")
+		return fmt.Errorf("failed to add %s: %w", strings.Join(pathspecs, " "), err)
+	}
 	fmt.Println("Done")
 	return nil
 }
 
+// excludePathspec returns the pathspec excluding what pattern matches. Magic
+// the pattern already has is kept: ":(glob)**/*.gen.go" becomes
+// ":(exclude,glob)**/*.gen.go", and the short forms ":!x" and ":^x" already
+// exclude.
+func excludePathspec(pattern string) string {
+	if strings.HasPrefix(pattern, ":!") || strings.HasPrefix(pattern, ":^") {
+		return pattern
+	}
+	if magic, ok := strings.CutPrefix(pattern, gitcmd.PathspecMagicPrefix); ok {
+		return gitcmd.PathspecMagicPrefix + "exclude," + magic
+	}
+	return gitcmd.PathspecExclude + pattern
+}
+
+// StagedFiles lists the paths staged for the next commit. The list is empty,
+// not nil, when nothing is staged.
+func StagedFiles(r gitcmd.Runner) ([]string, error) {
+	out, err := r.Output(gitcmd.CmdGit, gitcmd.DiffCachedNamesArgs()...)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// Index modes of a StagedChange with special meaning.
+const (
+	ModeDeleted = "000000" // the path is removed
+	ModeGitlink = "160000" // the path is a submodule commit
+)
+
+// StagedChange is one staged path as reported by git diff --cached --raw.
+type StagedChange struct {
+	Path string
+	Mode string // mode in the index; ModeDeleted for a removal
+	SHA  string // object name in the index
+}
+
+// StagedChanges lists the staged paths with their index mode and object.
+func StagedChanges(r gitcmd.Runner) ([]StagedChange, error) {
+	out, err := r.Output(gitcmd.CmdGit, gitcmd.DiffCachedRawArgs()...)
+	if err != nil {
+		return nil, errors.New("list staged changes", err)
+	}
+	return parseRawDiff(string(out))
+}
+
+// parseRawDiff parses NUL-terminated "git diff --raw" output: a metadata
+// record ":<old mode> <new mode> <old sha> <new sha> <status>" followed by the
+// path, for each change.
+func parseRawDiff(out string) ([]StagedChange, error) {
+	fields := strings.Split(out, "\x00")
+	var changes []StagedChange
+	for i := 0; i+1 < len(fields); i += 2 {
+		meta := strings.Fields(strings.TrimPrefix(fields[i], ":"))
+		if len(meta) != 5 {
+			return nil, errors.New("parse staged changes", fmt.Errorf("unexpected record %q", fields[i]))
+		}
+		if strings.HasPrefix(meta[4], "U") {
+			return nil, errors.NewWithPath("parse staged changes", fields[i+1], fmt.Errorf("unmerged path"))
+		}
+		changes = append(changes, StagedChange{Path: fields[i+1], Mode: meta[1], SHA: meta[3]})
+	}
+	return changes, nil
+}
+
 // CommitPushOptions configures CommitAndPush behavior.
 type CommitPushOptions struct {
 	// SetUpstream pushes with "-u" to set the upstream tracking reference
@@ -49,6 +129,52 @@ type CommitPushOptions struct {
 	// exit code 1) as success and proceeds to push, instead of failing. Used on
 	// the direct-commit path where an empty commit must not abort the action.
 	TolerateNothingToCommit bool
+	// Sign passes "-S" so the commit is signed with the key configured by
+	// sign_commits.
+	Sign bool
+	// Signoff passes "--signoff" unless the message already carries a
+	// Signed-off-by trailer for SignoffIdentity ("Name <email>").
+	Signoff         bool
+	SignoffIdentity string
+	// Trailers are "Key: value" lines added with "--trailer". Trailers the
+	// message already has are skipped.
+	Trailers []string
+	// RunHooks lets git run the pre-commit and commit-msg hooks, reporting
+	// the output of a failing hook in the error. Otherwise, as with
+	// run_hooks: false, the commit passes "--no-verify", so that hooks
+	// installed in the checkout are bypassed.
+	RunHooks bool
+	// ConflictStrategy is how a push rejected because the remote branch moved
+	// is recovered (config.PushConflictRebase or PushConflictMerge), retrying
+	// at most PushRetries times. Empty or PushConflictFail fails the push.
+	ConflictStrategy string
+	PushRetries      int
+	// Force is the push_force mode. config.PushForceLease pushes with
+	// --force-with-lease, overwriting the remote branch only while it still
+	// points at ExpectedSHA; config.PushForceForce pushes with --force.
+	// Forced pushes are never recovered: there is no rejection to recover from.
+	// ExpectedSHA is also passed on to Deferred as the commit the branch
+	// pointed at before the push.
+	Force       string
+	ExpectedSHA string
+	// Deferred, when set, receives the branch instead of it being pushed, for
+	// atomic_push to publish it together with the tag. Nothing is recorded
+	// when there was nothing to commit.
+	Deferred *AtomicPush
+}
+
+// CommitOptionsFor returns opts with the signing, trailer, hook and push
+// recovery settings of cfg (sign_commits, commit_signoff, commit_coauthors,
+// commit_trailers, run_hooks, push_conflict_strategy, push_retries) filled in.
+func CommitOptionsFor(cfg *config.GitConfig, opts CommitPushOptions) CommitPushOptions {
+	opts.Sign = cfg.SignCommits
+	opts.Signoff = cfg.CommitSignoff
+	opts.SignoffIdentity = cfg.Identity()
+	opts.Trailers = cfg.CommitTrailers()
+	opts.RunHooks = cfg.RunHooks
+	opts.ConflictStrategy = cfg.PushConflictStrategy
+	opts.PushRetries = cfg.PushRetries
+	return opts
 }
 
 // isNothingToCommitExit reports whether err is a "git commit" exit-code-1
@@ -58,38 +184,135 @@ func isNothingToCommitExit(err error) bool {
 	return ok && code == 1
 }
 
+// isNothingToCommit reports whether the commit failed with err because
+// nothing was staged. A failing hook also exits with 1, so with hooks the
+// index is checked as well.
+func isNothingToCommit(r gitcmd.Runner, err error, hooks bool) bool {
+	if !isNothingToCommitExit(err) {
+		return false
+	}
+	if !hooks {
+		return true
+	}
+	staged, listErr := StagedFiles(r)
+	return listErr == nil && len(staged) == 0
+}
+
+// commit runs git commit with args. With hooks, the output is captured so that
+// a hook failure is reported with what the hook printed, as a permanent
+// *errors.CommandError: retrying the commit runs the same hook again.
+func commit(r gitcmd.Runner, args []string, hooks bool) error {
+	if !hooks {
+		return r.Run(gitcmd.CmdGit, args...)
+	}
+
+	out, err := r.CombinedOutput(gitcmd.CmdGit, args...)
+	fmt.Print(string(out))
+	if err != nil {
+		return CommandFailure("git commit", out, err)
+	}
+	return nil
+}
+
+// CommandFailure returns err, the failure of a command run for the user that
+// printed out, as a permanent *errors.CommandError carrying its exit status.
+func CommandFailure(command string, out []byte, err error) error {
+	code, ok := gitcmd.ExitCodeOf(err)
+	if !ok {
+		code = -1
+	}
+	return errors.Permanent(errors.NewCommandError(command, code, out, err))
+}
+
 // CommitAndPush commits the staged changes and pushes them to the remote branch.
 // Behavior is controlled by opts (upstream tracking and empty-commit tolerance).
-func CommitAndPush(r gitcmd.Runner, commitMessage, branch string, opts CommitPushOptions) error {
-	// Commit
+// It returns the remote commit the push built on, as Push does, or
+// opts.ExpectedSHA when nothing was committed.
+func CommitAndPush(r gitcmd.Runner, commitMessage, branch string, opts CommitPushOptions) (string, error) {
+	committed, err := Commit(r, commitMessage, opts)
+	if err != nil {
+		return "", err
+	}
+	if !committed {
+		return opts.ExpectedSHA, nil
+	}
+	return Push(r, branch, opts)
+}
+
+// Commit commits the staged changes with the signing, trailer and hook
+// options of opts. It reports false, without error, when nothing was staged
+// and opts.TolerateNothingToCommit is set.
+func Commit(r gitcmd.Runner, commitMessage string, opts CommitPushOptions) (bool, error) {
 	fmt.Printf("  - Committing changes... ")
-	if err := r.Run(gitcmd.CmdGit, gitcmd.CommitArgs(commitMessage)...); err != nil {
-		if opts.TolerateNothingToCommit && isNothingToCommitExit(err) {
+	commitArgs := gitcmd.CommitArgs(commitMessage)
+	if opts.Sign {
+		commitArgs = gitcmd.CommitSignedArgs(commitMessage)
+	}
+	commitArgs = append(commitArgs, commitTrailerOpts(commitMessage, opts)...)
+	if !opts.RunHooks {
+		commitArgs = append(commitArgs, gitcmd.OptNoVerify)
+	}
+	if err := commit(r, commitArgs, opts.RunHooks); err != nil {
+		if opts.TolerateNothingToCommit && isNothingToCommit(r, err, opts.RunHooks) {
 			// Nothing was committed, so this run has nothing to publish and the
 			// push is skipped. Pushing anyway would fail for reasons unrelated
 			// to the requested work — most visibly when the local branch is
 			// behind its remote, where git rejects the push as non-fast-forward
 			// and takes the whole action down with it.
 			fmt.Println("[WARN] Nothing to commit, skipping commit and push...")
-			return nil
+			return false, nil
 		}
 		fmt.Println("FAILED")
-		return fmt.Errorf("failed to commit: %w", err)
+		return false, fmt.Errorf("failed to commit: %w", err)
 	}
 	fmt.Println("Done")
+	return true, nil
+}
 
-	// Push
-	pushArgs := gitcmd.PushArgs(gitcmd.RefOrigin, branch)
-	if opts.SetUpstream {
-		pushArgs = gitcmd.PushUpstreamArgs(gitcmd.RefOrigin, branch)
+// Push pushes branch to the remote with the force and recovery options of
+// opts, or hands it to opts.Deferred. It returns the remote commit the push
+// built on: opts.ExpectedSHA, or the fetched tip of the branch when a rejected
+// push was recovered by integrating remote commits. Undoing the push must
+// restore that commit, not opts.ExpectedSHA, or the integrated commits would
+// be lost.
+func Push(r gitcmd.Runner, branch string, opts CommitPushOptions) (string, error) {
+	forceOpts := pushForceOpts(branch, opts)
+	if opts.Deferred != nil {
+		fmt.Println("  - Deferring push of " + branch + " to the atomic push")
+		opts.Deferred.SetBranch(branch, opts.ExpectedSHA, forceOpts)
+		return opts.ExpectedSHA, nil
 	}
-	if err := RunStep(r, "Pushing changes", gitcmd.CmdGit, pushArgs...); err != nil {
-		return fmt.Errorf("failed to push: %w", err)
+	pushArgs := gitcmd.PushArgs(gitcmd.RefOrigin, branch, forceOpts...)
+	if opts.SetUpstream {
+		pushArgs = gitcmd.PushUpstreamArgs(gitcmd.RefOrigin, branch, forceOpts...)
 	}
+	return pushWithRecovery(r, branch, pushArgs, opts)
+}
 
+// pushForceOpts returns the push options for opts.Force. A plain force push
+// discards whatever the remote branch holds, so it is announced loudly.
+func pushForceOpts(branch string, opts CommitPushOptions) []string {
+	switch opts.Force {
+	case config.PushForceLease:
+		return []string{gitcmd.ForceWithLeaseOpt(branch, opts.ExpectedSHA)}
+	case config.PushForceForce:
+		fmt.Println("  - [WARN] ==============================================================")
+		fmt.Printf("  - [WARN] push_force is 'force': overwriting %s on %s unconditionally.\n", branch, gitcmd.RefOrigin)
+		fmt.Println("  - [WARN] Any remote commits not in this push will be lost.")
+		fmt.Println("  - [WARN] ==============================================================")
+		return []string{gitcmd.OptForce}
+	}
 	return nil
 }
 
+// commitTrailerOpts returns the signoff and trailer options for a commit,
+// leaving out whatever the message already contains.
+func commitTrailerOpts(message string, opts CommitPushOptions) []string {
+	signoff := opts.Signoff &&
+		len(MissingTrailers(message, []string{FormatTrailer(TrailerSignedOffBy, opts.SignoffIdentity)})) > 0
+	return gitcmd.CommitTrailerOpts(signoff, MissingTrailers(message, opts.Trailers))
+}
+
 // CurrentCommitSHA retrieves the current HEAD commit SHA.
 func CurrentCommitSHA(r gitcmd.Runner) (string, error) {
 	out, err := r.Output(gitcmd.CmdGit, gitcmd.RevParseArgs("HEAD")...)
@@ -98,8 +321,3 @@ func CurrentCommitSHA(r gitcmd.Runner) (string, error) {
 	}
 	return strings.TrimSpace(string(out)), nil
 }
-
-// executeGitAdd executes the git add command for a specific pattern.
-func executeGitAdd(r gitcmd.Runner, pattern string) error {
-	return r.Run(gitcmd.CmdGit, gitcmd.AddArgs(pattern)...)
-}
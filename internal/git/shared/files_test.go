@@ -2,10 +2,8 @@ package shared
 
 import (
 	"os/exec"
-	"strings"
+	"reflect"
 	"testing"
-
-	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 )
 
 func TestIsNothingToCommitExit(t *testing.T) {
@@ -33,39 +31,29 @@ func TestIsNothingToCommitExit(t *testing.T) {
 	}
 }
 
-func TestStageFiles_EmptyPattern(t *testing.T) {
-	// strings.Fields("") returns empty slice, so no git add is called
-	// This should succeed with no operations
-	err := StageFiles(gitcmd.NewFakeRunner(), "")
-	if err != nil {
-		t.Errorf("StageFiles('') error = %v, want nil", err)
-	}
+func rawRecord(dstMode, dstSHA, status, path string) string {
+	return ":100644 " + dstMode + " 0000000000000000000000000000000000000000 " + dstSHA + " " + status + "\x00" + path + "\x00"
 }
 
-func TestStageFiles_WhitespaceOnly(t *testing.T) {
-	err := StageFiles(gitcmd.NewFakeRunner(), "   ")
+func TestParseRawDiff(t *testing.T) {
+	diff := rawRecord("120000", "aaa", "A", "link") +
+		rawRecord("160000", "bbb", "A", "vendor/lib") +
+		rawRecord("100644", "ccc", "M", "with space.txt")
+
+	changes, err := parseRawDiff(diff)
 	if err != nil {
-		t.Errorf("StageFiles('   ') error = %v, want nil", err)
+		t.Fatalf("parseRawDiff: %v", err)
 	}
-}
-
-func TestFieldsSplitting(t *testing.T) {
-	// Verify the splitting logic matches expectations
-	tests := []struct {
-		input string
-		want  int
-	}{
-		{"", 0},
-		{".", 1},
-		{"file1.txt file2.txt", 2},
-		{"  file1.txt  file2.txt  file3.txt  ", 3},
-		{"single", 1},
+	want := []StagedChange{
+		{Path: "link", Mode: "120000", SHA: "aaa"},
+		{Path: "vendor/lib", Mode: ModeGitlink, SHA: "bbb"},
+		{Path: "with space.txt", Mode: "100644", SHA: "ccc"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("parseRawDiff() = %v, want %v", changes, want)
 	}
 
-	for _, tt := range tests {
-		fields := strings.Fields(tt.input)
-		if len(fields) != tt.want {
-			t.Errorf("Fields(%q) = %d fields, want %d", tt.input, len(fields), tt.want)
-		}
+	if _, err := parseRawDiff(rawRecord("100644", "ddd", "U", "conflict.txt")); err == nil {
+		t.Error("expected an error for an unmerged path")
 	}
 }
@@ -0,0 +1,143 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// pushWithRecovery runs the push and, when it is rejected because the remote
+// branch has moved on, integrates the remote commits with opts.ConflictStrategy
+// and pushes again, up to opts.PushRetries times. It returns the remote commit
+// the successful push built on: opts.ExpectedSHA, or the fetched tip of the
+// branch once remote commits were integrated.
+//
+// A conflict while integrating is aborted, leaving the branch as it was, and
+// reported as a permanent GitError listing the conflicted paths: re-running
+// the workflow would only find nothing left to commit and skip the push.
+func pushWithRecovery(r gitcmd.Runner, branch string, pushArgs []string, opts CommitPushOptions) (string, error) {
+	base := opts.ExpectedSHA
+	for attempt := 1; ; attempt++ {
+		err := RunStep(r, "Pushing changes", gitcmd.CmdGit, pushArgs...)
+		if err == nil {
+			return base, nil
+		}
+		if opts.ConflictStrategy == "" || opts.ConflictStrategy == config.PushConflictFail || isForced(opts) {
+			return "", fmt.Errorf("failed to push: %w", err)
+		}
+
+		rejected, checkErr := isPushRejected(r, branch, err)
+		if checkErr != nil {
+			return "", fmt.Errorf("failed to push: %w", checkErr)
+		}
+		if !rejected {
+			return "", fmt.Errorf("failed to push: %w", err)
+		}
+		if attempt > opts.PushRetries {
+			return "", errors.Permanent(errors.New("push",
+				fmt.Errorf("remote branch %s kept moving; rejected %d times", branch, attempt)))
+		}
+
+		fmt.Printf("  - [WARN] Push rejected, %s has new commits (retry %d/%d)\n", branch, attempt, opts.PushRetries)
+		if err := integrateRemote(r, branch, opts.ConflictStrategy); err != nil {
+			return "", err
+		}
+		if base, err = remoteTip(r, branch); err != nil {
+			return "", err
+		}
+	}
+}
+
+// isForced reports whether opts push with --force or --force-with-lease. A
+// rejected lease means the remote branch changed since it was read; that is
+// the protection working, not something to rebase away.
+func isForced(opts CommitPushOptions) bool {
+	return opts.Force == config.PushForceLease || opts.Force == config.PushForceForce
+}
+
+// isPushRejected reports whether a failed push was rejected because the
+// remote branch has commits the local branch lacks. git push exits 1 for a
+// rejected ref (and 128 for connection or permission problems); the fetch and
+// ancestry check confirm that the remote really moved on.
+func isPushRejected(r gitcmd.Runner, branch string, pushErr error) (bool, error) {
+	if code, ok := gitcmd.ExitCodeOf(pushErr); !ok || code != 1 {
+		return false, nil
+	}
+
+	if err := RunStep(r, "Fetching "+branch, gitcmd.CmdGit, gitcmd.FetchArgs(gitcmd.RefOrigin, branch)...); err != nil {
+		return false, err
+	}
+
+	// Output keeps the check off the log; only the exit status matters.
+	_, err := r.Output(gitcmd.CmdGit, gitcmd.MergeBaseIsAncestorArgs(remoteRef(branch), "HEAD")...)
+	if err == nil {
+		// The remote is already contained in HEAD: the push failed for
+		// another reason, such as a protected branch or a declined hook.
+		return false, nil
+	}
+	if code, ok := gitcmd.ExitCodeOf(err); ok && code == 1 {
+		return true, nil
+	}
+	return false, err
+}
+
+// integrateRemote rebases onto, or merges, the fetched remote branch.
+func integrateRemote(r gitcmd.Runner, branch, strategy string) error {
+	upstream := remoteRef(branch)
+
+	args, abortArgs := gitcmd.RebaseArgs(upstream), gitcmd.RebaseAbortArgs()
+	desc := "Rebasing onto " + upstream
+	if strategy == config.PushConflictMerge {
+		args, abortArgs = gitcmd.MergeArgs(upstream), gitcmd.MergeAbortArgs()
+		desc = "Merging " + upstream
+	}
+
+	err := RunStep(r, desc, gitcmd.CmdGit, args...)
+	if err == nil {
+		return nil
+	}
+
+	// Read the conflicts before the abort clears them.
+	conflicts := conflictedPaths(r)
+	if abortErr := r.Run(gitcmd.CmdGit, abortArgs...); abortErr != nil {
+		fmt.Printf("  - [WARN] Could not abort %s: %v\n", strategy, abortErr)
+	}
+
+	if len(conflicts) > 0 {
+		err = fmt.Errorf("conflicts in %s", strings.Join(conflicts, ", "))
+	}
+	return errors.Permanent(errors.NewWithPath(strategy, upstream, err))
+}
+
+// conflictedPaths lists the paths with unresolved conflicts, or nil if they
+// cannot be determined.
+func conflictedPaths(r gitcmd.Runner) []string {
+	out, err := r.Output(gitcmd.CmdGit, gitcmd.DiffConflictedArgs()...)
+	if err != nil {
+		return nil
+	}
+	var paths []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
+
+// remoteTip returns the commit the remote-tracking ref of branch points at.
+func remoteTip(r gitcmd.Runner, branch string) (string, error) {
+	out, err := r.Output(gitcmd.CmdGit, gitcmd.RevParseArgs(remoteRef(branch))...)
+	if err != nil {
+		return "", errors.NewWithPath("rev-parse", remoteRef(branch), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// remoteRef returns the remote-tracking ref of branch on origin.
+func remoteRef(branch string) string {
+	return gitcmd.RefOrigin + "/" + branch
+}
@@ -0,0 +1,206 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// rejectingRunner returns a FakeRunner that rejects the first `rejections`
+// pushes as non-fast-forward (exit 1, remote not an ancestor of HEAD) and
+// accepts the later ones.
+func rejectingRunner(rejections int) *gitcmd.FakeRunner {
+	pushKey := key(gitcmd.PushArgs(gitcmd.RefOrigin, "main"))
+	pushes := 0
+	f := gitcmd.NewFakeRunner()
+	f.Handler = func(name string, args []string) (string, error) {
+		switch (gitcmd.Call{Name: name, Args: args}).Key() {
+		case pushKey:
+			pushes++
+			if pushes <= rejections {
+				return "", gitcmd.Fail(1)
+			}
+		case key(gitcmd.MergeBaseIsAncestorArgs("origin/main", "HEAD")):
+			return "", gitcmd.Fail(1)
+		case key(gitcmd.RevParseArgs("origin/main")):
+			return "d00dfeed\n", nil
+		}
+		return "", nil
+	}
+	return f
+}
+
+func recoveryOptions(strategy string) CommitPushOptions {
+	return CommitPushOptions{ConflictStrategy: strategy, PushRetries: 3}
+}
+
+func TestCommitAndPush_RebasesAndRetriesRejectedPush(t *testing.T) {
+	f := rejectingRunner(1)
+	opts := recoveryOptions(config.PushConflictRebase)
+	opts.ExpectedSHA = "9f1c0de"
+
+	base, err := CommitAndPush(f, "msg", "main", opts)
+	if err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want the push recovered", err)
+	}
+	// The push built on the fetched remote tip, not on the commit seen before.
+	if base != "d00dfeed" {
+		t.Errorf("CommitAndPush() = %q, want the rebased-onto tip d00dfeed", base)
+	}
+
+	want := []string{
+		commitKey("msg"),
+		key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")),
+		key(gitcmd.FetchArgs(gitcmd.RefOrigin, "main")),
+		key(gitcmd.MergeBaseIsAncestorArgs("origin/main", "HEAD")),
+		key(gitcmd.RebaseArgs("origin/main")),
+		key(gitcmd.RevParseArgs("origin/main")),
+		key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")),
+	}
+	got := f.Keys()
+	if len(got) != len(want) {
+		t.Fatalf("Keys() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Keys()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCommitAndPush_MergeStrategy(t *testing.T) {
+	f := rejectingRunner(1)
+
+	if _, err := CommitAndPush(f, "msg", "main", recoveryOptions(config.PushConflictMerge)); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want the push recovered", err)
+	}
+	if !f.Ran(key(gitcmd.MergeArgs("origin/main"))) {
+		t.Errorf("Keys() = %v, want a merge of origin/main", f.Keys())
+	}
+	if f.Ran(key(gitcmd.RebaseArgs("origin/main"))) {
+		t.Error("rebase ran with the merge strategy")
+	}
+}
+
+func TestCommitAndPush_RetriesAreBounded(t *testing.T) {
+	f := rejectingRunner(100)
+
+	_, err := CommitAndPush(f, "msg", "main", recoveryOptions(config.PushConflictRebase))
+	if err == nil {
+		t.Fatal("CommitAndPush() error = nil, want the push to give up")
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("error = %v, want it marked permanent", err)
+	}
+
+	pushes := 0
+	for _, k := range f.Keys() {
+		if k == key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")) {
+			pushes++
+		}
+	}
+	if pushes != 4 {
+		t.Errorf("pushed %d times, want 4 (1 + 3 retries)", pushes)
+	}
+}
+
+func TestCommitAndPush_RebaseConflictAborts(t *testing.T) {
+	f := rejectingRunner(1)
+	f.Handler = wrapHandler(f.Handler, map[string]string{
+		key(gitcmd.DiffConflictedArgs()): "a.txt\ndir/b c.txt\n",
+	}, key(gitcmd.RebaseArgs("origin/main")))
+
+	_, err := CommitAndPush(f, "msg", "main", recoveryOptions(config.PushConflictRebase))
+	if err == nil {
+		t.Fatal("CommitAndPush() error = nil, want the conflict reported")
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("error = %v, want it marked permanent", err)
+	}
+	if !strings.Contains(err.Error(), "a.txt, dir/b c.txt") {
+		t.Errorf("error = %q, want it to list the conflicted paths", err.Error())
+	}
+	if !f.Ran(key(gitcmd.RebaseAbortArgs())) {
+		t.Errorf("Keys() = %v, want the rebase aborted", f.Keys())
+	}
+}
+
+func TestCommitAndPush_OtherPushFailuresAreNotRecovered(t *testing.T) {
+	tests := []struct {
+		name    string
+		pushErr error
+	}{
+		{
+			name:    "connection failure",
+			pushErr: gitcmd.Fail(128),
+		},
+		{
+			name:    "remote already contained in HEAD",
+			pushErr: gitcmd.Fail(1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := gitcmd.NewFakeRunner().
+				Stub(key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")), gitcmd.FakeResult{Err: tt.pushErr})
+
+			_, err := CommitAndPush(f, "msg", "main", recoveryOptions(config.PushConflictRebase))
+			if err == nil {
+				t.Fatal("CommitAndPush() error = nil, want the push failure")
+			}
+			if errors.IsPermanent(err) {
+				t.Errorf("error = %v, want it left retryable", err)
+			}
+			if f.Ran(key(gitcmd.RebaseArgs("origin/main"))) {
+				t.Error("rebased after a push failure that was not a rejection")
+			}
+		})
+	}
+}
+
+func TestCommitAndPush_FailStrategyDoesNotRecover(t *testing.T) {
+	f := rejectingRunner(1)
+
+	if _, err := CommitAndPush(f, "msg", "main", recoveryOptions(config.PushConflictFail)); err == nil {
+		t.Fatal("CommitAndPush() error = nil, want the rejection returned")
+	}
+	if f.Ran(key(gitcmd.FetchArgs(gitcmd.RefOrigin, "main"))) {
+		t.Errorf("Keys() = %v, want no recovery with the fail strategy", f.Keys())
+	}
+}
+
+// wrapHandler layers canned stdout and a failing command over next.
+func wrapHandler(next func(string, []string) (string, error), stdout map[string]string, failing string) func(string, []string) (string, error) {
+	return func(name string, args []string) (string, error) {
+		k := gitcmd.Call{Name: name, Args: args}.Key()
+		if out, ok := stdout[k]; ok {
+			return out, nil
+		}
+		if k == failing {
+			return "", gitcmd.Fail(1)
+		}
+		return next(name, args)
+	}
+}
+
+// A rejected lease means the branch moved since it was read; rebasing onto
+// it would defeat the lease.
+func TestCommitAndPush_ForcedPushIsNotRecovered(t *testing.T) {
+	lease := gitcmd.PushArgs(gitcmd.RefOrigin, "main", gitcmd.ForceWithLeaseOpt("main", "9f1c0de"))
+	f := gitcmd.NewFakeRunner().
+		Stub(key(lease), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
+
+	opts := recoveryOptions(config.PushConflictRebase)
+	opts.Force = config.PushForceLease
+	opts.ExpectedSHA = "9f1c0de"
+	if _, err := CommitAndPush(f, "msg", "main", opts); err == nil {
+		t.Fatal("CommitAndPush() error = nil, want the rejected lease returned")
+	}
+	if f.Ran(key(gitcmd.FetchArgs(gitcmd.RefOrigin, "main"))) {
+		t.Errorf("Keys() = %v, want no recovery of a forced push", f.Keys())
+	}
+}
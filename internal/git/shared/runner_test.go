@@ -1,9 +1,12 @@
 package shared
 
 import (
+	stderrors "errors"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/somaz94/go-git-commit-action/internal/errors"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
 )
 
@@ -13,6 +16,11 @@ func key(args []string) string {
 	return gitcmd.Call{Name: gitcmd.CmdGit, Args: args}.Key()
 }
 
+// commitKey renders the commit of message as made without run_hooks.
+func commitKey(message string) string {
+	return key(append(gitcmd.CommitArgs(message), gitcmd.OptNoVerify))
+}
+
 func TestRunStep_Success(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 
@@ -36,66 +44,78 @@ func TestRunStep_PropagatesError(t *testing.T) {
 	}
 }
 
-func TestStageFiles_IssuesOneAddPerPattern(t *testing.T) {
+func TestStageFiles_IssuesOneAddForAllPatterns(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 
-	if err := StageFiles(f, "a.txt  b.txt   c.txt"); err != nil {
+	if err := StageFiles(f, []string{"a.txt", "docs/my notes.md"}, nil); err != nil {
 		t.Fatalf("StageFiles() error = %v, want nil", err)
 	}
 
-	want := []string{
-		key(gitcmd.AddArgs("a.txt")),
-		key(gitcmd.AddArgs("b.txt")),
-		key(gitcmd.AddArgs("c.txt")),
+	want := []string{key(gitcmd.AddPathspecsArgs([]string{"a.txt", "docs/my notes.md"}))}
+	if got := f.Keys(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
 	}
-	got := f.Keys()
-	if len(got) != len(want) {
-		t.Fatalf("Keys() = %v, want %v", got, want)
+}
+
+func TestStageFiles_Excludes(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+
+	excludes := []string{"vendor", ":(glob)**/*.gen.go", ":!tmp"}
+	if err := StageFiles(f, []string{"."}, excludes); err != nil {
+		t.Fatalf("StageFiles() error = %v, want nil", err)
 	}
-	for i := range want {
-		if got[i] != want[i] {
-			t.Errorf("Keys()[%d] = %q, want %q", i, got[i], want[i])
-		}
+
+	want := key(gitcmd.AddPathspecsArgs([]string{".", ":(exclude)vendor", ":(exclude,glob)**/*.gen.go", ":!tmp"}))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want %q", f.Keys(), want)
 	}
 }
 
-func TestStageFiles_StopsAtFirstFailure(t *testing.T) {
-	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.AddArgs("bad.txt")), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
+func TestStageFiles_Failure(t *testing.T) {
+	f := &gitcmd.FakeRunner{Default: gitcmd.FakeResult{Err: gitcmd.Fail(128)}}
 
-	err := StageFiles(f, "bad.txt good.txt")
+	err := StageFiles(f, []string{"bad.txt"}, []string{"x"})
 	if err == nil {
 		t.Fatal("StageFiles() error = nil, want the add failure")
 	}
-	if !strings.Contains(err.Error(), "bad.txt") {
-		t.Errorf("error = %q, want it to name the failing pattern", err.Error())
-	}
-	// The second pattern must never be attempted.
-	if f.Ran(key(gitcmd.AddArgs("good.txt"))) {
-		t.Error("staging continued past a failing pattern, want it to stop")
+	if !strings.Contains(err.Error(), "bad.txt :(exclude)x") {
+		t.Errorf("error = %q, want it to name the pathspecs", err.Error())
 	}
 }
 
 func TestStageFiles_NoPatternsIssuesNoCommands(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 
-	if err := StageFiles(f, "   "); err != nil {
+	if err := StageFiles(f, nil, []string{"vendor"}); err != nil {
 		t.Fatalf("StageFiles() error = %v, want nil", err)
 	}
 	if len(f.Calls()) != 0 {
-		t.Errorf("Calls() = %v, want no commands for a blank pattern", f.Calls())
+		t.Errorf("Calls() = %v, want no commands without patterns", f.Calls())
+	}
+}
+
+func TestStagedFiles(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.DiffCachedNamesArgs()), gitcmd.FakeResult{Stdout: "a.txt\x00docs/my notes.md\x00"})
+
+	got, err := StagedFiles(f)
+	if err != nil {
+		t.Fatalf("StagedFiles() error = %v, want nil", err)
+	}
+	if want := []string{"a.txt", "docs/my notes.md"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("StagedFiles() = %v, want %v", got, want)
 	}
 }
 
 func TestCommitAndPush_CommitThenPush(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 
-	if err := CommitAndPush(f, "chore: msg", "main", CommitPushOptions{}); err != nil {
+	if _, err := CommitAndPush(f, "chore: msg", "main", CommitPushOptions{}); err != nil {
 		t.Fatalf("CommitAndPush() error = %v, want nil", err)
 	}
 
 	want := []string{
-		key(gitcmd.CommitArgs("chore: msg")),
+		commitKey("chore: msg"),
 		key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")),
 	}
 	got := f.Keys()
@@ -112,7 +132,7 @@ func TestCommitAndPush_CommitThenPush(t *testing.T) {
 func TestCommitAndPush_SetUpstream(t *testing.T) {
 	f := gitcmd.NewFakeRunner()
 
-	if err := CommitAndPush(f, "msg", "feature", CommitPushOptions{SetUpstream: true}); err != nil {
+	if _, err := CommitAndPush(f, "msg", "feature", CommitPushOptions{SetUpstream: true}); err != nil {
 		t.Fatalf("CommitAndPush() error = %v, want nil", err)
 	}
 
@@ -122,14 +142,65 @@ func TestCommitAndPush_SetUpstream(t *testing.T) {
 	}
 }
 
+func TestCommitAndPush_Sign(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+
+	if _, err := CommitAndPush(f, "msg", "main", CommitPushOptions{Sign: true}); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want nil", err)
+	}
+
+	want := key(append(gitcmd.CommitSignedArgs("msg"), gitcmd.OptNoVerify))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want it to contain %q", f.Keys(), want)
+	}
+}
+
+func TestCommitAndPush_SignoffAndTrailers(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+	opts := CommitPushOptions{
+		Signoff:         true,
+		SignoffIdentity: "Bot <bot@example.com>",
+		Trailers:        []string{"Co-authored-by: Jane <jane@example.com>", "Refs: #1"},
+	}
+
+	if _, err := CommitAndPush(f, "msg", "main", opts); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want nil", err)
+	}
+
+	want := key(append(gitcmd.CommitArgs("msg"), append(gitcmd.CommitTrailerOpts(true, opts.Trailers), gitcmd.OptNoVerify)...))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want it to contain %q", f.Keys(), want)
+	}
+}
+
+// Trailers already in the message, the sign-off included, are not repeated.
+func TestCommitAndPush_SkipsExistingTrailers(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+	message := "msg\n\nSigned-off-by: Bot <bot@example.com>\nRefs: #1"
+	opts := CommitPushOptions{
+		Signoff:         true,
+		SignoffIdentity: "Bot <bot@example.com>",
+		Trailers:        []string{"Refs: #1", "Refs: #2"},
+	}
+
+	if _, err := CommitAndPush(f, message, "main", opts); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want nil", err)
+	}
+
+	want := key(append(gitcmd.CommitArgs(message), append(gitcmd.CommitTrailerOpts(false, []string{"Refs: #2"}), gitcmd.OptNoVerify)...))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want it to contain %q", f.Keys(), want)
+	}
+}
+
 // An empty commit is tolerated, and because nothing was committed there is
 // nothing to publish — the push must be skipped rather than attempted.
 func TestCommitAndPush_TolerateNothingToCommit(t *testing.T) {
 	// git exits 1 from "commit" when there is nothing staged.
 	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
+		Stub(commitKey("msg"), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
 
-	err := CommitAndPush(f, "msg", "main", CommitPushOptions{TolerateNothingToCommit: true})
+	_, err := CommitAndPush(f, "msg", "main", CommitPushOptions{TolerateNothingToCommit: true})
 	if err != nil {
 		t.Fatalf("CommitAndPush() error = %v, want the empty commit tolerated", err)
 	}
@@ -142,10 +213,10 @@ func TestCommitAndPush_TolerateNothingToCommit(t *testing.T) {
 // was skipped, so a stale local branch cannot fail the action.
 func TestCommitAndPush_EmptyCommitSkipsAFailingPush(t *testing.T) {
 	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
+		Stub(commitKey("msg"), gitcmd.FakeResult{Err: gitcmd.Fail(1)}).
 		Stub(key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
 
-	if err := CommitAndPush(f, "msg", "main", CommitPushOptions{TolerateNothingToCommit: true}); err != nil {
+	if _, err := CommitAndPush(f, "msg", "main", CommitPushOptions{TolerateNothingToCommit: true}); err != nil {
 		t.Fatalf("CommitAndPush() error = %v, want the rejected push never to be attempted", err)
 	}
 }
@@ -153,9 +224,9 @@ func TestCommitAndPush_EmptyCommitSkipsAFailingPush(t *testing.T) {
 // The upstream variant follows the same rule.
 func TestCommitAndPush_EmptyCommitSkipsUpstreamPush(t *testing.T) {
 	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
+		Stub(commitKey("msg"), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
 
-	if err := CommitAndPush(f, "msg", "feature", CommitPushOptions{
+	if _, err := CommitAndPush(f, "msg", "feature", CommitPushOptions{
 		SetUpstream: true, TolerateNothingToCommit: true,
 	}); err != nil {
 		t.Fatalf("CommitAndPush() error = %v, want nil", err)
@@ -167,9 +238,9 @@ func TestCommitAndPush_EmptyCommitSkipsUpstreamPush(t *testing.T) {
 
 func TestCommitAndPush_EmptyCommitFailsWhenNotTolerated(t *testing.T) {
 	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
+		Stub(commitKey("msg"), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
 
-	err := CommitAndPush(f, "msg", "main", CommitPushOptions{})
+	_, err := CommitAndPush(f, "msg", "main", CommitPushOptions{})
 	if err == nil {
 		t.Fatal("CommitAndPush() error = nil, want the commit failure")
 	}
@@ -182,19 +253,63 @@ func TestCommitAndPush_EmptyCommitFailsWhenNotTolerated(t *testing.T) {
 // when the caller opted into tolerance.
 func TestCommitAndPush_OtherExitCodeNotTolerated(t *testing.T) {
 	f := gitcmd.NewFakeRunner().
-		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
+		Stub(commitKey("msg"), gitcmd.FakeResult{Err: gitcmd.Fail(128)})
 
-	err := CommitAndPush(f, "msg", "main", CommitPushOptions{TolerateNothingToCommit: true})
+	_, err := CommitAndPush(f, "msg", "main", CommitPushOptions{TolerateNothingToCommit: true})
 	if err == nil {
 		t.Fatal("CommitAndPush() error = nil, want exit 128 to stay fatal")
 	}
 }
 
+// With run_hooks the commit runs the hooks, without --no-verify.
+func TestCommitAndPush_RunHooks(t *testing.T) {
+	f := gitcmd.NewFakeRunner()
+
+	if _, err := CommitAndPush(f, "msg", "main", CommitPushOptions{RunHooks: true}); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want nil", err)
+	}
+	if want := key(gitcmd.CommitArgs("msg")); !f.Ran(want) {
+		t.Errorf("Keys() = %v, want it to contain %q", f.Keys(), want)
+	}
+}
+
+// A failing hook exits 1 like an empty commit; with changes staged it is a
+// failure reporting the hook output, and retrying cannot fix it.
+func TestCommitAndPush_HookFailure(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Stdout: "gofmt....Failed\nmain.go\n", Err: gitcmd.Fail(1)}).
+		Stub(key(gitcmd.DiffCachedNamesArgs()), gitcmd.FakeResult{Stdout: "main.go\x00"})
+
+	_, err := CommitAndPush(f, "msg", "main", CommitPushOptions{RunHooks: true, TolerateNothingToCommit: true})
+	var cmdErr *errors.CommandError
+	if !stderrors.As(err, &cmdErr) {
+		t.Fatalf("CommitAndPush() error = %v, want a *CommandError", err)
+	}
+	if cmdErr.ExitCode != 1 || !strings.Contains(err.Error(), "gofmt....Failed") {
+		t.Errorf("CommitAndPush() error = %v, want the exit status and hook output", err)
+	}
+	if !errors.IsPermanent(err) {
+		t.Errorf("CommitAndPush() error = %v, want a permanent error", err)
+	}
+	if f.Ran(key(gitcmd.PushArgs(gitcmd.RefOrigin, "main"))) {
+		t.Errorf("Keys() = %v, want no push after a failed hook", f.Keys())
+	}
+}
+
+func TestCommitAndPush_RunHooksNothingToCommit(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.CommitArgs("msg")), gitcmd.FakeResult{Stdout: "nothing to commit\n", Err: gitcmd.Fail(1)})
+
+	if _, err := CommitAndPush(f, "msg", "main", CommitPushOptions{RunHooks: true, TolerateNothingToCommit: true}); err != nil {
+		t.Fatalf("CommitAndPush() error = %v, want the empty commit tolerated", err)
+	}
+}
+
 func TestCommitAndPush_PushFailure(t *testing.T) {
 	f := gitcmd.NewFakeRunner().
 		Stub(key(gitcmd.PushArgs(gitcmd.RefOrigin, "main")), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
 
-	err := CommitAndPush(f, "msg", "main", CommitPushOptions{})
+	_, err := CommitAndPush(f, "msg", "main", CommitPushOptions{})
 	if err == nil {
 		t.Fatal("CommitAndPush() error = nil, want the push failure")
 	}
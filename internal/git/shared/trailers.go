@@ -0,0 +1,87 @@
+package shared
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TrailerSignedOffBy is the trailer key git commit --signoff adds.
+const TrailerSignedOffBy = "Signed-off-by"
+
+// trailerLinePattern matches a "Key: value" trailer line.
+var trailerLinePattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*)$`)
+
+// FormatTrailer renders a trailer line from its key and value.
+func FormatTrailer(key, value string) string {
+	return key + ": " + value
+}
+
+// MessageTrailers returns the trailers of a commit message: the "Key: value"
+// lines of its final paragraph, when every line of that paragraph is a
+// trailer (or an indented continuation). The subject paragraph never counts.
+func MessageTrailers(message string) []string {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var trailers []string
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		switch {
+		case trailerLinePattern.MatchString(line):
+			trailers = append(trailers, strings.TrimSpace(line))
+		case len(trailers) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			// Continuation of a folded value.
+		default:
+			return nil
+		}
+	}
+	return trailers
+}
+
+// MissingTrailers returns the trailers not already present in message, in
+// order and without duplicates. Keys compare case-insensitively, as git
+// does, and values exactly.
+func MissingTrailers(message string, trailers []string) []string {
+	seen := make(map[string]bool)
+	for _, t := range MessageTrailers(message) {
+		seen[normalizeTrailer(t)] = true
+	}
+
+	var missing []string
+	for _, t := range trailers {
+		n := normalizeTrailer(t)
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		missing = append(missing, t)
+	}
+	return missing
+}
+
+// AppendTrailers adds the missing trailers to message as its final trailer
+// block, the way git commit --trailer would. It is used where the commit is
+// not made by git, such as commit_via_api.
+func AppendTrailers(message string, trailers []string) string {
+	missing := MissingTrailers(message, trailers)
+	if len(missing) == 0 {
+		return message
+	}
+
+	message = strings.TrimRight(message, "\n")
+	separator := "\n\n"
+	if len(MessageTrailers(message)) > 0 {
+		separator = "\n"
+	}
+	return message + separator + strings.Join(missing, "\n") + "\n"
+}
+
+// normalizeTrailer returns the comparison form of a trailer line.
+func normalizeTrailer(trailer string) string {
+	m := trailerLinePattern.FindStringSubmatch(strings.TrimSpace(trailer))
+	if m == nil {
+		return trailer
+	}
+	return strings.ToLower(m[1]) + ": " + strings.TrimSpace(m[2])
+}
@@ -0,0 +1,99 @@
+package shared
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMessageTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "subject only",
+			message: "fix: thing",
+			want:    nil,
+		},
+		{
+			name:    "subject that looks like a trailer",
+			message: "Refs: #1",
+			want:    nil,
+		},
+		{
+			name:    "trailer block",
+			message: "fix: thing\n\nBody text.\n\nRefs: #1\nSigned-off-by: A <a@example.com>\n",
+			want:    []string{"Refs: #1", "Signed-off-by: A <a@example.com>"},
+		},
+		{
+			name:    "folded value",
+			message: "fix: thing\n\nNote: first line\n  continued\nRefs: #1",
+			want:    []string{"Note: first line", "Refs: #1"},
+		},
+		{
+			name:    "final paragraph is prose",
+			message: "fix: thing\n\nRefs: #1\nthis is not a trailer",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageTrailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MessageTrailers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMissingTrailers(t *testing.T) {
+	message := "fix: thing\n\nco-authored-by: Jane <jane@example.com>"
+	trailers := []string{
+		"Co-authored-by: Jane <jane@example.com>", // present, key case differs
+		"Co-authored-by: Joe <joe@example.com>",
+		"Refs: #1",
+		"Refs:  #1", // duplicate within the list
+	}
+
+	want := []string{"Co-authored-by: Joe <joe@example.com>", "Refs: #1"}
+	if got := MissingTrailers(message, trailers); !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingTrailers() = %v, want %v", got, want)
+	}
+}
+
+func TestAppendTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		trailers []string
+		want     string
+	}{
+		{
+			name:     "starts a trailer block",
+			message:  "fix: thing\n",
+			trailers: []string{"Refs: #1"},
+			want:     "fix: thing\n\nRefs: #1\n",
+		},
+		{
+			name:     "extends an existing block",
+			message:  "fix: thing\n\nRefs: #1",
+			trailers: []string{"Refs: #1", "Signed-off-by: A <a@example.com>"},
+			want:     "fix: thing\n\nRefs: #1\nSigned-off-by: A <a@example.com>\n",
+		},
+		{
+			name:     "nothing missing",
+			message:  "fix: thing\n\nRefs: #1",
+			trailers: []string{"Refs: #1"},
+			want:     "fix: thing\n\nRefs: #1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AppendTrailers(tt.message, tt.trailers); got != tt.want {
+				t.Errorf("AppendTrailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
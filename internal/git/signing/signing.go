@@ -0,0 +1,227 @@
+package signing
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+const (
+	// envGNUPGHome selects the keyring gpg (and therefore git) uses.
+	envGNUPGHome = "GNUPGHOME"
+
+	// gitFormatOpenPGP is the gpg.format value for GPG keys.
+	gitFormatOpenPGP = "openpgp"
+
+	gpgKeyFile = "signing.asc"
+	sshKeyFile = "signing_key"
+
+	// permKeyFile keeps key material readable by the owner only; ssh-keygen
+	// refuses to use a private key with broader permissions.
+	permKeyFile = 0600
+)
+
+// Signer imports the signing key into an isolated location for the duration
+// of the run and points git at it. Call Cleanup when the run ends, whether it
+// succeeded or not, to remove the key material again.
+type Signer struct {
+	config *config.GitConfig
+	runner gitcmd.Runner
+
+	// dir holds the keyring or key file; empty until Setup creates it.
+	dir string
+	// configured lists the git config keys Setup set, for Cleanup to unset.
+	configured []string
+	// prevGNUPGHome is the GNUPGHOME value Setup replaced, if any.
+	prevGNUPGHome    string
+	hadPrevGNUPGHome bool
+}
+
+// configSetting is one git config value applied by Setup.
+type configSetting struct {
+	key, value, desc string
+}
+
+// NewSigner creates a new Signer instance.
+func NewSigner(cfg *config.GitConfig) *Signer {
+	return NewSignerWithRunner(cfg, gitcmd.NewExecRunner())
+}
+
+// NewSignerWithRunner creates a Signer with an explicit command Runner,
+// allowing tests to assert the emitted commands without gpg or git installed.
+func NewSignerWithRunner(cfg *config.GitConfig, r gitcmd.Runner) *Signer {
+	return &Signer{config: cfg, runner: r}
+}
+
+// Setup imports signing_key and configures gpg.format, user.signingkey and,
+// for sign_commits, the commit.gpgsign default. It does nothing unless
+// sign_commits or sign_tags is set. tag.gpgsign is left alone: the release tag
+// is signed explicitly, and the setting would turn lightweight alias tags into
+// annotated tags that need a message.
+//
+// GPG keys are imported into a fresh keyring selected through GNUPGHOME, so
+// the runner's own keyring is never touched. SSH keys are written to a private
+// file that user.signingkey points at. Keys must not be passphrase protected.
+func (s *Signer) Setup() error {
+	if !s.config.SigningEnabled() {
+		return nil
+	}
+
+	fmt.Println("\nConfiguring Signing:")
+
+	dir, err := os.MkdirTemp("", "git-signing-")
+	if err != nil {
+		return errors.New("create signing directory", err)
+	}
+	s.dir = dir
+
+	var signingKey, format string
+	switch s.config.SigningFormat {
+	case config.SigningFormatSSH:
+		format = config.SigningFormatSSH
+		signingKey, err = s.writeSSHKey()
+	default:
+		format = gitFormatOpenPGP
+		signingKey, err = s.importGPGKey()
+	}
+	if err != nil {
+		return err
+	}
+
+	settings := []configSetting{
+		{gitcmd.ConfigGPGFormat, format, "Setting signing format"},
+		{gitcmd.ConfigSigningKey, signingKey, "Setting signing key"},
+	}
+	if s.config.SignCommits {
+		settings = append(settings, configSetting{gitcmd.ConfigCommitGPGSign, "true", "Enabling commit signing"})
+	}
+
+	for _, setting := range settings {
+		if err := shared.RunStep(s.runner, setting.desc, gitcmd.CmdGit, gitcmd.ConfigSetArgs(setting.key, setting.value)...); err != nil {
+			return errors.New("configure "+setting.key, err)
+		}
+		s.configured = append(s.configured, setting.key)
+	}
+
+	return nil
+}
+
+// importGPGKey imports the armored key into a keyring under s.dir and returns
+// the fingerprint of its secret key.
+func (s *Signer) importGPGKey() (string, error) {
+	s.prevGNUPGHome, s.hadPrevGNUPGHome = os.LookupEnv(envGNUPGHome)
+	if err := os.Setenv(envGNUPGHome, s.dir); err != nil {
+		return "", errors.New("set "+envGNUPGHome, err)
+	}
+
+	keyPath := filepath.Join(s.dir, gpgKeyFile)
+	if err := os.WriteFile(keyPath, []byte(s.config.SigningKey), permKeyFile); err != nil {
+		return "", errors.NewWithPath("write signing key", keyPath, err)
+	}
+	// The armored key is only needed until gpg has imported it.
+	defer func() { _ = os.Remove(keyPath) }()
+
+	if err := shared.RunStep(s.runner, "Importing GPG key", gitcmd.CmdGPG, gitcmd.GPGImportArgs(keyPath)...); err != nil {
+		return "", errors.New("import GPG key", err)
+	}
+
+	fmt.Printf("  - Reading key fingerprint... ")
+	out, err := s.runner.Output(gitcmd.CmdGPG, gitcmd.GPGListSecretKeysArgs()...)
+	if err != nil {
+		fmt.Println("FAILED")
+		return "", errors.New("list GPG secret keys", err)
+	}
+	fingerprint := secretKeyFingerprint(string(out))
+	if fingerprint == "" {
+		fmt.Println("FAILED")
+		return "", errors.NewConfigError("signing_key", "does not contain a GPG secret key")
+	}
+	fmt.Println(fingerprint)
+
+	return fingerprint, nil
+}
+
+// writeSSHKey writes the private key to a file under s.dir and returns its
+// path, which git passes to ssh-keygen when signing.
+func (s *Signer) writeSSHKey() (string, error) {
+	key := s.config.SigningKey
+	// OpenSSH rejects a private key file without a trailing newline.
+	if !strings.HasSuffix(key, "\n") {
+		key += "\n"
+	}
+
+	fmt.Printf("  - Writing SSH key... ")
+	keyPath := filepath.Join(s.dir, sshKeyFile)
+	if err := os.WriteFile(keyPath, []byte(key), permKeyFile); err != nil {
+		fmt.Println("FAILED")
+		return "", errors.NewWithPath("write signing key", keyPath, err)
+	}
+	fmt.Println("Done")
+
+	return keyPath, nil
+}
+
+// Cleanup removes the key material and the signing configuration added by
+// Setup. Failures are reported as warnings only: cleanup runs at the end of
+// the action and must not mask the result of the run itself.
+func (s *Signer) Cleanup() {
+	if s.dir == "" {
+		return
+	}
+
+	fmt.Println("\nCleaning Up Signing Key:")
+
+	if s.config.SigningFormat != config.SigningFormatSSH {
+		// The agent caches the unlocked key and keeps sockets in the keyring
+		// directory; stop it before the directory goes away.
+		if err := shared.RunStep(s.runner, "Stopping gpg-agent", gitcmd.CmdGPGConf, gitcmd.GPGKillAgentArgs()...); err != nil {
+			fmt.Printf("  - [WARN] Could not stop gpg-agent: %v\n", err)
+		}
+		if s.hadPrevGNUPGHome {
+			_ = os.Setenv(envGNUPGHome, s.prevGNUPGHome)
+		} else {
+			_ = os.Unsetenv(envGNUPGHome)
+		}
+	}
+
+	for _, key := range s.configured {
+		if err := s.runner.Run(gitcmd.CmdGit, gitcmd.ConfigUnsetArgs(key)...); err != nil {
+			fmt.Printf("  - [WARN] Could not unset %s: %v\n", key, err)
+		}
+	}
+	s.configured = nil
+
+	fmt.Printf("  - Removing key material... ")
+	if err := os.RemoveAll(s.dir); err != nil {
+		fmt.Printf("[WARN] %v\n", err)
+	} else {
+		fmt.Println("Done")
+	}
+	s.dir = ""
+}
+
+// secretKeyFingerprint returns the fingerprint of the first secret key in gpg
+// --with-colons output: the first "fpr" record after a "sec" record.
+func secretKeyFingerprint(colons string) string {
+	inSecretKey := false
+	for _, line := range strings.Split(colons, "\n") {
+		fields := strings.Split(line, ":")
+		switch fields[0] {
+		case "sec":
+			inSecretKey = true
+		case "ssb", "pub", "sub":
+			inSecretKey = false
+		case "fpr":
+			if inSecretKey && len(fields) > 9 && fields[9] != "" {
+				return fields[9]
+			}
+		}
+	}
+	return ""
+}
@@ -2,14 +2,19 @@ package git
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
 	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/journal"
+	"github.com/somaz94/go-git-commit-action/internal/git/release"
 	"github.com/somaz94/go-git-commit-action/internal/git/shared"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/github"
 	"github.com/somaz94/go-git-commit-action/internal/output"
 )
 
@@ -18,6 +23,28 @@ import (
 type TagManager struct {
 	config *config.GitConfig
 	runner gitcmd.Runner
+	client *github.Client
+
+	// Set by resolveBumpedTag in tag_bump mode.
+	bumpResolved     bool
+	nothingToRelease bool
+	previousTag      string
+	version          string
+	bumpType         string
+	commits          []conventionalCommit
+
+	// Set by PrepareChangelog; the default release body.
+	changelog string
+
+	// Set by createTag; the alias tags moved to the new tag.
+	aliases []string
+
+	// Set by DeferPush; collects the branch and tags for atomic_push.
+	pending *shared.AtomicPush
+
+	// Set by createTag when journaling; the object each pushed tag pointed
+	// at beforehand, "" for a new tag.
+	tagsBefore map[string]string
 }
 
 // NewTagManager creates a new TagManager instance with the provided configuration.
@@ -29,7 +56,14 @@ func NewTagManager(config *config.GitConfig) *TagManager {
 // NewTagManagerWithRunner creates a TagManager with an explicit command Runner,
 // allowing tests to assert the emitted git commands without a real repository.
 func NewTagManagerWithRunner(config *config.GitConfig, r gitcmd.Runner) *TagManager {
-	return &TagManager{config: config, runner: r}
+	return NewTagManagerWithClient(config, r, github.NewClient(config.GitHubToken))
+}
+
+// NewTagManagerWithClient creates a TagManager with both seams supplied
+// explicitly: the command Runner for git and the API client for GitHub
+// releases. Tests use it to drive the release path against an httptest server.
+func NewTagManagerWithClient(config *config.GitConfig, r gitcmd.Runner, client *github.Client) *TagManager {
+	return &TagManager{config: config, runner: r, client: client}
 }
 
 // HandleGitTag orchestrates the Git tag operations based on configuration.
@@ -44,20 +78,89 @@ func (tm *TagManager) HandleGitTag(ctx context.Context, result *output.Result) e
 			return err
 		}
 
+		// In tag_bump mode the tag name is derived from the fetched tags
+		if err := tm.resolveBumpedTag(); err != nil {
+			return err
+		}
+		if tm.nothingToRelease {
+			fmt.Println("\n[WARN] No commits since the latest version. Skipping tag creation.")
+			return tm.publishBumpOutputs(result)
+		}
+
 		// Either delete or create a tag based on the configuration
 		if tm.config.DeleteTag {
 			return tm.deleteTag()
 		}
 
-		if err := tm.createTag(); err != nil {
+		if err := tm.createTag(ctx); err != nil {
 			return err
 		}
 
 		result.Set(output.KeyTagName, tm.config.TagName)
-		return nil
+		if len(tm.aliases) > 0 {
+			result.Set(output.KeyAliasTags, strings.Join(tm.aliases, ","))
+		}
+		if err := tm.publishBumpOutputs(result); err != nil {
+			return err
+		}
+
+		// With atomic_push the tag is not on the remote yet; PushPending
+		// publishes the release once it is.
+		if tm.pending != nil {
+			return nil
+		}
+
+		// Publish a GitHub release for the pushed tag
+		return tm.publishRelease(ctx, result)
 	})
 }
 
+// publishRelease creates the GitHub release for the tag when create_release
+// is set and records its id and URL. When release_assets is set the release is
+// looked up (or created if missing) and the matched files are uploaded to it.
+func (tm *TagManager) publishRelease(ctx context.Context, result *output.Result) error {
+	if !tm.config.CreateRelease && tm.config.ReleaseAssets == "" {
+		return nil
+	}
+
+	publisher := release.NewPublisherWithClient(tm.config, tm.client)
+
+	var rel release.Release
+	var err error
+	if tm.config.CreateRelease {
+		rel, err = publisher.Publish(ctx, tm.config.TagName, tm.changelog)
+	} else {
+		rel, err = publisher.EnsureRelease(ctx, tm.config.TagName, tm.changelog)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !rel.DryRun {
+		result.Set(output.KeyReleaseID, strconv.Itoa(rel.ID))
+		result.Set(output.KeyReleaseURL, rel.HTMLURL)
+	}
+
+	if tm.config.ReleaseAssets == "" {
+		return nil
+	}
+
+	assets, err := publisher.UploadAssets(ctx, rel)
+	if err != nil {
+		return err
+	}
+	if rel.DryRun {
+		return nil
+	}
+
+	data, err := json.Marshal(assets)
+	if err != nil {
+		return errors.New("encode release assets", err)
+	}
+	result.Set(output.KeyReleaseAssets, string(data))
+	return nil
+}
+
 // fetchTags retrieves all tags and references from the remote repository.
 // This ensures that tag operations have the most up-to-date information.
 func (tm *TagManager) fetchTags() error {
@@ -82,27 +185,80 @@ func (tm *TagManager) deleteTag() error {
 }
 
 // createTag creates a new Git tag and pushes it to the remote repository.
-// The tag can point to a specific commit if tag_reference is provided.
-func (tm *TagManager) createTag() error {
+// The tag can point to a specific commit if tag_reference is provided. With
+// update_alias_tags the major and minor aliases are moved to it and pushed in
+// the same atomic push.
+func (tm *TagManager) createTag(ctx context.Context) error {
 	// Determine the commit to tag
 	targetCommit, err := tm.resolveTargetCommit()
 	if err != nil {
 		return err
 	}
 
+	// Remember where the tag stood so that a rollback can restore it
+	journaling := journal.FromContext(ctx) != nil
+	if journaling {
+		tm.tagsBefore = map[string]string{}
+		tm.rememberTag(tm.config.TagName)
+	}
+
 	// Build the tag command arguments
 	tagArgs := tm.buildTagArgs(targetCommit)
 
 	// Create a human-readable description of the operation
 	desc := tm.buildTagDescription(targetCommit)
 
-	// Execute the tag creation and push commands
-	commands := []Command{
-		{gitcmd.CmdGit, tagArgs, desc},
-		{gitcmd.CmdGit, gitcmd.PushTagArgs(tm.config.TagName, true), "Pushing tag to remote"},
+	if err := ExecuteCommandBatch(tm.runner, []Command{{gitcmd.CmdGit, tagArgs, desc}}, ""); err != nil {
+		return err
 	}
 
-	return ExecuteCommandBatch(tm.runner, commands, "")
+	// Move the floating alias tags, if requested, before pushing
+	aliases, err := tm.resolveAliasTags()
+	if err != nil {
+		return err
+	}
+	tm.aliases = aliases
+	if journaling {
+		for _, alias := range aliases {
+			tm.rememberTag(alias)
+		}
+	}
+
+	if tm.pending != nil {
+		if err := ExecuteCommandBatch(tm.runner, tm.aliasCommands(aliases), ""); err != nil {
+			return err
+		}
+		fmt.Println("  - Deferring push of " + tm.config.TagName + " to the atomic push")
+		tm.pending.AddTags(append([]string{tm.config.TagName}, aliases...)...)
+		return nil
+	}
+
+	commands := append(tm.aliasCommands(aliases), tm.pushTagCommand(aliases))
+	if err := ExecuteCommandBatch(tm.runner, commands, ""); err != nil {
+		return err
+	}
+	tm.recordTagPushes(ctx)
+	return nil
+}
+
+// rememberTag records the object tag points at, which after fetchTags is
+// also where it stands on the remote, or "" if there is no such tag.
+func (tm *TagManager) rememberTag(tag string) {
+	out, err := tm.runner.Output(gitcmd.CmdGit, gitcmd.RevParseArgs(gitcmd.RefTags+tag)...)
+	if err != nil {
+		tm.tagsBefore[tag] = ""
+		return
+	}
+	tm.tagsBefore[tag] = strings.TrimSpace(string(out))
+}
+
+// recordTagPushes records the pushed tag and aliases in the journal of ctx.
+func (tm *TagManager) recordTagPushes(ctx context.Context) {
+	for _, tag := range append([]string{tm.config.TagName}, tm.aliases...) {
+		if before, ok := tm.tagsBefore[tag]; ok {
+			recordTagPush(ctx, tm.runner, tag, before)
+		}
+	}
 }
 
 // resolveTargetCommit determines the exact commit that will be tagged.
@@ -156,9 +312,19 @@ func shortenCommitSHA(sha string) string {
 // buildTagArgs constructs the arguments for the git tag command.
 // It handles different combinations of tag options based on the configuration.
 func (tm *TagManager) buildTagArgs(targetCommit string) []string {
+	// A signed tag is always annotated; the tag name stands in for a missing
+	// message.
+	message := tm.config.TagMessage
+	if tm.config.SignTags && message == "" {
+		message = tm.config.TagName
+	}
+
 	// If we have a message, create an annotated tag
-	if tm.config.TagMessage != "" {
-		args := gitcmd.TagCreateAnnotatedArgs(tm.config.TagName, tm.config.TagMessage, true)
+	if message != "" {
+		args := gitcmd.TagCreateAnnotatedArgs(tm.config.TagName, message, true)
+		if tm.config.SignTags {
+			args = gitcmd.TagCreateSignedArgs(tm.config.TagName, message, true)
+		}
 		// Insert target commit if specified (before the message)
 		if targetCommit != "" {
 			// Find the position of -m flag and insert commit before it
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// aliasTarget is the revision suffix that peels the new tag to its commit, so
+// an alias is a lightweight tag on the commit rather than a tag of a tag.
+const aliasTarget = "^{commit}"
+
+// resolveAliasTags returns the floating alias tags (v1 and v1.2 for v1.2.3)
+// that update_alias_tags should move to the tag being created.
+//
+// Pre-release tags never move an alias. An alias is also left where it is when
+// its series already holds a newer stable version, so a backport release such
+// as v1.1.5 after v1.2.0 moves v1.1 but refuses to move v1 backwards.
+func (tm *TagManager) resolveAliasTags() ([]string, error) {
+	if !tm.config.AliasTags {
+		return nil, nil
+	}
+
+	prefix := tm.config.TagPrefix
+	name := tm.config.TagName
+	v, ok := parseSemver(strings.TrimPrefix(name, prefix))
+	if !strings.HasPrefix(name, prefix) || !ok {
+		return nil, errors.NewConfigError("update_alias_tags",
+			fmt.Sprintf("tag %q is not a semantic version with prefix %q", name, prefix))
+	}
+	if len(v.Pre) > 0 {
+		fmt.Printf("  - [WARN] %s is a pre-release, leaving alias tags unchanged\n", name)
+		return nil, nil
+	}
+
+	tags, err := tm.listTags()
+	if err != nil {
+		return nil, err
+	}
+
+	series := []struct {
+		alias string
+		match func(semVersion) bool
+	}{
+		{fmt.Sprintf("%s%d", prefix, v.Major), func(o semVersion) bool {
+			return o.Major == v.Major
+		}},
+		{fmt.Sprintf("%s%d.%d", prefix, v.Major, v.Minor), func(o semVersion) bool {
+			return o.Major == v.Major && o.Minor == v.Minor
+		}},
+	}
+
+	var aliases []string
+	for _, s := range series {
+		if newest, found := newestStableTag(tags, prefix, s.match); found && newest.Version.compare(v) > 0 {
+			fmt.Printf("  - [WARN] Refusing to move %s backwards: %s is newer than %s\n", s.alias, newest.Name, name)
+			continue
+		}
+		aliases = append(aliases, s.alias)
+	}
+	return aliases, nil
+}
+
+// newestStableTag returns the highest non-pre-release semver tag with prefix
+// whose version satisfies match.
+func newestStableTag(tags []string, prefix string, match func(semVersion) bool) (semverTag, bool) {
+	var best semverTag
+	found := false
+	for _, name := range tags {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		v, ok := parseSemver(strings.TrimPrefix(name, prefix))
+		if !ok || len(v.Pre) > 0 || !match(v) {
+			continue
+		}
+		if !found || v.compare(best.Version) > 0 {
+			best = semverTag{Name: name, Version: v}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// aliasCommands returns the commands that force-move each alias to the commit
+// of the new tag.
+func (tm *TagManager) aliasCommands(aliases []string) []Command {
+	commands := make([]Command, 0, len(aliases))
+	for _, alias := range aliases {
+		args := append(gitcmd.TagCreateArgs(alias, true), tm.config.TagName+aliasTarget)
+		commands = append(commands, Command{gitcmd.CmdGit, args, fmt.Sprintf("Moving alias tag %s to %s", alias, tm.config.TagName)})
+	}
+	return commands
+}
+
+// pushTagCommand pushes the new tag, together with its aliases in one atomic
+// push when there are any, so the remote never sees the aliases moved without
+// the tag they follow.
+func (tm *TagManager) pushTagCommand(aliases []string) Command {
+	if len(aliases) == 0 {
+		return Command{gitcmd.CmdGit, gitcmd.PushTagArgs(tm.config.TagName, true), "Pushing tag to remote"}
+	}
+
+	refs := append([]string{tm.config.TagName}, aliases...)
+	return Command{gitcmd.CmdGit, gitcmd.PushTagsAtomicArgs(refs, true),
+		fmt.Sprintf("Pushing tag and aliases %s atomically", strings.Join(aliases, ", "))}
+}
@@ -0,0 +1,115 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+func aliasConfig(tagName string) *config.GitConfig {
+	cfg := tagConfig(tagName)
+	cfg.TagPrefix = "v"
+	cfg.AliasTags = true
+	return cfg
+}
+
+func TestHandleGitTag_MovesAliasTagsAtomically(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1\nv1.2\nv1.2.2\nv1.2.3\nv1.3.0-rc.1\n"})
+	tm := NewTagManagerWithRunner(aliasConfig("v1.2.3"), f)
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.TagCreateArgs("v1.2.3", true)),
+		key(append(gitcmd.TagCreateArgs("v1", true), "v1.2.3^{commit}")),
+		key(append(gitcmd.TagCreateArgs("v1.2", true), "v1.2.3^{commit}")),
+		key(gitcmd.PushTagsAtomicArgs([]string{"v1.2.3", "v1", "v1.2"}, true)),
+	})
+	if strings.Contains(strings.Join(f.Keys(), "\n"), key(gitcmd.PushTagArgs("v1.2.3", true))) {
+		t.Errorf("Keys() = %v, want the tag pushed only within the atomic push", f.Keys())
+	}
+	if got := result.Get(output.KeyAliasTags); got != "v1,v1.2" {
+		t.Errorf("alias_tags output = %q, want v1,v1.2", got)
+	}
+}
+
+func TestResolveAliasTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		tags    string
+		want    []string
+		wantErr bool
+	}{
+		{"first release", "v1.0.0", "v1.0.0\n", []string{"v1", "v1.0"}, false},
+		{"backport keeps major", "v1.1.5", "v1.1.4\nv1.1.5\nv1.2.0\n", []string{"v1.1"}, false},
+		{"older minor refused", "v1.2.1", "v1.2.1\nv1.2.7\n", nil, false},
+		{"newer prerelease ignored", "v2.0.1", "v2.0.1\nv2.1.0-rc.1\n", []string{"v2", "v2.0"}, false},
+		{"prerelease tag", "v2.0.0-rc.1", "v2.0.0-rc.1\n", nil, false},
+		{"not semver", "release-1", "release-1\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := gitcmd.NewFakeRunner().
+				Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: tt.tags})
+			tm := NewTagManagerWithRunner(aliasConfig(tt.tag), f)
+
+			got, err := tm.resolveAliasTags()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveAliasTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("resolveAliasTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGitTag_NoAliasesPushesTagAlone(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1.2.1\nv1.2.7\n"})
+	tm := NewTagManagerWithRunner(aliasConfig("v1.2.1"), f)
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.TagCreateArgs("v1.2.1", true)),
+		key(gitcmd.PushTagArgs("v1.2.1", true)),
+	})
+	if got := result.Get(output.KeyAliasTags); got != "" {
+		t.Errorf("alias_tags output = %q, want empty", got)
+	}
+}
+
+// With sign_tags the release tag is signed explicitly while the aliases stay
+// lightweight tags pointing at its commit.
+func TestHandleGitTag_SignedTagWithAliases(t *testing.T) {
+	cfg := aliasConfig("v1.2.3")
+	cfg.SignTags = true
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1.2.3\n"})
+	tm := NewTagManagerWithRunner(cfg, f)
+
+	if err := tm.HandleGitTag(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.TagCreateSignedArgs("v1.2.3", "v1.2.3", true)),
+		key(append(gitcmd.TagCreateArgs("v1", true), "v1.2.3^{commit}")),
+		key(append(gitcmd.TagCreateArgs("v1.2", true), "v1.2.3^{commit}")),
+		key(gitcmd.PushTagsAtomicArgs([]string{"v1.2.3", "v1", "v1.2"}, true)),
+	})
+}
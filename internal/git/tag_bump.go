@@ -0,0 +1,207 @@
+package git
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// bumpTypeNone is published as bump_type when tag_bump: auto finds no commits
+// since the latest version and therefore creates no tag.
+const bumpTypeNone = "none"
+
+// semverTag pairs a tag name with its parsed version.
+type semverTag struct {
+	Name    string
+	Version semVersion
+}
+
+// resolveBumpedTag computes the tag name for tag_bump mode from the tags
+// already fetched by fetchTags, and writes it back to config.TagName so the
+// create path stays unchanged. It runs once per TagManager: a retry after a
+// failed push must reuse the same name rather than bump past its own tag.
+//
+// In auto mode the bump type is inferred from the Conventional Commit headers
+// between the latest version and the tag target; with no commits in that range
+// nothingToRelease is set and no tag name is produced.
+func (tm *TagManager) resolveBumpedTag() error {
+	if tm.config.TagBump == "" || tm.bumpResolved {
+		return nil
+	}
+
+	fmt.Printf("  - Computing next %s version... ", tm.config.TagBump)
+
+	tags, err := tm.listTags()
+	if err != nil {
+		fmt.Println("FAILED")
+		return err
+	}
+
+	latest, found, err := latestSemverTag(tags, tm.config.TagPrefix)
+	if err != nil {
+		fmt.Println("FAILED")
+		return err
+	}
+
+	mode := tm.config.TagBump
+	if mode == config.TagBumpAuto {
+		commits, err := tm.commitsSince(latest.Name)
+		if err != nil {
+			fmt.Println("FAILED")
+			return err
+		}
+		tm.commits = commits
+		mode = inferBump(commits)
+		if mode == "" {
+			fmt.Println("no new commits, nothing to release")
+			tm.previousTag = latest.Name
+			tm.bumpResolved = true
+			tm.nothingToRelease = true
+			return nil
+		}
+	}
+
+	base := semVersion{}
+	if found {
+		base = latest.Version
+	}
+
+	next, err := base.bump(mode, tm.config.TagPreID)
+	if err != nil {
+		fmt.Println("FAILED")
+		return errors.NewConfigError("tag_bump", err.Error())
+	}
+
+	tm.config.TagName = tm.config.TagPrefix + next.String()
+	tm.version = next.String()
+	tm.bumpType = mode
+	tm.previousTag = latest.Name
+	tm.bumpResolved = true
+
+	if found {
+		fmt.Printf("%s -> %s\n", latest.Name, tm.config.TagName)
+	} else {
+		fmt.Printf("no previous version, starting at %s\n", tm.config.TagName)
+	}
+	return nil
+}
+
+// commitsSince lists the commits reachable from the tag target but not from
+// previousTag. An empty previousTag selects the full history of the target.
+func (tm *TagManager) commitsSince(previousTag string) ([]conventionalCommit, error) {
+	target := tm.config.TagReference
+	if target == "" {
+		target = "HEAD"
+	}
+
+	revRange := target
+	if previousTag != "" {
+		revRange = previousTag + ".." + target
+	}
+
+	out, err := tm.runner.Output(gitcmd.CmdGit, gitcmd.LogArgs(revRange)...)
+	if err != nil {
+		return nil, errors.NewWithPath("list commits", revRange, err)
+	}
+	return parseCommitLog(string(out)), nil
+}
+
+// publishBumpOutputs records the tag_bump results: the bare version, the bump
+// type applied and, in auto mode, the commits it was inferred from as a JSON
+// array so later jobs can build release notes without re-reading history.
+func (tm *TagManager) publishBumpOutputs(result *output.Result) error {
+	if !tm.bumpResolved {
+		return nil
+	}
+
+	if tm.previousTag != "" {
+		result.Set(output.KeyPreviousTag, tm.previousTag)
+	}
+	if tm.nothingToRelease {
+		result.Set(output.KeyBumpType, bumpTypeNone)
+	} else {
+		result.Set(output.KeyBumpType, tm.bumpType)
+		result.Set(output.KeyVersion, tm.version)
+	}
+
+	if tm.config.TagBump == config.TagBumpAuto {
+		commits := tm.commits
+		if commits == nil {
+			commits = []conventionalCommit{}
+		}
+		data, err := json.Marshal(commits)
+		if err != nil {
+			return errors.New("encode commit list", err)
+		}
+		result.Set(output.KeyCommits, string(data))
+	}
+	return nil
+}
+
+// listTags returns the names of all local tags.
+func (tm *TagManager) listTags() ([]string, error) {
+	out, err := tm.runner.Output(gitcmd.CmdGit, gitcmd.TagListArgs()...)
+	if err != nil {
+		return nil, errors.New("list tags", err)
+	}
+
+	var tags []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			tags = append(tags, name)
+		}
+	}
+	return tags, nil
+}
+
+// latestSemverTag finds the highest semver tag carrying prefix. It reports
+// found=false for a repository without any tags, and a ConfigError when tags
+// exist but none of them is a prefixed semver, or when the highest version is
+// held by more than one tag (e.g. v1.2.3 and v1.2.3+build.5).
+func latestSemverTag(tags []string, prefix string) (semverTag, bool, error) {
+	var best []semverTag
+
+	for _, name := range tags {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		v, ok := parseSemver(strings.TrimPrefix(name, prefix))
+		if !ok {
+			continue
+		}
+
+		candidate := semverTag{Name: name, Version: v}
+		switch {
+		case len(best) == 0:
+			best = []semverTag{candidate}
+		case v.compare(best[0].Version) > 0:
+			best = []semverTag{candidate}
+		case v.compare(best[0].Version) == 0:
+			best = append(best, candidate)
+		}
+	}
+
+	if len(best) == 0 {
+		if len(tags) > 0 {
+			return semverTag{}, false, errors.NewConfigError("tag_bump",
+				fmt.Sprintf("none of the %d existing tags is a semantic version with prefix %q; create the first version with tag_name", len(tags), prefix))
+		}
+		return semverTag{}, false, nil
+	}
+
+	if len(best) > 1 {
+		names := make([]string, len(best))
+		for i, t := range best {
+			names[i] = t.Name
+		}
+		return semverTag{}, false, errors.NewConfigError("tag_bump",
+			fmt.Sprintf("ambiguous latest version %s: held by tags %s", best[0].Version, strings.Join(names, ", ")))
+	}
+
+	return best[0], true, nil
+}
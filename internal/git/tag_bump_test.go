@@ -0,0 +1,365 @@
+package git
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/output"
+)
+
+// bumpConfig returns a config in tag_bump mode with the default "v" prefix.
+func bumpConfig(mode string) *config.GitConfig {
+	cfg := baseConfig()
+	cfg.TagBump = mode
+	cfg.TagPrefix = "v"
+	cfg.TagPreID = "rc"
+	return cfg
+}
+
+func TestParseSemver(t *testing.T) {
+	tests := []struct {
+		in   string
+		ok   bool
+		want string
+	}{
+		{"1.2.3", true, "1.2.3"},
+		{"0.0.0", true, "0.0.0"},
+		{"1.2.3-rc.1", true, "1.2.3-rc.1"},
+		{"1.2.3+build.7", true, "1.2.3"},
+		{"1.2", false, ""},
+		{"01.2.3", false, ""},
+		{"1.2.3-01", false, ""},
+		{"latest", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, ok := parseSemver(tt.in)
+			if ok != tt.ok {
+				t.Fatalf("parseSemver(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+			}
+			if ok && v.String() != tt.want {
+				t.Errorf("parseSemver(%q) = %q, want %q", tt.in, v.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSemVersion_Compare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := parseSemver(ordered[i])
+		b, _ := parseSemver(ordered[i+1])
+		if a.compare(b) >= 0 || b.compare(a) <= 0 {
+			t.Errorf("expected %s < %s", ordered[i], ordered[i+1])
+		}
+	}
+}
+
+func TestSemVersion_Bump(t *testing.T) {
+	tests := []struct {
+		from string
+		mode string
+		want string
+	}{
+		{"1.2.3", config.TagBumpMajor, "2.0.0"},
+		{"1.2.3", config.TagBumpMinor, "1.3.0"},
+		{"1.2.3", config.TagBumpPatch, "1.2.4"},
+		{"1.2.3", config.TagBumpPrerelease, "1.2.4-rc.1"},
+		{"1.2.4-rc.1", config.TagBumpPrerelease, "1.2.4-rc.2"},
+		{"1.2.4-beta.3", config.TagBumpPrerelease, "1.2.4-rc.1"},
+		{"1.2.4-rc.2", config.TagBumpPatch, "1.2.4"},
+		{"2.0.0-rc.1", config.TagBumpMajor, "2.0.0"},
+		{"1.3.0-rc.1", config.TagBumpMinor, "1.3.0"},
+		{"1.2.4-rc.1", config.TagBumpMinor, "1.3.0"},
+		{"0.0.0", config.TagBumpMinor, "0.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"/"+tt.mode, func(t *testing.T) {
+			v, _ := parseSemver(tt.from)
+			got, err := v.bump(tt.mode, "rc")
+			if err != nil {
+				t.Fatalf("bump() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("bump(%s, %s) = %s, want %s", tt.from, tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestSemverTag(t *testing.T) {
+	got, found, err := latestSemverTag([]string{"v1.2.3", "v1.10.0", "v1.9.9", "nightly", "1.99.0", "v2.0.0-rc.1"}, "v")
+	if err != nil || !found {
+		t.Fatalf("latestSemverTag() = (%v, %v, %v), want a match", got, found, err)
+	}
+	if got.Name != "v2.0.0-rc.1" {
+		t.Errorf("latestSemverTag() = %q, want v2.0.0-rc.1", got.Name)
+	}
+}
+
+func TestLatestSemverTag_NoTags(t *testing.T) {
+	_, found, err := latestSemverTag(nil, "v")
+	if err != nil || found {
+		t.Errorf("latestSemverTag(nil) = (%v, %v), want (false, nil)", found, err)
+	}
+}
+
+func TestLatestSemverTag_NonSemverHistory(t *testing.T) {
+	_, _, err := latestSemverTag([]string{"release-1", "nightly"}, "v")
+	var cfgErr *errors.ConfigError
+	if !stderrors.As(err, &cfgErr) {
+		t.Fatalf("latestSemverTag() error = %v, want a ConfigError", err)
+	}
+}
+
+func TestLatestSemverTag_Ambiguous(t *testing.T) {
+	_, _, err := latestSemverTag([]string{"v1.0.0", "v1.2.3", "v1.2.3+build.5"}, "v")
+	var cfgErr *errors.ConfigError
+	if !stderrors.As(err, &cfgErr) {
+		t.Fatalf("latestSemverTag() error = %v, want a ConfigError", err)
+	}
+	if !strings.Contains(err.Error(), "v1.2.3+build.5") {
+		t.Errorf("error = %q, want it to name the conflicting tags", err.Error())
+	}
+}
+
+func TestHandleGitTag_BumpCreatesNextVersion(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1.0.0\nv1.1.0\nv1.1.1\n"})
+	cfg := bumpConfig(config.TagBumpMinor)
+	tm := NewTagManagerWithRunner(cfg, f)
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	assertSequence(t, f.Keys(), []string{
+		key(gitcmd.FetchTagsArgs()),
+		key(gitcmd.TagListArgs()),
+		key(gitcmd.TagCreateArgs("v1.2.0", true)),
+		key(gitcmd.PushTagArgs("v1.2.0", true)),
+	})
+	if got := result.Get(output.KeyTagName); got != "v1.2.0" {
+		t.Errorf("tag_name output = %q, want v1.2.0", got)
+	}
+	if got := result.Get(output.KeyPreviousTag); got != "v1.1.1" {
+		t.Errorf("previous_tag output = %q, want v1.1.1", got)
+	}
+}
+
+func TestHandleGitTag_BumpWithoutPrefix(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "0.4.1\n"})
+	cfg := bumpConfig(config.TagBumpPatch)
+	cfg.TagPrefix = ""
+	tm := NewTagManagerWithRunner(cfg, f)
+
+	if err := tm.HandleGitTag(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if cfg.TagName != "0.4.2" {
+		t.Errorf("TagName = %q, want 0.4.2", cfg.TagName)
+	}
+}
+
+func TestHandleGitTag_BumpRetryKeepsName(t *testing.T) {
+	cfg := bumpConfig(config.TagBumpPatch)
+	cfg.RetryCount = 2
+	pushes := 0
+	f := gitcmd.NewFakeRunner()
+	f.Handler = func(name string, args []string) (string, error) {
+		switch key(args) {
+		case key(gitcmd.TagListArgs()):
+			if pushes > 0 {
+				// The failed attempt left its tag behind locally.
+				return "v1.0.0\nv1.0.1\n", nil
+			}
+			return "v1.0.0\n", nil
+		case key(gitcmd.PushTagArgs("v1.0.1", true)):
+			pushes++
+			if pushes == 1 {
+				return "", gitcmd.Fail(1)
+			}
+		}
+		return "", nil
+	}
+	tm := NewTagManagerWithRunner(cfg, f)
+
+	if err := tm.HandleGitTag(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want the retry to succeed", err)
+	}
+	if cfg.TagName != "v1.0.1" {
+		t.Errorf("TagName = %q, want v1.0.1 to survive the retry", cfg.TagName)
+	}
+}
+
+func TestHandleGitTag_BumpRejectsNonSemverHistory(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "nightly\n"})
+	tm := NewTagManagerWithRunner(bumpConfig(config.TagBumpMajor), f)
+
+	err := tm.HandleGitTag(context.Background(), output.NewResult())
+	var cfgErr *errors.ConfigError
+	if !stderrors.As(err, &cfgErr) {
+		t.Fatalf("HandleGitTag() error = %v, want a ConfigError", err)
+	}
+	for _, k := range f.Keys() {
+		if strings.HasPrefix(k, "git tag -f") {
+			t.Errorf("a tag was created (%q), want none", k)
+		}
+	}
+}
+
+// logOutput renders commits the way gitcmd.LogArgs formats them.
+func logOutput(entries ...[4]string) string {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(strings.Join(e[:], gitcmd.LogFieldSep) + gitcmd.LogRecordSep + "\n")
+	}
+	return b.String()
+}
+
+func TestParseConventionalCommit(t *testing.T) {
+	tests := []struct {
+		header, body string
+		wantType     string
+		wantScope    string
+		wantSubject  string
+		wantBreaking bool
+	}{
+		{"feat(api): add endpoint", "", "feat", "api", "add endpoint", false},
+		{"fix: handle nil", "", "fix", "", "handle nil", false},
+		{"refactor!: drop v1", "", "refactor", "", "drop v1", true},
+		{"chore: bump deps", "BREAKING CHANGE: requires go 1.26", "chore", "", "bump deps", true},
+		{"Update README", "", "", "", "Update README", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			c := parseConventionalCommit("sha", "dev", tt.header, tt.body)
+			if c.Type != tt.wantType || c.Scope != tt.wantScope || c.Subject != tt.wantSubject || c.Breaking != tt.wantBreaking {
+				t.Errorf("parseConventionalCommit(%q) = %+v", tt.header, c)
+			}
+		})
+	}
+}
+
+func TestParseCommitLog_MultilineBody(t *testing.T) {
+	out := logOutput(
+		[4]string{"aaa", "Ann", "feat: one", "line1\nline2\n"},
+		[4]string{"bbb", "Bob", "fix: two", ""},
+	)
+	commits := parseCommitLog(out)
+	if len(commits) != 2 {
+		t.Fatalf("parseCommitLog() returned %d commits, want 2", len(commits))
+	}
+	if commits[1].SHA != "bbb" || commits[1].Author != "Bob" || commits[1].Type != "fix" {
+		t.Errorf("second commit = %+v", commits[1])
+	}
+}
+
+func TestInferBump(t *testing.T) {
+	fix := conventionalCommit{Type: "fix"}
+	feat := conventionalCommit{Type: "feat"}
+	breaking := conventionalCommit{Type: "fix", Breaking: true}
+	other := conventionalCommit{Subject: "misc"}
+
+	tests := []struct {
+		name    string
+		commits []conventionalCommit
+		want    string
+	}{
+		{"none", nil, ""},
+		{"only fixes", []conventionalCommit{fix, other}, config.TagBumpPatch},
+		{"feat wins over fix", []conventionalCommit{fix, feat}, config.TagBumpMinor},
+		{"breaking wins", []conventionalCommit{feat, breaking}, config.TagBumpMajor},
+		{"non-conventional", []conventionalCommit{other}, config.TagBumpPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inferBump(tt.commits); got != tt.want {
+				t.Errorf("inferBump() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleGitTag_AutoBumpFromCommits(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1.4.2\n"}).
+		Stub(key(gitcmd.LogArgs("v1.4.2..HEAD")), gitcmd.FakeResult{Stdout: logOutput(
+			[4]string{"1111111111", "Ann", "feat(cli): add flag", ""},
+			[4]string{"2222222222", "Bob", "fix: typo", ""},
+		)})
+	tm := NewTagManagerWithRunner(bumpConfig(config.TagBumpAuto), f)
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	if !f.Ran(key(gitcmd.TagCreateArgs("v1.5.0", true))) {
+		t.Errorf("Keys() = %v, want v1.5.0 to be created", f.Keys())
+	}
+	if got := result.Get(output.KeyBumpType); got != config.TagBumpMinor {
+		t.Errorf("bump_type output = %q, want minor", got)
+	}
+	if got := result.Get(output.KeyVersion); got != "1.5.0" {
+		t.Errorf("version output = %q, want 1.5.0", got)
+	}
+	if got := result.Get(output.KeyCommits); !strings.Contains(got, `"subject":"add flag"`) || !strings.Contains(got, `"author":"Bob"`) {
+		t.Errorf("commits output = %q, want the parsed commit list", got)
+	}
+}
+
+func TestHandleGitTag_AutoBumpUsesTagReference(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	cfg := bumpConfig(config.TagBumpAuto)
+	cfg.TagReference = "release"
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1.0.0\n"}).
+		Stub(key(gitcmd.RevListArgs("release")), gitcmd.FakeResult{Stdout: sha}).
+		Stub(key(gitcmd.LogArgs("v1.0.0..release")), gitcmd.FakeResult{Stdout: logOutput(
+			[4]string{"3333333333", "Cy", "feat!: new config format", ""},
+		)})
+	tm := NewTagManagerWithRunner(cfg, f)
+
+	if err := tm.HandleGitTag(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if cfg.TagName != "v2.0.0" {
+		t.Errorf("TagName = %q, want v2.0.0", cfg.TagName)
+	}
+}
+
+func TestHandleGitTag_AutoBumpNothingToRelease(t *testing.T) {
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.TagListArgs()), gitcmd.FakeResult{Stdout: "v1.0.0\n"})
+	tm := NewTagManagerWithRunner(bumpConfig(config.TagBumpAuto), f)
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if got := result.Get(output.KeyBumpType); got != bumpTypeNone {
+		t.Errorf("bump_type output = %q, want %q", got, bumpTypeNone)
+	}
+	if got := result.Get(output.KeyTagName); got != "" {
+		t.Errorf("tag_name output = %q, want empty when nothing is released", got)
+	}
+	if got := result.Get(output.KeyCommits); got != "[]" {
+		t.Errorf("commits output = %q, want []", got)
+	}
+}
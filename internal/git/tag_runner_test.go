@@ -2,11 +2,17 @@ package git
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/somaz94/go-git-commit-action/internal/config"
 	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+	"github.com/somaz94/go-git-commit-action/internal/github"
 	"github.com/somaz94/go-git-commit-action/internal/output"
 )
 
@@ -52,6 +58,24 @@ func TestHandleGitTag_CreatesAnnotatedTag(t *testing.T) {
 	}
 }
 
+// A signed tag must be annotated, so the tag name becomes the message when
+// tag_message is empty.
+func TestHandleGitTag_CreatesSignedTag(t *testing.T) {
+	cfg := tagConfig("v2.1.0")
+	cfg.SignTags = true
+	f := gitcmd.NewFakeRunner()
+	tm := NewTagManagerWithRunner(cfg, f)
+
+	if err := tm.HandleGitTag(context.Background(), output.NewResult()); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+
+	want := key(gitcmd.TagCreateSignedArgs("v2.1.0", "v2.1.0", true))
+	if !f.Ran(want) {
+		t.Errorf("Keys() = %v, want it to contain %q", f.Keys(), want)
+	}
+}
+
 func TestHandleGitTag_DeletesTag(t *testing.T) {
 	cfg := tagConfig("v1.0.0")
 	cfg.DeleteTag = true
@@ -218,3 +242,100 @@ func TestNewTagManager_DefaultsToExecRunner(t *testing.T) {
 		t.Error("NewTagManager() runner = nil, want a default ExecRunner")
 	}
 }
+
+func TestHandleGitTag_CreatesReleaseAfterPush(t *testing.T) {
+	var posted map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/owner/repo/releases" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":9,"html_url":"https://github.com/owner/repo/releases/tag/v1.0.0"}`))
+	}))
+	defer srv.Close()
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+
+	cfg := tagConfig("v1.0.0")
+	cfg.CreateRelease = true
+	cfg.GitHubToken = "token"
+	f := gitcmd.NewFakeRunner()
+	tm := NewTagManagerWithClient(cfg, f, github.NewClientWithBaseURL("token", srv.URL))
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if posted["tag_name"] != "v1.0.0" {
+		t.Errorf("release payload = %v, want tag_name v1.0.0", posted)
+	}
+	if got := result.Get(output.KeyReleaseID); got != "9" {
+		t.Errorf("release_id output = %q, want 9", got)
+	}
+	if got := result.Get(output.KeyReleaseURL); got != "https://github.com/owner/repo/releases/tag/v1.0.0" {
+		t.Errorf("release_url output = %q", got)
+	}
+}
+
+func TestHandleGitTag_ReleaseSkippedWhenPushFails(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	cfg := tagConfig("v1.0.0")
+	cfg.CreateRelease = true
+	f := gitcmd.NewFakeRunner().
+		Stub(key(gitcmd.PushTagArgs("v1.0.0", true)), gitcmd.FakeResult{Err: gitcmd.Fail(1)})
+	tm := NewTagManagerWithClient(cfg, f, github.NewClientWithBaseURL("token", srv.URL))
+
+	if err := tm.HandleGitTag(context.Background(), output.NewResult()); err == nil {
+		t.Fatal("HandleGitTag() error = nil, want the push failure")
+	}
+	if calls != 0 {
+		t.Errorf("release API called %d times, want none after a failed push", calls)
+	}
+}
+
+func TestHandleGitTag_UploadsAssetsToExistingRelease(t *testing.T) {
+	asset := filepath.Join(t.TempDir(), "app.zip")
+	os.WriteFile(asset, []byte("zip"), 0644)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/owner/repo/releases/tags/v1.0.0":
+			_, _ = w.Write([]byte(`{"id":4,"html_url":"https://github.com/owner/repo/releases/tag/v1.0.0","upload_url":"` + srv.URL + `/upload{?name,label}"}`))
+		case "GET /repos/owner/repo/releases/4/assets":
+			_, _ = w.Write([]byte(`[]`))
+		case "POST /upload":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id":12,"name":"app.zip","browser_download_url":"https://dl/app.zip","content_type":"application/zip","size":3}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("GITHUB_REPOSITORY", "owner/repo")
+
+	cfg := tagConfig("v1.0.0")
+	cfg.ReleaseAssets = asset
+	cfg.GitHubToken = "token"
+	tm := NewTagManagerWithClient(cfg, gitcmd.NewFakeRunner(), github.NewClientWithBaseURL("token", srv.URL))
+	result := output.NewResult()
+
+	if err := tm.HandleGitTag(context.Background(), result); err != nil {
+		t.Fatalf("HandleGitTag() error = %v, want nil", err)
+	}
+	if got := result.Get(output.KeyReleaseID); got != "4" {
+		t.Errorf("release_id output = %q, want 4", got)
+	}
+	want := `[{"id":12,"name":"app.zip","url":"https://dl/app.zip","content_type":"application/zip","size":3}]`
+	if got := result.Get(output.KeyReleaseAssets); got != want {
+		t.Errorf("release_assets output = %s, want %s", got, want)
+	}
+}
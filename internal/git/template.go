@@ -0,0 +1,52 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/somaz94/go-git-commit-action/internal/config"
+	"github.com/somaz94/go-git-commit-action/internal/errors"
+	"github.com/somaz94/go-git-commit-action/internal/git/shared"
+	"github.com/somaz94/go-git-commit-action/internal/gitcmd"
+)
+
+// renderTemplates returns a copy of cfg with commit_message, pr_title and
+// pr_body rendered as templates. Validate has already rejected templates that
+// do not parse, so an error here means the run data itself was unusable.
+func renderTemplates(r gitcmd.Runner, cfg *config.GitConfig, changedFiles []string) (*config.GitConfig, error) {
+	if !hasTemplate(cfg.CommitMessage, cfg.PRTitle, cfg.PRBody) {
+		return cfg, nil
+	}
+
+	// HEAD does not exist yet in a repository without commits.
+	sha, _ := shared.CurrentCommitSHA(r)
+	data := config.NewTemplateData(cfg.Branch, sha, changedFiles)
+
+	rendered := *cfg
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"commit_message", &rendered.CommitMessage},
+		{"pr_title", &rendered.PRTitle},
+		{"pr_body", &rendered.PRBody},
+	}
+	for _, f := range fields {
+		text, err := config.RenderTemplate(f.name, *f.value, data)
+		if err != nil {
+			return nil, errors.NewConfigError(f.name, fmt.Sprintf("render template: %v", err))
+		}
+		*f.value = text
+	}
+	return &rendered, nil
+}
+
+// hasTemplate reports whether any of texts contains a template action.
+func hasTemplate(texts ...string) bool {
+	for _, text := range texts {
+		if strings.Contains(text, "{{") {
+			return true
+		}
+	}
+	return false
+}
@@ -4,25 +4,39 @@ package gitcmd
 const (
 	// Base command
 	CmdGit = "git"
+
+	// Signing tools
+	CmdGPG     = "gpg"
+	CmdGPGConf = "gpgconf"
+
+	// Shell running pre_commit_command
+	CmdShell = "sh"
 )
 
 // Git subcommands
 const (
-	SubCmdConfig   = "config"
-	SubCmdCommit   = "commit"
-	SubCmdPush     = "push"
-	SubCmdFetch    = "fetch"
-	SubCmdCheckout = "checkout"
-	SubCmdTag      = "tag"
-	SubCmdStatus   = "status"
-	SubCmdAdd      = "add"
-	SubCmdStash    = "stash"
-	SubCmdReset    = "reset"
-	SubCmdRevParse = "rev-parse"
-	SubCmdLsRemote = "ls-remote"
-	SubCmdDiff     = "diff"
-	SubCmdRevList  = "rev-list"
-	SubCmdRemote   = "remote"
+	SubCmdConfig     = "config"
+	SubCmdCommit     = "commit"
+	SubCmdPush       = "push"
+	SubCmdFetch      = "fetch"
+	SubCmdCheckout   = "checkout"
+	SubCmdTag        = "tag"
+	SubCmdStatus     = "status"
+	SubCmdAdd        = "add"
+	SubCmdStash      = "stash"
+	SubCmdReset      = "reset"
+	SubCmdRevParse   = "rev-parse"
+	SubCmdLsRemote   = "ls-remote"
+	SubCmdDiff       = "diff"
+	SubCmdRevList    = "rev-list"
+	SubCmdRemote     = "remote"
+	SubCmdLog        = "log"
+	SubCmdDescribe   = "describe"
+	SubCmdCatFile    = "cat-file"
+	SubCmdRebase     = "rebase"
+	SubCmdMerge      = "merge"
+	SubCmdMergeBase  = "merge-base"
+	SubCmdCommitTree = "commit-tree"
 )
 
 // Git global options
@@ -42,6 +56,35 @@ const (
 	OptNameStatus   = "--name-status"
 	OptDeleteRemote = "--delete"
 	OptSetURL       = "set-url"
+	OptAtomic       = "--atomic"
+	OptUnset        = "--unset"
+	OptSoft         = "--soft"
+	OptCached       = "--cached"
+	OptRaw          = "--raw"
+	OptNullTerm     = "-z"
+	OptNoRenames    = "--no-renames"
+	OptNoAbbrev     = "--no-abbrev"
+	ObjectBlob      = "blob"
+	ObjectCommit    = "commit"
+	OptAutostash    = "--autostash"
+	OptAbort        = "--abort"
+	OptNoEdit       = "--no-edit"
+	OptIsAncestor   = "--is-ancestor"
+	OptUnmerged     = "--diff-filter=U"
+	OptForceLease   = "--force-with-lease"
+	OptEndOfOptions = "--"
+	OptNumstat      = "--numstat"
+	OptObjectSize   = "-s"
+	OptQuiet        = "-q"
+)
+
+// Pathspec magic. A pathspec starting with ":(exclude)" removes the paths it
+// matches from those matched by the other pathspecs; one starting with
+// ":(literal)" matches the path as written, without glob characters.
+const (
+	PathspecMagicPrefix = ":("
+	PathspecExclude     = ":(exclude)"
+	PathspecLiteral     = ":(literal)"
 )
 
 // Git config specific options
@@ -49,13 +92,40 @@ const (
 	ConfigSafeDirectory = "safe.directory"
 	ConfigUserEmail     = "user.email"
 	ConfigUserName      = "user.name"
+	ConfigGPGFormat     = "gpg.format"
+	ConfigSigningKey    = "user.signingkey"
+	ConfigCommitGPGSign = "commit.gpgsign"
+)
+
+// GPG options
+const (
+	GPGOptBatch          = "--batch"
+	GPGOptImport         = "--import"
+	GPGOptWithColons     = "--with-colons"
+	GPGOptListSecretKeys = "--list-secret-keys"
+	GPGOptKill           = "--kill"
+	GPGAgent             = "gpg-agent"
 )
 
 // Git commit options
 const (
-	OptMessage  = "-m"
-	OptAnnotate = "-a"
-	OptDelete   = "-d"
+	OptMessage   = "-m"
+	OptAnnotate  = "-a"
+	OptDelete    = "-d"
+	OptGPGSign   = "-S" // git commit: sign the commit
+	OptNoGPGSign = "--no-gpg-sign"
+	OptSign      = "-s" // git tag: create a signed tag
+	OptSignoff   = "--signoff"
+	OptTrailer   = "--trailer"
+	OptNoVerify  = "--no-verify" // git commit: bypass the pre-commit and commit-msg hooks
+)
+
+// Git log format. Fields are separated by the ASCII unit separator and
+// records by the record separator so that multi-line bodies parse safely.
+const (
+	LogFieldSep  = "\x1f"
+	LogRecordSep = "\x1e"
+	LogFormat    = "--format=%H%x1f%an%x1f%s%x1f%b%x1e"
 )
 
 // Git stash options
@@ -74,6 +144,8 @@ const (
 const (
 	RefOrigin = "origin"
 	RefTags   = "refs/tags/"
+	RefHeads  = "refs/heads/"
+	RefLocks  = "refs/locks/"
 )
 
 // BuildArgs is a helper function to construct git command arguments.
@@ -123,6 +195,20 @@ func ConfigUserNameArgs(name string) []string {
 		Build()
 }
 
+// ConfigSetArgs builds arguments for setting a global configuration value.
+func ConfigSetArgs(key, value string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdConfig, OptGlobal, key, value).
+		Build()
+}
+
+// ConfigUnsetArgs builds arguments for removing a global configuration value.
+func ConfigUnsetArgs(key string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdConfig, OptGlobal, OptUnset, key).
+		Build()
+}
+
 // ConfigListArgs builds arguments for listing git configuration.
 func ConfigListArgs() []string {
 	return NewArgsBuilder().
@@ -137,17 +223,51 @@ func CommitArgs(message string) []string {
 		Build()
 }
 
-// PushArgs builds arguments for pushing to remote.
-func PushArgs(remote, branch string) []string {
+// CommitSignedArgs builds arguments for committing changes with a signature.
+func CommitSignedArgs(message string) []string {
 	return NewArgsBuilder().
-		Add(SubCmdPush, remote, branch).
+		Add(SubCmdCommit, OptGPGSign, OptMessage, message).
 		Build()
 }
 
-// PushUpstreamArgs builds arguments for pushing with upstream.
-func PushUpstreamArgs(remote, branch string) []string {
+// ShellCommandArgs builds the arguments running command with CmdShell.
+func ShellCommandArgs(command string) []string {
 	return NewArgsBuilder().
-		Add(SubCmdPush, OptUpstream, remote, branch).
+		Add("-c", command).
+		Build()
+}
+
+// CommitTrailerOpts builds the options that add trailers to a commit made with
+// CommitArgs or CommitSignedArgs: "--signoff" and one "--trailer" per
+// "Key: value" line.
+func CommitTrailerOpts(signoff bool, trailers []string) []string {
+	builder := NewArgsBuilder()
+	if signoff {
+		builder.Add(OptSignoff)
+	}
+	for _, trailer := range trailers {
+		builder.Add(OptTrailer, trailer)
+	}
+	return builder.Build()
+}
+
+// PushArgs builds arguments for pushing to remote. opts are placed before the
+// remote, typically ForceWithLeaseOpt or OptForce.
+func PushArgs(remote, branch string, opts ...string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdPush).
+		Add(opts...).
+		Add(remote, branch).
+		Build()
+}
+
+// PushUpstreamArgs builds arguments for pushing with upstream. opts are placed
+// before the remote, as in PushArgs.
+func PushUpstreamArgs(remote, branch string, opts ...string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdPush, OptUpstream).
+		Add(opts...).
+		Add(remote, branch).
 		Build()
 }
 
@@ -186,6 +306,15 @@ func AddArgs(pattern string) []string {
 		Build()
 }
 
+// AddPathspecsArgs builds arguments for adding the files matched by pathspecs
+// in one command. The pathspecs follow "--", so none is taken for an option.
+func AddPathspecsArgs(pathspecs []string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdAdd, OptEndOfOptions).
+		Add(pathspecs...).
+		Build()
+}
+
 // TagCreateArgs builds arguments for creating a tag.
 func TagCreateArgs(tagName string, force bool) []string {
 	builder := NewArgsBuilder().Add(SubCmdTag)
@@ -204,6 +333,15 @@ func TagCreateAnnotatedArgs(tagName, message string, force bool) []string {
 	return builder.Add(OptAnnotate, tagName, OptMessage, message).Build()
 }
 
+// TagCreateSignedArgs builds arguments for creating a signed annotated tag.
+func TagCreateSignedArgs(tagName, message string, force bool) []string {
+	builder := NewArgsBuilder().Add(SubCmdTag)
+	if force {
+		builder.Add(OptForce)
+	}
+	return builder.Add(OptSign, tagName, OptMessage, message).Build()
+}
+
 // TagDeleteArgs builds arguments for deleting a tag.
 func TagDeleteArgs(tagName string) []string {
 	return NewArgsBuilder().
@@ -220,6 +358,39 @@ func PushTagArgs(tagName string, force bool) []string {
 	return builder.Add(RefOrigin, tagName).Build()
 }
 
+// PushTagsAtomicArgs builds arguments for pushing several tags in a single
+// atomic push: either every ref is updated on the remote or none is.
+func PushTagsAtomicArgs(tagNames []string, force bool) []string {
+	builder := NewArgsBuilder().Add(SubCmdPush, OptAtomic)
+	if force {
+		builder.Add(OptForce)
+	}
+	builder.Add(RefOrigin)
+	for _, name := range tagNames {
+		builder.Add(RefTags + name)
+	}
+	return builder.Build()
+}
+
+// PushAtomicArgs builds arguments for pushing several refspecs, such as a
+// branch and a tag, in a single atomic push. opts are placed before the
+// remote, as in PushArgs.
+func PushAtomicArgs(remote string, refspecs []string, opts ...string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdPush, OptAtomic).
+		Add(opts...).
+		Add(remote).
+		Add(refspecs...).
+		Build()
+}
+
+// TagListArgs builds arguments for listing local tags, one per line.
+func TagListArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdTag, OptList).
+		Build()
+}
+
 // DeleteRemoteTagArgs builds arguments for deleting a remote tag.
 func DeleteRemoteTagArgs(tagName string) []string {
 	return NewArgsBuilder().
@@ -227,6 +398,17 @@ func DeleteRemoteTagArgs(tagName string) []string {
 		Build()
 }
 
+// PushRefToArgs builds arguments for pointing the remote ref (a full ref name
+// such as refs/heads/main) at sha, which must exist locally. An empty sha
+// deletes the remote ref. opts are placed before the remote, as in PushArgs.
+func PushRefToArgs(ref, sha string, opts ...string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdPush).
+		Add(opts...).
+		Add(RefOrigin, sha+":"+ref).
+		Build()
+}
+
 // FetchTagsArgs builds arguments for fetching tags.
 func FetchTagsArgs() []string {
 	return NewArgsBuilder().
@@ -248,6 +430,14 @@ func LsRemoteHeadsArgs(remote, branch string) []string {
 		Build()
 }
 
+// LsRemoteRefArgs builds arguments for listing a single remote ref by its full
+// name, such as refs/locks/main.
+func LsRemoteRefArgs(remote, ref string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdLsRemote, remote, ref).
+		Build()
+}
+
 // ResetHardArgs builds arguments for hard reset.
 func ResetHardArgs(ref string) []string {
 	return NewArgsBuilder().
@@ -255,6 +445,133 @@ func ResetHardArgs(ref string) []string {
 		Build()
 }
 
+// ResetSoftArgs builds arguments for moving HEAD to ref while keeping the
+// index and working tree.
+func ResetSoftArgs(ref string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdReset, OptSoft, ref).
+		Build()
+}
+
+// ResetIndexArgs builds arguments for unstaging every change, keeping the
+// working tree.
+func ResetIndexArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdReset, OptQuiet).
+		Build()
+}
+
+// DiffCachedRawArgs builds arguments for listing staged changes in raw,
+// NUL-terminated form with full object names and modes. Renames are reported
+// as a deletion plus an addition.
+func DiffCachedRawArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdDiff, OptCached, OptRaw, OptNullTerm, OptNoRenames, OptNoAbbrev).
+		Build()
+}
+
+// DiffCachedNumstatArgs builds arguments for counting the added and deleted
+// lines of each staged path, NUL-terminated. git reports "-" for both counts
+// of a binary file. Renames are reported as a deletion plus an addition.
+func DiffCachedNumstatArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdDiff, OptCached, OptNumstat, OptNullTerm, OptNoRenames).
+		Build()
+}
+
+// CatFileSizeArgs builds arguments for printing the size in bytes of an
+// object.
+func CatFileSizeArgs(sha string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdCatFile, OptObjectSize, sha).
+		Build()
+}
+
+// CatFileBlobArgs builds arguments for printing the raw content of a blob.
+func CatFileBlobArgs(sha string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdCatFile, ObjectBlob, sha).
+		Build()
+}
+
+// CatFileCommitArgs builds arguments for printing the raw commit object sha:
+// its headers, a blank line and the message.
+func CatFileCommitArgs(sha string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdCatFile, ObjectCommit, sha).
+		Build()
+}
+
+// CommitTreeArgs builds arguments for creating a parentless, unsigned commit
+// of tree with message, without touching any branch. git prints the new
+// commit SHA.
+func CommitTreeArgs(tree, message string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdCommitTree, OptNoGPGSign, tree, OptMessage, message).
+		Build()
+}
+
+// ForceWithLeaseOpt builds the push option that overwrites branch only while
+// the remote still points at expectedSHA. An empty expectedSHA expects the
+// branch not to exist on the remote.
+func ForceWithLeaseOpt(branch, expectedSHA string) string {
+	return OptForceLease + "=" + branch + ":" + expectedSHA
+}
+
+// RebaseArgs builds arguments for rebasing the current branch onto upstream,
+// stashing uncommitted changes around the rebase.
+func RebaseArgs(upstream string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdRebase, OptAutostash, upstream).
+		Build()
+}
+
+// RebaseAbortArgs builds arguments for aborting a rebase in progress.
+func RebaseAbortArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdRebase, OptAbort).
+		Build()
+}
+
+// MergeArgs builds arguments for merging ref into the current branch with
+// the default message, stashing uncommitted changes around the merge.
+func MergeArgs(ref string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdMerge, OptAutostash, OptNoEdit, ref).
+		Build()
+}
+
+// MergeAbortArgs builds arguments for aborting a merge in progress.
+func MergeAbortArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdMerge, OptAbort).
+		Build()
+}
+
+// MergeBaseIsAncestorArgs builds arguments for checking whether ancestor is
+// an ancestor of rev; git exits 1 when it is not.
+func MergeBaseIsAncestorArgs(ancestor, rev string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdMergeBase, OptIsAncestor, ancestor, rev).
+		Build()
+}
+
+// DiffCachedNamesArgs builds arguments for listing the staged paths,
+// NUL-terminated so that unusual names are not quoted.
+func DiffCachedNamesArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdDiff, OptCached, OptNameOnly, OptNullTerm).
+		Build()
+}
+
+// DiffConflictedArgs builds arguments for listing the paths with unresolved
+// conflicts.
+func DiffConflictedArgs() []string {
+	return NewArgsBuilder().
+		Add(SubCmdDiff, OptNameOnly, OptUnmerged).
+		Build()
+}
+
 // StashPushArgs builds arguments for stash push.
 func StashPushArgs() []string {
 	return NewArgsBuilder().
@@ -276,6 +593,28 @@ func DiffNameStatusArgs(base, head string) []string {
 		Build()
 }
 
+// DiffNumstatArgs builds arguments for counting the added and deleted lines
+// of each path changed between base and head, NUL-terminated. git reports "-"
+// for both counts of a binary file. Renames are reported as a deletion plus
+// an addition.
+func DiffNumstatArgs(base, head string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdDiff, base+".."+head, OptNumstat, OptNullTerm, OptNoRenames).
+		Build()
+}
+
+// DiffCachedNameStatusArgs builds arguments for listing the status and path
+// of each staged change, NUL-terminated, limited to pathspecs when given.
+// Renames are reported as a deletion plus an addition.
+func DiffCachedNameStatusArgs(pathspecs ...string) []string {
+	builder := NewArgsBuilder().
+		Add(SubCmdDiff, OptCached, OptNameStatus, OptNullTerm, OptNoRenames)
+	if len(pathspecs) > 0 {
+		builder.Add(OptEndOfOptions).Add(pathspecs...)
+	}
+	return builder.Build()
+}
+
 // RevListArgs builds arguments for rev-list command.
 func RevListArgs(ref string) []string {
 	return NewArgsBuilder().
@@ -283,6 +622,23 @@ func RevListArgs(ref string) []string {
 		Build()
 }
 
+// LogArgs builds arguments for listing the commits in revRange using LogFormat.
+func LogArgs(revRange string) []string {
+	return NewArgsBuilder().
+		Add(SubCmdLog, LogFormat, revRange).
+		Build()
+}
+
+// DescribeLatestTagArgs builds arguments for finding the most recent tag
+// reachable from ref. A non-empty exclude skips that tag name.
+func DescribeLatestTagArgs(ref, exclude string) []string {
+	builder := NewArgsBuilder().Add(SubCmdDescribe, OptTags, "--abbrev=0")
+	if exclude != "" {
+		builder.Add("--exclude", exclude)
+	}
+	return builder.Add(ref).Build()
+}
+
 // ConfigGetArgs builds arguments for getting a config value.
 func ConfigGetArgs(key string) []string {
 	return NewArgsBuilder().
@@ -303,3 +659,27 @@ func PushDeleteBranchArgs(remote, branch string) []string {
 		Add(SubCmdPush, remote, OptDeleteRemote, branch).
 		Build()
 }
+
+// GPGImportArgs builds arguments for importing a key file into the keyring
+// selected by GNUPGHOME.
+func GPGImportArgs(path string) []string {
+	return NewArgsBuilder().
+		Add(GPGOptBatch, GPGOptImport, path).
+		Build()
+}
+
+// GPGListSecretKeysArgs builds arguments for listing secret keys in the
+// machine-readable colon format.
+func GPGListSecretKeysArgs() []string {
+	return NewArgsBuilder().
+		Add(GPGOptBatch, GPGOptWithColons, GPGOptListSecretKeys).
+		Build()
+}
+
+// GPGKillAgentArgs builds arguments for stopping the gpg-agent of the keyring
+// selected by GNUPGHOME.
+func GPGKillAgentArgs() []string {
+	return NewArgsBuilder().
+		Add(GPGOptKill, GPGAgent).
+		Build()
+}
@@ -68,6 +68,25 @@ func TestPushUpstreamArgs(t *testing.T) {
 	}
 }
 
+func TestPushArgs_WithOptions(t *testing.T) {
+	lease := ForceWithLeaseOpt("feature", "abc123")
+	if lease != "--force-with-lease=feature:abc123" {
+		t.Errorf("ForceWithLeaseOpt() = %q", lease)
+	}
+
+	args := PushArgs("origin", "feature", lease)
+	expected := []string{SubCmdPush, lease, "origin", "feature"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("PushArgs() = %v, want %v", args, expected)
+	}
+
+	args = PushUpstreamArgs("origin", "feature", OptForce)
+	expected = []string{SubCmdPush, OptUpstream, OptForce, "origin", "feature"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("PushUpstreamArgs() = %v, want %v", args, expected)
+	}
+}
+
 func TestFetchArgs(t *testing.T) {
 	args := FetchArgs("origin", "main")
 	expected := []string{SubCmdFetch, "origin", "main"}
@@ -113,6 +132,24 @@ func TestAddArgs(t *testing.T) {
 	}
 }
 
+func TestAddPathspecsArgs(t *testing.T) {
+	args := AddPathspecsArgs([]string{"docs/my notes.md", ":(glob)**/*.go", PathspecExclude + "vendor"})
+	expected := []string{SubCmdAdd, OptEndOfOptions, "docs/my notes.md", ":(glob)**/*.go", ":(exclude)vendor"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("AddPathspecsArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestDiffCachedNamesArgs(t *testing.T) {
+	args := DiffCachedNamesArgs()
+	expected := []string{SubCmdDiff, OptCached, OptNameOnly, OptNullTerm}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("DiffCachedNamesArgs() = %v, want %v", args, expected)
+	}
+}
+
 func TestTagCreateArgs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -218,6 +255,95 @@ func TestPushTagArgs(t *testing.T) {
 	}
 }
 
+func TestPushTagsAtomicArgs(t *testing.T) {
+	args := PushTagsAtomicArgs([]string{"v1.2.3", "v1", "v1.2"}, true)
+	expected := []string{SubCmdPush, OptAtomic, OptForce, RefOrigin, "refs/tags/v1.2.3", "refs/tags/v1", "refs/tags/v1.2"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("PushTagsAtomicArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestPushAtomicArgs(t *testing.T) {
+	lease := ForceWithLeaseOpt("main", "abc123")
+	args := PushAtomicArgs(RefOrigin, []string{"main", "+refs/tags/v1.0.0"}, lease)
+	expected := []string{SubCmdPush, OptAtomic, lease, RefOrigin, "main", "+refs/tags/v1.0.0"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("PushAtomicArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestPushRefToArgs(t *testing.T) {
+	args := PushRefToArgs(RefHeads+"main", "abc123", ForceWithLeaseOpt("main", "def456"))
+	expected := []string{SubCmdPush, "--force-with-lease=main:def456", RefOrigin, "abc123:refs/heads/main"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("PushRefToArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestGuardArgs(t *testing.T) {
+	if got, want := DiffCachedNumstatArgs(), []string{SubCmdDiff, OptCached, OptNumstat, OptNullTerm, OptNoRenames}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffCachedNumstatArgs() = %v, want %v", got, want)
+	}
+	if got, want := CatFileSizeArgs("abc123"), []string{SubCmdCatFile, OptObjectSize, "abc123"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CatFileSizeArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestShellCommandArgs(t *testing.T) {
+	if got, want := ShellCommandArgs("make fmt && go mod tidy"), []string{"-c", "make fmt && go mod tidy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ShellCommandArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestLockArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{
+			name: "ls-remote ref",
+			got:  LsRemoteRefArgs(RefOrigin, "refs/locks/main"),
+			want: []string{SubCmdLsRemote, RefOrigin, "refs/locks/main"},
+		},
+		{
+			name: "cat-file commit",
+			got:  CatFileCommitArgs("abc123"),
+			want: []string{SubCmdCatFile, ObjectCommit, "abc123"},
+		},
+		{
+			name: "commit-tree",
+			got:  CommitTreeArgs("def456", "lock"),
+			want: []string{SubCmdCommitTree, OptNoGPGSign, "def456", OptMessage, "lock"},
+		},
+		{
+			name: "delete ref",
+			got:  PushRefToArgs("refs/locks/main", "", ForceWithLeaseOpt("refs/locks/main", "abc123")),
+			want: []string{SubCmdPush, "--force-with-lease=refs/locks/main:abc123", RefOrigin, ":refs/locks/main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("args = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagListArgs(t *testing.T) {
+	args := TagListArgs()
+	expected := []string{SubCmdTag, OptList}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("TagListArgs() = %v, want %v", args, expected)
+	}
+}
+
 func TestDeleteRemoteTagArgs(t *testing.T) {
 	args := DeleteRemoteTagArgs("v1.0.0")
 	expected := []string{SubCmdPush, RefOrigin, ":refs/tags/v1.0.0"}
@@ -290,6 +416,24 @@ func TestDiffNameStatusArgs(t *testing.T) {
 	}
 }
 
+func TestDiffNumstatArgs(t *testing.T) {
+	args := DiffNumstatArgs("main", "develop")
+	expected := []string{SubCmdDiff, "main..develop", OptNumstat, "-z", "--no-renames"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("DiffNumstatArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestDiffCachedNameStatusArgs(t *testing.T) {
+	args := DiffCachedNameStatusArgs()
+	expected := []string{SubCmdDiff, "--cached", OptNameStatus, "-z", "--no-renames"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("DiffCachedNameStatusArgs() = %v, want %v", args, expected)
+	}
+}
+
 func TestRevListArgs(t *testing.T) {
 	args := RevListArgs("v1.0.0")
 	expected := []string{SubCmdRevList, "-n1", "v1.0.0"}
@@ -299,6 +443,42 @@ func TestRevListArgs(t *testing.T) {
 	}
 }
 
+func TestLogArgs(t *testing.T) {
+	args := LogArgs("v1.0.0..HEAD")
+	expected := []string{SubCmdLog, LogFormat, "v1.0.0..HEAD"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("LogArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestDescribeLatestTagArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude string
+		want    []string
+	}{
+		{
+			name: "without exclude",
+			want: []string{SubCmdDescribe, OptTags, "--abbrev=0", "HEAD"},
+		},
+		{
+			name:    "with exclude",
+			exclude: "v2.0.0",
+			want:    []string{SubCmdDescribe, OptTags, "--abbrev=0", "--exclude", "v2.0.0", "HEAD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := DescribeLatestTagArgs("HEAD", tt.exclude)
+			if !reflect.DeepEqual(args, tt.want) {
+				t.Errorf("DescribeLatestTagArgs() = %v, want %v", args, tt.want)
+			}
+		})
+	}
+}
+
 func TestArgsBuilder(t *testing.T) {
 	// Test the builder pattern
 	builder := NewArgsBuilder()
@@ -356,3 +536,162 @@ func TestPushDeleteBranchArgs(t *testing.T) {
 		t.Errorf("PushDeleteBranchArgs() = %v, want %v", args, expected)
 	}
 }
+
+func TestConfigSetArgs(t *testing.T) {
+	args := ConfigSetArgs(ConfigGPGFormat, "ssh")
+	expected := []string{SubCmdConfig, OptGlobal, "gpg.format", "ssh"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("ConfigSetArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestConfigUnsetArgs(t *testing.T) {
+	args := ConfigUnsetArgs(ConfigSigningKey)
+	expected := []string{SubCmdConfig, OptGlobal, OptUnset, "user.signingkey"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("ConfigUnsetArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestCommitSignedArgs(t *testing.T) {
+	args := CommitSignedArgs("signed")
+	expected := []string{SubCmdCommit, "-S", OptMessage, "signed"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("CommitSignedArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestCommitTrailerOpts(t *testing.T) {
+	args := CommitTrailerOpts(true, []string{"Co-authored-by: A <a@example.com>", "Refs: #12"})
+	expected := []string{OptSignoff, OptTrailer, "Co-authored-by: A <a@example.com>", OptTrailer, "Refs: #12"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("CommitTrailerOpts() = %v, want %v", args, expected)
+	}
+
+	if args := CommitTrailerOpts(false, nil); len(args) != 0 {
+		t.Errorf("CommitTrailerOpts(false, nil) = %v, want no options", args)
+	}
+}
+
+func TestTagCreateSignedArgs(t *testing.T) {
+	args := TagCreateSignedArgs("v1.0.0", "Release v1.0.0", true)
+	expected := []string{SubCmdTag, OptForce, "-s", "v1.0.0", OptMessage, "Release v1.0.0"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("TagCreateSignedArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestGPGArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"import", GPGImportArgs("/tmp/key.asc"), []string{"--batch", "--import", "/tmp/key.asc"}},
+		{"list secret keys", GPGListSecretKeysArgs(), []string{"--batch", "--with-colons", "--list-secret-keys"}},
+		{"kill agent", GPGKillAgentArgs(), []string{"--kill", "gpg-agent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("args = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetSoftArgs(t *testing.T) {
+	args := ResetSoftArgs("origin/main")
+	expected := []string{SubCmdReset, "--soft", "origin/main"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("ResetSoftArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestResetIndexArgs(t *testing.T) {
+	args := ResetIndexArgs()
+	expected := []string{SubCmdReset, "-q"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("ResetIndexArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestDiffCachedRawArgs(t *testing.T) {
+	args := DiffCachedRawArgs()
+	expected := []string{SubCmdDiff, "--cached", "--raw", "-z", "--no-renames", "--no-abbrev"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("DiffCachedRawArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestCatFileBlobArgs(t *testing.T) {
+	args := CatFileBlobArgs("abc123")
+	expected := []string{"cat-file", "blob", "abc123"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("CatFileBlobArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestRebaseArgs(t *testing.T) {
+	args := RebaseArgs("origin/main")
+	expected := []string{"rebase", "--autostash", "origin/main"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("RebaseArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestRebaseAbortArgs(t *testing.T) {
+	args := RebaseAbortArgs()
+	expected := []string{"rebase", "--abort"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("RebaseAbortArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestMergeArgs(t *testing.T) {
+	args := MergeArgs("origin/main")
+	expected := []string{"merge", "--autostash", "--no-edit", "origin/main"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("MergeArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestMergeAbortArgs(t *testing.T) {
+	args := MergeAbortArgs()
+	expected := []string{"merge", "--abort"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("MergeAbortArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestMergeBaseIsAncestorArgs(t *testing.T) {
+	args := MergeBaseIsAncestorArgs("origin/main", "HEAD")
+	expected := []string{"merge-base", "--is-ancestor", "origin/main", "HEAD"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("MergeBaseIsAncestorArgs() = %v, want %v", args, expected)
+	}
+}
+
+func TestDiffConflictedArgs(t *testing.T) {
+	args := DiffConflictedArgs()
+	expected := []string{"diff", "--name-only", "--diff-filter=U"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("DiffConflictedArgs() = %v, want %v", args, expected)
+	}
+}
@@ -22,7 +22,8 @@ func (c Call) Key() string {
 
 // FakeResult is the canned outcome of one command.
 type FakeResult struct {
-	// Stdout is returned by Output. Run ignores it.
+	// Stdout is returned by Output, and by CombinedOutput also when Err is
+	// set. Run ignores it.
 	Stdout string
 	// Err is returned by both Run and Output. Use Fail to build an error that
 	// carries a specific exit code.
@@ -88,6 +89,13 @@ func (f *FakeRunner) Output(name string, args ...string) ([]byte, error) {
 	return []byte(out), nil
 }
 
+// CombinedOutput records the call and returns the canned stdout together with
+// the canned error, as a failing command still produces output.
+func (f *FakeRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	out, err := f.resolve(name, args)
+	return []byte(out), err
+}
+
 // resolve records the call and computes its outcome.
 func (f *FakeRunner) resolve(name string, args []string) (string, error) {
 	f.mu.Lock()
@@ -21,6 +21,11 @@ type Runner interface {
 	// Output executes the command and returns its stdout. Stderr is not
 	// captured, matching the semantics of exec.Cmd.Output.
 	Output(name string, args ...string) ([]byte, error)
+
+	// CombinedOutput executes the command and returns its stdout and stderr
+	// interleaved, also when it fails, so that the output of a failing hook
+	// can be reported with the error.
+	CombinedOutput(name string, args ...string) ([]byte, error)
 }
 
 // ExecRunner is the production Runner, backed by os/exec.
@@ -53,6 +58,11 @@ func (r *ExecRunner) Output(name string, args ...string) ([]byte, error) {
 	return exec.Command(name, args...).Output()
 }
 
+// CombinedOutput executes the command and returns its stdout and stderr.
+func (r *ExecRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
 // ExitError reports that a command ran to completion but exited non-zero.
 // A fake Runner returns this so that exit-code-sensitive logic (such as the
 // "nothing to commit" check) is reachable in tests without spawning a process.
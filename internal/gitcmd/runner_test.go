@@ -79,6 +79,19 @@ func TestExecRunner_Output_ExitError(t *testing.T) {
 	}
 }
 
+// CombinedOutput captures stderr with stdout, including from a failing command.
+func TestExecRunner_CombinedOutput(t *testing.T) {
+	r := NewExecRunner()
+
+	out, err := r.CombinedOutput("sh", "-c", "echo out; echo err >&2; exit 5")
+	if code, ok := ExitCodeOf(err); !ok || code != 5 {
+		t.Errorf("ExitCodeOf() = (%d, %v), want (5, true)", code, ok)
+	}
+	if got := string(out); got != "out\nerr\n" {
+		t.Errorf("CombinedOutput() = %q, want %q", got, "out\nerr\n")
+	}
+}
+
 func TestExitError(t *testing.T) {
 	cause := errors.New("underlying")
 	e := &ExitError{Code: 1, Err: cause}
@@ -189,6 +202,19 @@ func TestFakeRunner_Default(t *testing.T) {
 	}
 }
 
+func TestFakeRunner_CombinedOutput(t *testing.T) {
+	f := NewFakeRunner().Stub("git commit -m msg", FakeResult{Stdout: "hook failed\n", Err: Fail(1)})
+
+	// Unlike Output, the canned output is returned with the error.
+	out, err := f.CombinedOutput("git", "commit", "-m", "msg")
+	if err == nil {
+		t.Error("CombinedOutput() error = nil, want the canned failure")
+	}
+	if got := string(out); got != "hook failed\n" {
+		t.Errorf("CombinedOutput() = %q, want %q", got, "hook failed\n")
+	}
+}
+
 func TestFakeRunner_Handler(t *testing.T) {
 	// A stateful fake: status reports a change only after an add has run.
 	f := NewFakeRunner()
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/somaz94/go-git-commit-action/internal/errors"
@@ -18,6 +20,10 @@ const (
 	apiVersion     = "2022-11-28"
 	acceptHeader   = "application/vnd.github+json"
 	requestTimeout = 30 * time.Second
+
+	// uploadTimeout bounds a single release asset upload. Artifacts can be far
+	// larger than any JSON payload, so requestTimeout would be too tight.
+	uploadTimeout = 10 * time.Minute
 )
 
 // Client handles GitHub API interactions.
@@ -55,6 +61,75 @@ func (c *Client) Patch(ctx context.Context, endpoint string, data interface{}) (
 	return c.request(ctx, http.MethodPatch, endpoint, data)
 }
 
+// Put sends a PUT request to the GitHub API.
+func (c *Client) Put(ctx context.Context, endpoint string, data interface{}) (map[string]interface{}, error) {
+	return c.request(ctx, http.MethodPut, endpoint, data)
+}
+
+// Get sends a GET request to the GitHub API and returns an object response.
+// Like Post and Patch, a non-2xx response with a JSON body is returned without
+// an error so the caller can inspect its "message" (e.g. "Not Found").
+func (c *Client) Get(ctx context.Context, endpoint string) (map[string]interface{}, error) {
+	body, statusCode, err := c.do(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, errors.New("GitHub API GET", err)
+	}
+	return decodeObject(http.MethodGet, body, statusCode)
+}
+
+// Delete sends a DELETE request to the GitHub API. Any non-2xx response is
+// returned as an APIError carrying the API message when the body has one.
+func (c *Client) Delete(ctx context.Context, endpoint string) error {
+	body, statusCode, err := c.do(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return errors.New("GitHub API DELETE", err)
+	}
+	if statusCode >= 200 && statusCode < 300 {
+		return nil
+	}
+
+	var errResult map[string]interface{}
+	if json.Unmarshal(body, &errResult) == nil {
+		if msg, ok := errResult["message"].(string); ok && msg != "" {
+			return errors.NewAPIErrorWithDetails("GitHub API DELETE", msg, statusCode, errResult)
+		}
+	}
+	return errors.NewAPIError("GitHub API DELETE", fmt.Sprintf("HTTP %d", statusCode))
+}
+
+// Upload streams size bytes from body to a release's upload URL as an asset
+// called name. uploadURL is the release's "upload_url", an RFC 6570 template
+// such as ".../assets{?name,label}"; the template part is replaced by the name
+// query. The body is never buffered in memory. Like Post, a non-2xx response
+// with a JSON body is returned without an error.
+func (c *Client) Upload(ctx context.Context, uploadURL, name string, body io.Reader, size int64, contentType string) (map[string]interface{}, error) {
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	target := uploadURL + "?name=" + url.QueryEscape(name)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, body)
+	if err != nil {
+		return nil, errors.New("GitHub API upload", err)
+	}
+	// A file reader does not let net/http infer the length, and the uploads
+	// endpoint rejects chunked requests. net/http takes a zero length with a
+	// body for an unknown one, so an empty file is sent without a body.
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	uploadClient := &http.Client{Transport: c.httpClient.Transport, Timeout: uploadTimeout}
+	respBody, statusCode, err := c.send(uploadClient, req)
+	if err != nil {
+		return nil, errors.New("GitHub API upload", err)
+	}
+
+	return decodeObject(http.MethodPost, respBody, statusCode)
+}
+
 // GetArray sends a GET request to the GitHub API and returns an array response.
 func (c *Client) GetArray(ctx context.Context, endpoint string) ([]map[string]interface{}, error) {
 	body, statusCode, err := c.do(ctx, http.MethodGet, endpoint, nil)
@@ -74,6 +149,50 @@ func (c *Client) GetArray(ctx context.Context, endpoint string) ([]map[string]in
 	return result, nil
 }
 
+// GraphQL runs a query or mutation against the GitHub GraphQL API and
+// returns its "data". Unlike the REST methods, a response carrying "errors"
+// is returned as an APIError with the first error's message.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]interface{}) (map[string]interface{}, error) {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, errors.New("marshal GraphQL request", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/graphql", bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.New("GitHub GraphQL API", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	body, statusCode, err := c.send(c.httpClient, req)
+	if err != nil {
+		return nil, errors.New("GitHub GraphQL API", err)
+	}
+
+	var result struct {
+		Data    map[string]interface{}     `json:"data"`
+		Errors  []struct{ Message string } `json:"errors"`
+		Message string                     `json:"message"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		if statusCode < 200 || statusCode >= 300 {
+			return nil, errors.NewAPIError("GitHub GraphQL API", fmt.Sprintf("HTTP %d", statusCode))
+		}
+		return nil, errors.New("parse GitHub GraphQL response", err)
+	}
+	switch {
+	case len(result.Errors) > 0:
+		return nil, errors.NewAPIError("GitHub GraphQL API", result.Errors[0].Message)
+	case statusCode < 200 || statusCode >= 300:
+		msg := result.Message
+		if msg == "" {
+			msg = fmt.Sprintf("HTTP %d", statusCode)
+		}
+		return nil, errors.NewAPIError("GitHub GraphQL API", msg)
+	}
+	return result.Data, nil
+}
+
 // Repo returns the GitHub repository name.
 func (c *Client) Repo() string {
 	return c.repo
@@ -94,14 +213,21 @@ func (c *Client) do(ctx context.Context, method, endpoint string, payload []byte
 		return nil, 0, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.token)
-	req.Header.Set("Accept", acceptHeader)
-	req.Header.Set("X-GitHub-Api-Version", apiVersion)
 	if payload != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	return c.send(c.httpClient, req)
+}
+
+// send adds the authentication and API version headers to req, executes it
+// with httpClient and returns the response body and status code.
+func (c *Client) send(httpClient *http.Client, req *http.Request) ([]byte, int, error) {
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", acceptHeader)
+	req.Header.Set("X-GitHub-Api-Version", apiVersion)
+
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -115,7 +241,7 @@ func (c *Client) do(ctx context.Context, method, endpoint string, payload []byte
 	return respBody, resp.StatusCode, nil
 }
 
-// request sends a POST/PATCH request with a JSON body to the GitHub API.
+// request sends a POST/PATCH/PUT request with a JSON body to the GitHub API.
 func (c *Client) request(ctx context.Context, method, endpoint string, data interface{}) (map[string]interface{}, error) {
 	jsonData, err := json.Marshal(data)
 	if err != nil {
@@ -127,6 +253,12 @@ func (c *Client) request(ctx context.Context, method, endpoint string, data inte
 		return nil, errors.New("GitHub API "+method, err)
 	}
 
+	return decodeObject(method, body, statusCode)
+}
+
+// decodeObject turns a GitHub API response into the map form returned by
+// Get, Post and Patch.
+func decodeObject(method string, body []byte, statusCode int) (map[string]interface{}, error) {
 	// For client/server errors, try to parse the JSON body so the caller
 	// can inspect API error details (e.g., "A pull request already exists").
 	if statusCode < 200 || statusCode >= 300 {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -214,6 +215,74 @@ func TestPatch_Success(t *testing.T) {
 	}
 }
 
+func TestPut_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/repos/owner/repo/pulls/1/merge" {
+			t.Errorf("request = %s %s, want PUT /repos/owner/repo/pulls/1/merge", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"merged":true}`))
+	}))
+	defer srv.Close()
+
+	resp, err := testClient(srv.URL).Put(context.Background(), "/pulls/1/merge", map[string]string{"merge_method": "squash"})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if resp["merged"] != true {
+		t.Errorf("merged = %v, want true", resp["merged"])
+	}
+}
+
+func TestGraphQL_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			t.Errorf("request = %s %s, want POST /graphql", r.Method, r.URL.Path)
+		}
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if vars, _ := body["variables"].(map[string]interface{}); vars["id"] != "PR_1" {
+			t.Errorf("variables = %v, want id PR_1", body["variables"])
+		}
+		_, _ = w.Write([]byte(`{"data":{"node":{"isDraft":true}}}`))
+	}))
+	defer srv.Close()
+
+	data, err := testClient(srv.URL).GraphQL(context.Background(), "query($id: ID!) { node(id: $id) { id } }", map[string]interface{}{"id": "PR_1"})
+	if err != nil {
+		t.Fatalf("GraphQL() error = %v", err)
+	}
+	if node, _ := data["node"].(map[string]interface{}); node["isDraft"] != true {
+		t.Errorf("data = %v, want the node", data)
+	}
+}
+
+func TestGraphQL_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"errors", http.StatusOK, `{"data":null,"errors":[{"message":"Pull request is in clean status"}]}`, "Pull request is in clean status"},
+		{"message", http.StatusUnauthorized, `{"message":"Bad credentials"}`, "Bad credentials"},
+		{"unparseable", http.StatusBadGateway, `<html>`, "HTTP 502"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := testClient(srv.URL).GraphQL(context.Background(), "mutation { x }", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GraphQL() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetArray_Success(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
@@ -251,3 +320,127 @@ func TestGetArray_ErrorStatus(t *testing.T) {
 		t.Fatal("GetArray() with 404 should return an error")
 	}
 }
+
+func TestGet_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("method = %q, want GET", r.Method)
+		}
+		if r.URL.Path != "/repos/owner/repo/releases/tags/v1.0.0" {
+			t.Errorf("path = %q, want /repos/owner/repo/releases/tags/v1.0.0", r.URL.Path)
+		}
+		if got := r.Header.Get("Content-Type"); got != "" {
+			t.Errorf("GET should not set Content-Type, got %q", got)
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer srv.Close()
+
+	resp, err := testClient(srv.URL).Get(context.Background(), "/releases/tags/v1.0.0")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if resp["id"].(float64) != 42 {
+		t.Errorf("id = %v, want 42", resp["id"])
+	}
+}
+
+func TestGet_NotFoundBodyReturned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer srv.Close()
+
+	resp, err := testClient(srv.URL).Get(context.Background(), "/releases/tags/missing")
+	if err != nil {
+		t.Fatalf("Get() error = %v, want nil (error body returned to caller)", err)
+	}
+	if resp["message"] != "Not Found" {
+		t.Errorf("message = %v, want Not Found", resp["message"])
+	}
+}
+
+func TestDelete_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("method = %q, want DELETE", r.Method)
+		}
+		if r.URL.Path != "/repos/owner/repo/releases/assets/7" {
+			t.Errorf("path = %q, want /repos/owner/repo/releases/assets/7", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if err := testClient(srv.URL).Delete(context.Background(), "/releases/assets/7"); err != nil {
+		t.Errorf("Delete() error = %v, want nil", err)
+	}
+}
+
+func TestDelete_ErrorMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Must have admin rights"}`))
+	}))
+	defer srv.Close()
+
+	err := testClient(srv.URL).Delete(context.Background(), "/releases/assets/7")
+	if err == nil || !strings.Contains(err.Error(), "Must have admin rights") {
+		t.Errorf("Delete() error = %v, want the API message", err)
+	}
+}
+
+func TestUpload_StreamsBody(t *testing.T) {
+	const content = "artifact bytes"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/assets" || r.URL.Query().Get("name") != "app v1.tar.gz" {
+			t.Errorf("URL = %q, want the template expanded with the name", r.URL.String())
+		}
+		if r.ContentLength != int64(len(content)) {
+			t.Errorf("ContentLength = %d, want %d", r.ContentLength, len(content))
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/gzip" {
+			t.Errorf("Content-Type = %q, want application/gzip", got)
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Error("Authorization header missing")
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != content {
+			t.Errorf("body = %q, want %q", body, content)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":3,"name":"app v1.tar.gz"}`))
+	}))
+	defer srv.Close()
+
+	resp, err := testClient(srv.URL).Upload(context.Background(), srv.URL+"/assets{?name,label}",
+		"app v1.tar.gz", strings.NewReader(content), int64(len(content)), "application/gzip")
+	if err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if resp["id"].(float64) != 3 {
+		t.Errorf("id = %v, want 3", resp["id"])
+	}
+}
+
+// An empty file is sent with a zero Content-Length, not chunked.
+func TestUpload_EmptyFile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength != 0 || len(r.TransferEncoding) != 0 {
+			t.Errorf("ContentLength = %d, TransferEncoding = %v, want an empty body of known length", r.ContentLength, r.TransferEncoding)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":4,"name":"empty.txt"}`))
+	}))
+	defer srv.Close()
+
+	// A reader net/http cannot size, like the file of a real upload.
+	body := io.MultiReader(strings.NewReader(""))
+	if _, err := testClient(srv.URL).Upload(context.Background(), srv.URL+"/assets{?name,label}",
+		"empty.txt", body, 0, "text/plain"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+}
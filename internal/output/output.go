@@ -1,6 +1,8 @@
 package output
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
@@ -9,12 +11,26 @@ import (
 
 // Key constants for action outputs.
 const (
-	KeyCommitSHA    = "commit_sha"
-	KeyPRNumber     = "pr_number"
-	KeyPRURL        = "pr_url"
-	KeyTagName      = "tag_name"
-	KeySkipped      = "skipped"
-	KeyChangedFiles = "changed_files"
+	KeyCommitSHA     = "commit_sha"
+	KeyPRNumber      = "pr_number"
+	KeyPRURL         = "pr_url"
+	KeyPRAction      = "pr_action"
+	KeyPRChecks      = "pr_checks"
+	KeyTagName       = "tag_name"
+	KeyPreviousTag   = "previous_tag"
+	KeyAliasTags     = "alias_tags"
+	KeyVersion       = "version"
+	KeyBumpType      = "bump_type"
+	KeyCommits       = "commits"
+	KeyChangelog     = "changelog"
+	KeyReleaseID     = "release_id"
+	KeyReleaseURL    = "release_url"
+	KeyReleaseAssets = "release_assets"
+	KeySkipped       = "skipped"
+	KeyChangedFiles  = "changed_files"
+	KeyStagedFiles   = "staged_files"
+	KeyCommitSHAs    = "commit_shas"
+	KeyPullRequests  = "pull_requests"
 )
 
 // Result holds all output values to be written to GITHUB_OUTPUT.
@@ -78,7 +94,11 @@ func (r *Result) WriteToGitHubOutput() (err error) {
 
 	var lines []string
 	for k, v := range r.values {
-		lines = append(lines, fmt.Sprintf("%s=%s", k, v))
+		line, err := formatOutputLine(k, v)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
 	}
 
 	if len(lines) > 0 {
@@ -95,3 +115,20 @@ func (r *Result) WriteToGitHubOutput() (err error) {
 
 	return nil
 }
+
+// formatOutputLine renders one GITHUB_OUTPUT entry. Single-line values use the
+// "key=value" form; multi-line values (such as a rendered changelog) use the
+// heredoc form with a random delimiter that cannot collide with the content.
+func formatOutputLine(key, value string) (string, error) {
+	if !strings.Contains(value, "\n") {
+		return fmt.Sprintf("%s=%s", key, value), nil
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate output delimiter: %w", err)
+	}
+	delimiter := "ghadelimiter_" + hex.EncodeToString(buf)
+
+	return fmt.Sprintf("%s<<%s\n%s\n%s", key, delimiter, strings.TrimSuffix(value, "\n"), delimiter), nil
+}
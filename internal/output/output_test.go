@@ -102,6 +102,41 @@ func TestResult_WriteToGitHubOutput_WithFile(t *testing.T) {
 	}
 }
 
+func TestResult_WriteToGitHubOutput_MultilineValue(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "github-output-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	tmpFile.Close()
+
+	t.Setenv("GITHUB_OUTPUT", tmpFile.Name())
+
+	r := NewResult()
+	r.Set(KeyChangelog, "## v1.0.0\n\n- first\n")
+
+	if err := r.WriteToGitHubOutput(); err != nil {
+		t.Fatalf("WriteToGitHubOutput() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "changelog<<ghadelimiter_") {
+		t.Fatalf("Output file = %q, want a heredoc entry", string(content))
+	}
+	delimiter := strings.TrimPrefix(lines[0], "changelog<<")
+	if lines[4] != delimiter {
+		t.Errorf("closing delimiter = %q, want %q", lines[4], delimiter)
+	}
+	if got := strings.Join(lines[1:4], "\n"); got != "## v1.0.0\n\n- first" {
+		t.Errorf("heredoc body = %q, want the changelog", got)
+	}
+}
+
 func TestResult_WriteToGitHubOutput_EmptyResult(t *testing.T) {
 	tmpFile, err := os.CreateTemp("", "github-output-*")
 	if err != nil {
@@ -142,12 +177,21 @@ func TestResult_WriteToGitHubOutput_InvalidPath(t *testing.T) {
 func TestKeyConstants(t *testing.T) {
 	// Verify key constants are defined correctly
 	keys := map[string]string{
-		"commit_sha":    KeyCommitSHA,
-		"pr_number":     KeyPRNumber,
-		"pr_url":        KeyPRURL,
-		"tag_name":      KeyTagName,
-		"skipped":       KeySkipped,
-		"changed_files": KeyChangedFiles,
+		"commit_sha":     KeyCommitSHA,
+		"pr_number":      KeyPRNumber,
+		"pr_url":         KeyPRURL,
+		"tag_name":       KeyTagName,
+		"previous_tag":   KeyPreviousTag,
+		"alias_tags":     KeyAliasTags,
+		"version":        KeyVersion,
+		"bump_type":      KeyBumpType,
+		"commits":        KeyCommits,
+		"changelog":      KeyChangelog,
+		"release_id":     KeyReleaseID,
+		"release_url":    KeyReleaseURL,
+		"release_assets": KeyReleaseAssets,
+		"skipped":        KeySkipped,
+		"changed_files":  KeyChangedFiles,
 	}
 
 	for expected, got := range keys {